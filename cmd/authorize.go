@@ -6,9 +6,13 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
 	tea "github.com/charmbracelet/bubbletea"
@@ -24,26 +28,48 @@ Issue Summoner to submit issues to a specified source code hosting platform on y
 	Run: func(cmd *cobra.Command, args []string) {
 		logger := getLogger(cmd)
 
-		srcCodeHost, err := cmd.Flags().GetString(flag_sch)
+		settings, err := resolveSettings(cmd)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		wd, err := os.Getwd()
-		if err != nil {
+		if err := settings.loadProfile(); err != nil {
 			logger.Fatal(err.Error())
 		}
+		srcCodeHost, repo, opts := settings.sch.value, settings.repo, settings.opts
+
+		if srcCodeHost == git.Local {
+			logger.Success("The local source code host does not require authorization")
+			return
+		}
 
-		repo, err := git.NewRepository(wd)
+		withToken, err := cmd.Flags().GetBool(flag_with_token)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		gitManager, err := git.NewGitManager(srcCodeHost, repo)
+		gitManager, err := git.NewGitManager(srcCodeHost, repo, opts)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
+		// personal access tokens are piped in, i.e. echo $TOKEN | issue-summoner authorize --with-token,
+		// so that they can be stored on machines that cannot complete the device flow
+		if withToken {
+			token, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				logger.Fatal(err.Error())
+			}
+
+			if err := gitManager.StoreToken(strings.TrimSpace(string(token))); err != nil {
+				logger.Fatal(err.Error())
+			}
+
+			logger.Success(fmt.Sprintf("Access token for %s stored", srcCodeHost))
+			logTokenInfo(logger, gitManager)
+			return
+		}
+
 		if gitManager.Authenticated() {
 			logger.Warning(fmt.Sprintf("You are authorized for %s already", srcCodeHost))
 			logger.PrintStdout("Do you want to create a new access token? (y/n): ")
@@ -61,6 +87,17 @@ Issue Summoner to submit issues to a specified source code hosting platform on y
 			}
 		}
 
+		// azure devops authorization reads a personal access token from stdin,
+		// which would compete with the spinner for control of the terminal
+		if srcCodeHost == git.Azure {
+			if err := gitManager.Authorize(); err != nil {
+				logger.Fatal(err.Error())
+			}
+			logger.Success(fmt.Sprintf("Authorization for %s succeeded!", srcCodeHost))
+			logTokenInfo(logger, gitManager)
+			return
+		}
+
 		spinner := tea.NewProgram(
 			ui.InitSpinner(fmt.Sprintf("Pending %s authorization", srcCodeHost)),
 		)
@@ -80,18 +117,40 @@ Issue Summoner to submit issues to a specified source code hosting platform on y
 			}
 		}()
 
+		// the spinner restores the terminal before exiting, it would otherwise hide the error
 		if err := gitManager.Authorize(); err != nil {
-			if releaseErr := spinner.ReleaseTerminal(); releaseErr != nil {
-				logger.Fatal(err.Error())
-			}
+			spinner.Quit()
+			wg.Wait()
+			logger.Fatal(err.Error())
 		}
 
 		logger.Success(fmt.Sprintf("Authorization for %s succeeded!", srcCodeHost))
+		logTokenInfo(logger, gitManager)
 	},
 }
 
+// logTokenInfo validates a newly stored access token so that tokens with missing
+// scopes are reported right away rather than when issues are reported
+func logTokenInfo(logger *common.Logger, gitManager git.GitManager) {
+	info, err := gitManager.Validate()
+	if err != nil {
+		logger.Warning(err.Error())
+		return
+	}
+
+	if info.Owner != "" {
+		logger.Info("Authorized as " + info.Owner)
+	}
+
+	if !info.ExpiresAt.IsZero() {
+		logger.Info("Access token expires on " + info.ExpiresAt.Local().Format(time.DateTime))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(authorizeCmd)
 	authorizeCmd.Flags().StringP(flag_sch, shortflag_sch, git.Github, flag_desc_sch)
 	authorizeCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	authorizeCmd.Flags().Bool(flag_with_token, false, flag_desc_with_token)
+	authorizeCmd.Flags().String(flag_profile, "", flag_desc_profile)
 }
@@ -0,0 +1,171 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Fails when issue annotations violate the policy of the repository",
+	Long: `Check scans your git project for issue annotations and enforces the rules in the
+check section of the project config file (.issue-summoner.yaml). It is intended for CI, where
+builds fail when annotations are added without an issue reference, when the number of annotations
+exceeds a budget, when annotations reside in forbidden paths or when they are older than the
+maximum age. Each violation is printed on a single line and the command exits with a non-zero
+status code when there are violations. Flags take precedence over the project config file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		policy := settings.policy
+
+		annotations := policy.Annotations
+		if len(annotations) == 0 || cmd.Flags().Changed(flag_annotation) {
+			annotations = []string{settings.annotation.value}
+		}
+
+		rules, err := checkRules(cmd, policy)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		age := blameAge(cmd, settings, logger)
+		violations := make([]issue.Violation, 0)
+
+		for _, annotation := range annotations {
+			located := make(map[issue.IssueMode][]issue.Issue)
+			for _, mode := range []issue.IssueMode{issue.IssueModeScan, issue.IssueModePurge} {
+				manager, err := settings.newAnnotationManager(annotation, mode)
+				if err != nil {
+					logger.Fatal(err.Error())
+				}
+
+				if err := settings.walk(manager); err != nil {
+					logger.Fatal(err.Error())
+				}
+				located[mode] = manager.Issues
+			}
+
+			annotationRules := rules
+			if !cmd.Flags().Changed(flag_max_count) {
+				annotationRules.MaxCount = policy.MaxCount[annotation]
+			}
+
+			found, err := issue.Check(
+				annotation,
+				located[issue.IssueModeScan],
+				located[issue.IssueModePurge],
+				annotationRules,
+				age,
+			)
+			if err != nil {
+				logger.Fatal(err.Error())
+			}
+			violations = append(violations, found...)
+		}
+
+		if len(violations) == 0 {
+			logger.Success(fmt.Sprintf("Check passed: %v annotations comply with the policy", annotations))
+			return
+		}
+
+		for _, v := range violations {
+			location := v.Annotation
+			if v.Path != "" {
+				location = fmt.Sprintf("%s:%d", v.Path, v.Line)
+			}
+			logger.Print(fmt.Sprintf("%s  %s  %s", location, v.Rule, v.Message))
+		}
+
+		logger.Warning(fmt.Sprintf("Check failed: %d violation(s) of the annotation policy", len(violations)))
+		logger.Hint(hint_check_policy)
+		os.Exit(1)
+	},
+}
+
+// checkRules combines the policy of the project config file with the flags of the check command.
+// The max count of each annotation is read from the policy by the caller.
+func checkRules(cmd *cobra.Command, policy common.Policy) (issue.Rules, error) {
+	rules := issue.Rules{RequireIssue: policy.RequireIssue, ForbiddenPaths: policy.ForbiddenPaths}
+
+	if cmd.Flags().Changed(flag_require_issue) {
+		requireIssue, err := cmd.Flags().GetBool(flag_require_issue)
+		if err != nil {
+			return rules, err
+		}
+		rules.RequireIssue = requireIssue
+	}
+
+	maxCount, err := cmd.Flags().GetInt(flag_max_count)
+	if err != nil {
+		return rules, err
+	}
+	rules.MaxCount = maxCount
+
+	if cmd.Flags().Changed(flag_forbidden_path) {
+		if rules.ForbiddenPaths, err = cmd.Flags().GetStringArray(flag_forbidden_path); err != nil {
+			return rules, err
+		}
+	}
+
+	maxAge := policy.MaxAge
+	if cmd.Flags().Changed(flag_max_age) {
+		maxAge = stringFlag(cmd, flag_max_age)
+	}
+
+	if maxAge != "" {
+		if rules.MaxAge, err = common.ParseAge(maxAge); err != nil {
+			return rules, err
+		}
+	}
+
+	return rules, nil
+}
+
+// blameAge returns the age of the line that contains an annotation. Each file is blamed once,
+// files that cannot be blamed are skipped by the max age rule and lines that have not been
+// committed yet, such as the lines of untracked files, are new.
+func blameAge(cmd *cobra.Command, settings *settings, logger *common.Logger) issue.AgeFunc {
+	now := time.Now()
+	blamed := make(map[string][]time.Time)
+
+	return func(iss issue.Issue) (time.Duration, bool) {
+		times, ok := blamed[iss.FilePath]
+		if !ok {
+			var err error
+			if times, err = settings.repo.LineTimes(cmd.Context(), iss.FilePath); err != nil {
+				logger.Warning(err.Error())
+			}
+			blamed[iss.FilePath] = times
+		}
+
+		if iss.LineNumber < 1 || iss.LineNumber > len(times) || times[iss.LineNumber-1].IsZero() {
+			return 0, false
+		}
+		return now.Sub(times[iss.LineNumber-1]), true
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
+	checkCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	checkCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+	checkCmd.Flags().Bool(flag_require_issue, false, flag_desc_require_issue)
+	checkCmd.Flags().Int(flag_max_count, 0, flag_desc_max_count)
+	checkCmd.Flags().StringArray(flag_forbidden_path, nil, flag_desc_forbidden_path)
+	checkCmd.Flags().String(flag_max_age, "", flag_desc_max_age)
+	checkCmd.Flags().Bool(flag_tracked, false, flag_desc_tracked)
+}
@@ -1,67 +1,451 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
 	"github.com/spf13/cobra"
 )
 
 const (
-	err_unauthorized     = "Please run `issue-summoner authorize` and complete the authorization process. This will allow us to submit issues on your behalf."
-	flag_annotation      = "annotation"
-	flag_debug           = "debug"
-	flag_desc_annotation = "The annotation to search for (@TODO:, @FIXME, etc)"
-	flag_desc_debug      = "Log the stack trace when errors occur"
-	flag_desc_mode       = "scan: searches for annotations denoted with the --annotation flag. purge: checks status of reported issues and removes comments"
-	flag_desc_path       = "the path to your local git repository"
-	flag_desc_sch        = "The source code hosting platform you would like to use. Such as, github, gitlab, or bitbucket"
-	flag_desc_verbose    = "log detailed information about each issue annotation that is located during the scan"
-	flag_mode            = "mode"
-	flag_path            = "path"
-	flag_sch             = "sch"
-	flag_verbose         = "verbose"
-	found_issues         = "Number of issues found: "
-	issue_template_path  = "./templates/issue.tmpl"
-	no_issues            = "No issues were found in your project using the annotation: "
-	select_issues        = "Select the issues you wish to report"
-	shortflag_annotation = "a"
-	shortflag_debug      = "d"
-	shortflag_mode       = "m"
-	shortflag_path       = "p"
-	shortflag_sch        = "s"
-	shortflag_verbose    = "v"
-	tip_verbose          = "run issue-summoner scan -v (verbose) for more details about the tag annotations that were found"
+	err_confirm_no_tty         = "stdin is not a terminal. Re-run with --yes to answer:"
+	err_select_no_tty          = "stdin is not a terminal. Select issues with --all or --title-match, which can be narrowed with --path, --annotation, --since, --diff and --tracked, and confirm with --yes"
+	err_unauthorized           = "Please run `issue-summoner authorize` and complete the authorization process. This will allow us to submit issues on your behalf."
+	flag_all                   = "all"
+	flag_annotation            = "annotation"
+	flag_assign_author         = "assign-author"
+	flag_blame                 = "blame"
+	flag_cached                = "cached"
+	flag_check_duplicates      = "check-duplicates"
+	flag_close_orphans         = "close-orphans"
+	flag_codeowners            = "codeowners"
+	flag_commit_msg            = "commit-msg"
+	flag_concurrency           = "concurrency"
+	flag_debug                 = "debug"
+	flag_desc_all              = "report every annotation within --path, without the interactive selection"
+	flag_desc_annotation       = "The annotation to search for (@TODO:, @FIXME, etc)"
+	flag_desc_assign_author    = "assign each issue to the author of its annotation, according to git blame. Emails are mapped to usernames with the authors setting of " + common.ProjectConfigFile
+	flag_desc_blame            = "attribute each annotation to the author of its line, according to git blame, which is printed by --verbose and written by --format. Implied by --older-than"
+	flag_desc_cached           = "scan the contents of the tracked files that are staged in the git index, rather than the working tree. Implies --tracked"
+	flag_desc_check_duplicates = "search the open issues of the repository for issues with the same fingerprint, or the same title, before reporting"
+	flag_desc_close_orphans    = "close issues that were created by a previous report whose annotation no longer exists. Azure DevOps work items are moved to their closedState"
+	flag_desc_codeowners       = "assign each issue to the owners of its file, according to the CODEOWNERS file, and add the labels of the owners from the ownerLabels setting of " + common.ProjectConfigFile
+	flag_desc_commit_msg       = "also install a commit-msg hook that adds a Refs trailer for each reported annotation that the commit removes"
+	flag_desc_concurrency      = "the maximum number of requests that are sent to the source code hosting platform at the same time"
+	flag_desc_debug            = "Log the stack trace when errors occur"
+	flag_desc_depth            = "number of directories that annotations are grouped by, i.e. 2 groups pkg/api/client.go by pkg/api. 0 keeps every directory"
+	flag_desc_diff             = "only include annotations whose comment overlaps lines that were added by a revision range, i.e. main...HEAD"
+	flag_desc_dry_run          = "print the payload of each issue instead of reporting it. Source files and the journal are not modified"
+	flag_desc_every            = "sample every nth commit of the first parent history"
+	flag_desc_force            = "overwrite hooks that were not installed by issue-summoner"
+	flag_desc_format           = "write every issue to stdout as json, ndjson, csv, yaml or sarif, using a versioned schema. Messages are written to stderr"
+	flag_desc_forbidden_path   = "glob of files that may not contain annotations, relative to the work tree. Can be repeated and replaces the check.forbiddenPaths setting"
+	flag_desc_history_format   = "write the history to stdout as json or csv, using a versioned schema. Messages are written to stderr"
+	flag_desc_history_ref      = "the commit, branch or tag whose first parent history is sampled"
+	flag_desc_label            = "label to add to the reported issues, in addition to the project and profile labels. Can be repeated. It does not select issues"
+	flag_desc_limit            = "the maximum number of commits that are sampled. 0 samples the entire history"
+	flag_desc_max_age          = "maximum age of an annotation, according to git blame, i.e. 90d, 12w or 36h"
+	flag_desc_max_count        = "maximum number of each annotation, reported or not. 0 disables the rule"
+	flag_desc_mode             = "scan: searches for annotations denoted with the --annotation flag. purge: checks status of reported issues and removes comments"
+	flag_desc_older_than       = "only include annotations whose line was last changed before an age, according to git blame, i.e. 90d, 12w or 36h"
+	flag_desc_path             = "the path to your local git repository"
+	flag_desc_profile          = "the config.json profile to use, such as work or personal. The source code host of the profile overrides --sch"
+	flag_desc_ref              = "scan the files of a commit, branch or tag, i.e. release/1.4, which are read from the git object database without checking them out"
+	flag_desc_require_issue    = "fail when an annotation does not reference a reported issue, i.e. @TODO(#12)"
+	flag_desc_report_path      = "the path to your local git repository. When the path is a sub directory, or file, of the work tree only the annotations within it are selected"
+	flag_desc_since            = "only include annotations whose comment overlaps lines that were added since a ref, including uncommitted changes and untracked files"
+	flag_desc_sch              = "The source code hosting platform you would like to use. Such as, github, gitlab, bitbucket, azure, or local"
+	flag_desc_staged           = "scan the contents that are staged for the next commit, rather than the work tree. Exits with status code 1 when the staged lines add unreported annotations, or malformed references"
+	flag_desc_tags             = "sample the tagged commits of the first parent history, instead of every nth commit"
+	flag_desc_title_match      = "report the annotations within --path whose title matches the regular expression, without the interactive selection"
+	flag_desc_token_file       = "path to a file containing an access token. Takes precedence over env variables and config.json"
+	flag_desc_tracked          = "only scan the files that are tracked by git, according to the git index, rather than walking the working tree"
+	flag_desc_verbose          = "log detailed information about each issue annotation that is located during the scan"
+	flag_desc_with_token       = "read a personal access token from stdin and store it instead of starting the authorization flow"
+	flag_desc_yes              = "answer yes to confirmation prompts, which is required when stdin is not a terminal"
+	flag_depth                 = "depth"
+	flag_diff                  = "diff"
+	flag_dry_run               = "dry-run"
+	flag_every                 = "every"
+	flag_force                 = "force"
+	flag_format                = "format"
+	flag_forbidden_path        = "forbidden-path"
+	flag_label                 = "label"
+	flag_limit                 = "limit"
+	flag_max_age               = "max-age"
+	flag_max_count             = "max-count"
+	flag_mode                  = "mode"
+	flag_older_than            = "older-than"
+	flag_path                  = "path"
+	flag_profile               = "profile"
+	flag_ref                   = "ref"
+	flag_require_issue         = "require-issue"
+	flag_sch                   = "sch"
+	flag_since                 = "since"
+	flag_staged                = "staged"
+	flag_tags                  = "tags"
+	flag_title_match           = "title-match"
+	flag_token_file            = "token-file"
+	flag_tracked               = "tracked"
+	flag_verbose               = "verbose"
+	flag_with_token            = "with-token"
+	flag_yes                   = "yes"
+	found_issues               = "Number of issues found: "
+	hint_check_policy          = "rules are configured in the check section of " + common.ProjectConfigFile + ", or with flags"
+	hint_pending_write_back    = "issue numbers that could not be written are journaled and will be written on the next <issue-summoner report>"
+	hint_staged                = "report the annotations with <issue-summoner report>, or skip the pre-commit hook with git commit --no-verify"
+	issue_template_path        = "./templates/issue.tmpl"
+	no_issues                  = "No issues were found in your project using the annotation: "
+	select_issues              = "Select the issues you wish to report"
+	shortflag_annotation       = "a"
+	shortflag_debug            = "d"
+	shortflag_mode             = "m"
+	shortflag_path             = "p"
+	shortflag_sch              = "s"
+	shortflag_verbose          = "v"
+	shortflag_yes              = "y"
+	source_default             = "default"
+	source_flag                = "flag"
+	source_profile             = "profile"
+	source_project             = common.ProjectConfigFile
+	tip_verbose                = "run issue-summoner scan -v (verbose) for more details about the tag annotations that were found"
 )
 
-func getCommonFlags(cmd *cobra.Command) (annotation string, path string) {
-	var err error
-	annotation, err = cmd.Flags().GetString(flag_annotation)
+func getLogger(cmd *cobra.Command) *common.Logger {
+	debugIndicator, err := cmd.Flags().GetBool(flag_debug)
 	if err != nil {
 		cobra.CheckErr(err)
 	}
 
-	path, err = cmd.Flags().GetString(flag_path)
-	if err != nil {
-		cobra.CheckErr(err)
-	}
+	return common.NewLogger(debugIndicator)
+}
 
+// setting is a resolved value along with where it was read from
+type setting struct {
+	value  string
+	source string
+}
+
+// settings are the effective values for a command. Each value is resolved using the following
+// precedence: command line flags, the project config file (.issue-summoner.yaml), the config.json
+// profile and lastly the default value of the flag.
+type settings struct {
+	annotation setting
+	sch        setting
+	profile    setting
+	repository setting
+	writeBack  setting
+	template   setting
+	tracked    setting  // "true" when only the files in the git index are scanned, see [settings.walk]
+	labels     []string // project labels, profile labels are added by the [git.GitManager]
+	profLabels []string // labels of the profile, only used for display purposes
+	include    []string
+	exclude    []string
+	authors    map[string]string   // usernames of commit authors by email, see [git.Assignee]
+	ownerLabel map[string][]string // labels of the issues that are routed to each CODEOWNERS owner
+	repo       *git.Repository
+	opts       git.ManagerOptions
+	policy     common.Policy // rules of <issue-summoner check>
+	cached     bool          // scan the staged contents of the tracked files
+	ref        string        // revision whose files are scanned instead of the working tree
+	commit     git.Hash      // commit of [ref]
+}
+
+func resolveSettings(cmd *cobra.Command) (*settings, error) {
+	path := stringFlag(cmd, flag_path)
 	if path == "" {
 		wd, err := os.Getwd()
 		if err != nil {
-			cobra.CheckErr(err)
+			return nil, err
 		}
 		path = wd
 	}
 
-	return annotation, path
+	repo, err := git.NewRepository(path)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := common.ReadProjectConfig(repo.WorkTree)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &settings{
+		annotation: resolveSetting(cmd, flag_annotation, project.Annotation, "@TODO"),
+		sch:        resolveSetting(cmd, flag_sch, project.Sch, git.Github),
+		profile:    resolveSetting(cmd, flag_profile, project.Profile, ""),
+		repository: setting{value: repo.UserName + "/" + repo.RepoName, source: "remote url"},
+		writeBack:  resolveSetting(cmd, "", project.WriteBack, issue.DefaultWriteBack),
+		template:   resolveSetting(cmd, "", project.Template, ""),
+		labels:     project.Labels,
+		include:    project.Include,
+		exclude:    project.Exclude,
+		authors:    project.Authors,
+		ownerLabel: project.OwnerLabels,
+		policy:     project.Check,
+		repo:       repo,
+		opts:       git.ManagerOptions{TokenFile: stringFlag(cmd, flag_token_file), Context: cmd.Context()},
+	}
+
+	s.tracked = setting{value: strconv.FormatBool(project.Tracked), source: source_default}
+	if project.Tracked {
+		s.tracked.source = source_project
+	}
+
+	if flag := cmd.Flags().Lookup(flag_tracked); flag != nil && flag.Changed {
+		s.tracked = setting{value: flag.Value.String(), source: source_flag}
+	}
+
+	if flag := cmd.Flags().Lookup(flag_cached); flag != nil && flag.Changed {
+		s.cached = flag.Value.String() == "true"
+	}
+
+	if flag := cmd.Flags().Lookup(flag_ref); flag != nil && flag.Changed {
+		s.ref = flag.Value.String()
+		if s.commit, err = repo.ResolveRevision(s.ref); err != nil {
+			return nil, err
+		}
+	}
+
+	if project.Repository != "" {
+		if err := repo.SetTarget(project.Repository); err != nil {
+			return nil, err
+		}
+		s.repository = setting{value: project.Repository, source: source_project}
+	}
+
+	return s, nil
 }
 
-func getLogger(cmd *cobra.Command) *common.Logger {
-	debugIndicator, err := cmd.Flags().GetBool(flag_debug)
+// loadProfile selects the config.json profile, see [git.SelectProfile]. It is only called by the
+// commands that talk to a source code host, so that scanning does not read or create config.json.
+func (s *settings) loadProfile() error {
+	conf, err := common.ReadConfig()
+	if err != nil {
+		return err
+	}
+
+	// the source code host of a profile takes precedence over the --sch flag
+	profile := git.SelectProfile(conf, s.sch.value, s.repo, s.profile.value)
+	if profile.Backend != s.sch.value {
+		s.sch = setting{value: profile.Backend, source: source_profile}
+	}
+
+	if s.profile.value == "" {
+		s.profile = setting{value: profile.Name, source: source_default}
+	}
+
+	s.opts.Profile = s.profile.value
+	s.profLabels = profile.Config.Labels
+	return nil
+}
+
+// resolveSetting returns the value of a flag, when it was set, followed by the value from
+// the project config file and the default value of the flag. An empty flag name can be
+// used for settings that can only be set in the project config file.
+func resolveSetting(cmd *cobra.Command, flag, projectVal, defaultVal string) setting {
+	if flag != "" && cmd.Flags().Lookup(flag) != nil && cmd.Flags().Changed(flag) {
+		return setting{value: stringFlag(cmd, flag), source: source_flag}
+	}
+
+	if projectVal != "" {
+		return setting{value: projectVal, source: source_project}
+	}
+
+	if flag != "" && cmd.Flags().Lookup(flag) != nil {
+		return setting{value: stringFlag(cmd, flag), source: source_default}
+	}
+
+	return setting{value: defaultVal, source: source_default}
+}
+
+// stringFlag returns an empty string for flags that are not defined by the command
+func stringFlag(cmd *cobra.Command, flag string) string {
+	if cmd.Flags().Lookup(flag) == nil {
+		return ""
+	}
+
+	val, err := cmd.Flags().GetString(flag)
 	if err != nil {
 		cobra.CheckErr(err)
 	}
 
-	return common.NewLogger(debugIndicator)
+	return val
+}
+
+// issueOptions reads the issue template, which is relative to the working tree
+func (s *settings) issueOptions() (issue.Options, error) {
+	opts := issue.Options{Include: s.include, Exclude: s.exclude, WriteBack: s.writeBack.value}
+	if s.template.value == "" {
+		return opts, nil
+	}
+
+	tmpl, err := os.ReadFile(filepath.Join(s.repo.WorkTree, s.template.value))
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Template = string(tmpl)
+	return opts, nil
+}
+
+// changedLines reads the lines that were added since the --since ref, or by the --diff revision
+// range. A nil set is returned when neither flag was set, along with a description of the changes.
+func changedLines(cmd *cobra.Command, s *settings) (issue.LineSet, string, error) {
+	since, diff := stringFlag(cmd, flag_since), stringFlag(cmd, flag_diff)
+	switch {
+	case since != "" && diff != "":
+		return nil, "", fmt.Errorf("--%s and --%s can not be used together", flag_since, flag_diff)
+	case since != "":
+		changes, err := s.repo.AddedSince(cmd.Context(), since)
+		return changes, "added since " + since, err
+	case diff != "":
+		changes, err := s.repo.AddedIn(cmd.Context(), diff)
+		return changes, "added by " + diff, err
+	default:
+		return nil, "", nil
+	}
+}
+
+// walk locates the issues of the working tree. When the tracked setting is enabled only the files in
+// the git index are scanned, which matches what is committed rather than approximating it with the
+// gitignore files, and the cached setting scans their staged contents instead of the working tree.
+// When a ref is set the files of its commit are read from the object database instead.
+func (s *settings) walk(manager *issue.IssueManager) error {
+	if s.ref != "" {
+		return s.walkCommit(manager)
+	}
+
+	if s.tracked.value != "true" && !s.cached {
+		return manager.Walk(s.repo.WorkTree)
+	}
+
+	entries, err := s.repo.ReadIndex()
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(entries))
+	staged := make(map[string]git.IndexEntry, len(entries))
+	for _, entry := range entries {
+		// conflicted paths have an entry per stage, and no staged contents
+		if !entry.Regular() || (s.cached && entry.Stage != 0) {
+			continue
+		}
+
+		if _, ok := staged[entry.Path]; !ok {
+			paths = append(paths, entry.Path)
+		}
+		staged[entry.Path] = entry
+	}
+
+	read := func(path string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(s.repo.WorkTree, filepath.FromSlash(path)))
+	}
+
+	if s.cached {
+		read = func(path string) ([]byte, error) {
+			return s.repo.ReadBlob(staged[path].Hash)
+		}
+	}
+
+	return manager.WalkFiles(s.repo.WorkTree, paths, read)
+}
+
+// walkCommit locates the issues in the files of the ref commit, without checking it out
+func (s *settings) walkCommit(manager *issue.IssueManager) error {
+	return s.scanCommit(s.commit, manager)
+}
+
+// scanCommit locates the issues of each manager in the files of a commit, reading the files from
+// the object database once for all of the managers, and attributes each issue to the commit
+func (s *settings) scanCommit(commit git.Hash, managers ...*issue.IssueManager) error {
+	entries, err := s.repo.ReadTree(commit)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.Regular() {
+			continue
+		}
+
+		src, err := s.repo.ReadBlob(entry.Hash)
+		if err != nil {
+			return err
+		}
+
+		for _, manager := range managers {
+			if err := manager.ScanSource(s.repo.WorkTree, entry.Path, src); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, manager := range managers {
+		for i := range manager.Issues {
+			manager.Issues[i].Commit = commit.String()
+		}
+	}
+	return nil
+}
+
+// attribute sets the author of the issues at [indices], or every issue when [indices] is nil, to the
+// commit that last changed the line of its annotation, see [git.Repository.BlameLines]. The files of the
+// ref commit are blamed when a ref is set, otherwise the files of the working tree.
+func (s *settings) attribute(ctx context.Context, manager *issue.IssueManager, indices []int) error {
+	rev := ""
+	if s.ref != "" {
+		rev = s.commit.String()
+	}
+
+	return manager.Attribute(indices, func(path string, lines []int) ([]issue.Authorship, error) {
+		blamed, err := s.repo.BlameLines(ctx, rev, path, lines)
+		if err != nil {
+			return nil, err
+		}
+
+		authors := make([]issue.Authorship, len(blamed))
+		for i, line := range blamed {
+			authors[i] = issue.Authorship{
+				Author:      line.Author,
+				AuthorEmail: line.AuthorEmail,
+				CommitSHA:   line.Commit,
+				CreatedAt:   line.Time,
+			}
+		}
+		return authors, nil
+	})
+}
+
+// newIssueManager creates an issue manager that is configured with the resolved settings
+func (s *settings) newIssueManager(mode issue.IssueMode) (*issue.IssueManager, error) {
+	return s.newAnnotationManager(s.annotation.value, mode)
+}
+
+// newAnnotationManager creates an issue manager for an annotation other than the resolved
+// annotation setting, such as the annotations of the check policy
+func (s *settings) newAnnotationManager(annotation string, mode issue.IssueMode) (*issue.IssueManager, error) {
+	manager, err := issue.NewIssueManager([]byte(annotation), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.issueOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.Configure(opts); err != nil {
+		return nil, err
+	}
+
+	return manager, nil
 }
@@ -0,0 +1,99 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the settings that issue summoner resolves for your repository",
+	Long: `Settings are resolved using the following precedence: command line flags, the project config
+file (.issue-summoner.yaml) at the root of your repository, the config.json profile and lastly defaults.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective settings and where each of them was read from",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if err := settings.loadProfile(); err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		rows := []struct {
+			key string
+			setting
+		}{
+			{"annotation", settings.annotation},
+			{"sch", settings.sch},
+			{"profile", settings.profile},
+			{"repository", settings.repository},
+			{"writeBack", settings.writeBack},
+			{"template", settings.template},
+			{"tracked", settings.tracked},
+			{"labels", listSetting(settings.profLabels, settings.labels)},
+			{"include", listSetting(nil, settings.include)},
+			{"exclude", listSetting(nil, settings.exclude)},
+		}
+
+		fmt.Println(
+			ui.AccentTextStyle.Render("Work tree: "),
+			ui.PrimaryTextStyle.Render(settings.repo.WorkTree),
+		)
+
+		for _, row := range rows {
+			fmt.Println(
+				ui.AccentTextStyle.Render(fmt.Sprintf("%-11s", row.key+":")),
+				ui.PrimaryTextStyle.Render(row.value),
+				ui.DimTextStyle.Render("("+row.source+")"),
+			)
+		}
+	},
+}
+
+// listSetting joins the values of a list setting. Profile values are listed first since
+// the project values are added to them.
+func listSetting(profileVals, projectVals []string) setting {
+	sources := make([]string, 0, 2)
+	if len(profileVals) > 0 {
+		sources = append(sources, source_profile)
+	}
+
+	if len(projectVals) > 0 {
+		sources = append(sources, source_project)
+	}
+
+	if len(sources) == 0 {
+		return setting{value: "[]", source: source_default}
+	}
+
+	values := append(append([]string{}, profileVals...), projectVals...)
+	return setting{
+		value:  "[" + strings.Join(values, ", ") + "]",
+		source: strings.Join(sources, ", "),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+	configShowCmd.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
+	configShowCmd.Flags().StringP(flag_sch, shortflag_sch, git.Github, flag_desc_sch)
+	configShowCmd.Flags().String(flag_profile, "", flag_desc_profile)
+	configShowCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+}
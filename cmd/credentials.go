@@ -0,0 +1,60 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/spf13/cobra"
+)
+
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage where access tokens are stored",
+	Long: `Access tokens are stored in a credential store rather than in config.json. The store is
+selected with the ISSUE_SUMMONER_CREDENTIAL_STORE env variable (keyring, encrypted, file). When the
+variable is not set, the operating system keyring is preferred, followed by a passphrase encrypted file
+when ISSUE_SUMMONER_PASSPHRASE is set, and lastly a file that is only readable by the current user.`,
+}
+
+var credentialsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move access tokens that are stored in plain text, in config.json, to the credential store",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		store, err := common.NewCredentialStore()
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		conf, err := common.ReadConfig()
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		migrated, err := common.MigrateTokens(conf, store)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if migrated == 0 {
+			logger.Info("No plain text access tokens were found in config.json")
+			return
+		}
+
+		if err := common.WriteToConfig(conf); err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		logger.Success(fmt.Sprintf("Moved %d access token(s) to the %s credential store", migrated, store.Name()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(credentialsCmd)
+	credentialsCmd.AddCommand(credentialsMigrateCmd)
+	credentialsMigrateCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+}
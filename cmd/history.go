@@ -0,0 +1,274 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// number of directories and authors whose trend is printed to the terminal
+const historyTopKeys = 5
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Reports how the number of issue annotations evolves across commits",
+	Long: `History samples commits along the first parent history of a branch, every nth commit or
+each tagged commit, and counts the issue annotations, reported or not, at each of them. The files
+of each commit are read from the git object database, nothing is checked out. Annotations are
+counted per annotation, per directory and per author, where the author is whoever last changed the
+line of the annotation according to git blame. The counts are printed as a table along with a
+sparkline of each trend, or written to stdout as json or csv with --format.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		format := stringFlag(cmd, flag_format)
+		if format != "" {
+			if !slices.Contains(issue.HistoryFormats, format) {
+				logger.Fatal(fmt.Sprintf("unsupported --%s %q. Expected one of %v", flag_format, format, issue.HistoryFormats))
+			}
+			logger.SetOutput(os.Stderr)
+		}
+
+		annotations := settings.policy.Annotations
+		if len(annotations) == 0 || cmd.Flags().Changed(flag_annotation) {
+			annotations = []string{settings.annotation.value}
+		}
+
+		every, err := cmd.Flags().GetInt(flag_every)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		limit, err := cmd.Flags().GetInt(flag_limit)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		depth, err := cmd.Flags().GetInt(flag_depth)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		tagged, err := cmd.Flags().GetBool(flag_tags)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if every < 1 || limit < 0 || depth < 0 {
+			logger.Fatal(fmt.Sprintf("--%s must be at least 1, --%s and --%s can not be negative", flag_every, flag_limit, flag_depth))
+		}
+
+		start := settings.commit
+		if settings.ref == "" {
+			if start, err = settings.repo.ResolveRevision("HEAD"); err != nil {
+				logger.Fatal(err.Error())
+			}
+		}
+
+		tags, err := settings.repo.Tags()
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		samples, err := sampleCommits(settings.repo, start, every, limit, tags, tagged)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if len(samples) == 0 {
+			logger.Fatal("no tagged commits were found in the first parent history")
+		}
+
+		history := issue.History{Version: issue.HistoryVersion, Snapshots: make([]issue.Snapshot, 0, len(samples))}
+		for i, commit := range samples {
+			logger.Info(fmt.Sprintf("Scanning commit %s (%d/%d)", commit.Hash.String()[:7], i+1, len(samples)))
+
+			snapshot, err := settings.snapshot(cmd.Context(), commit, tags[commit.Hash], annotations, depth)
+			if err != nil {
+				logger.Fatal(err.Error())
+			}
+			history.Snapshots = append(history.Snapshots, snapshot)
+		}
+
+		msg := fmt.Sprintf("Sampled %d commits for %v annotations", len(samples), annotations)
+		if format != "" {
+			if err := history.Write(os.Stdout, format); err != nil {
+				logger.Fatal(err.Error())
+			}
+			logger.Success(msg)
+			return
+		}
+
+		printHistory(history, annotations)
+		logger.Success(msg)
+	},
+}
+
+// sampleCommits selects commits of the first parent history of [start], oldest first. Every [every]
+// commit is selected, beginning with [start], or each tagged commit when [tagged] is set. At most
+// [limit] of the most recent commits are selected, all of them when limit is 0.
+func sampleCommits(repo *git.Repository, start git.Hash, every, limit int, tags map[git.Hash][]string, tagged bool) ([]git.Commit, error) {
+	// only the commits that can be selected are read
+	length := 0
+	if !tagged && limit > 0 {
+		length = (limit-1)*every + 1
+	}
+
+	commits, err := repo.FirstParents(start, length)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]git.Commit, 0)
+	for i, commit := range commits {
+		if limit > 0 && len(samples) == limit {
+			break
+		}
+
+		if (tagged && len(tags[commit.Hash]) > 0) || (!tagged && i%every == 0) {
+			samples = append(samples, commit)
+		}
+	}
+
+	slices.Reverse(samples)
+	return samples, nil
+}
+
+// snapshot counts the annotations of a commit. Each file that contains annotations is blamed once,
+// for the lines of its annotations.
+func (s *settings) snapshot(ctx context.Context, commit git.Commit, tags, annotations []string, depth int) (issue.Snapshot, error) {
+	managers := make([]*issue.IssueManager, 0, len(annotations)*2)
+	for _, annotation := range annotations {
+		for _, mode := range []issue.IssueMode{issue.IssueModeScan, issue.IssueModePurge} {
+			manager, err := s.newAnnotationManager(annotation, mode)
+			if err != nil {
+				return issue.Snapshot{}, err
+			}
+			managers = append(managers, manager)
+		}
+	}
+
+	snapshot := issue.NewSnapshot(commit.Hash.String(), commit.Committer.When, tags)
+	if err := s.scanCommit(commit.Hash, managers...); err != nil {
+		return snapshot, err
+	}
+
+	// each file is blamed once, for the lines of the annotations of every manager
+	lines := make(map[string][]int)
+	for _, manager := range managers {
+		for _, iss := range manager.Issues {
+			path := filepath.ToSlash(iss.FilePath)
+			lines[path] = append(lines[path], iss.LineNumber)
+		}
+	}
+
+	authors := make(map[string]map[int]string)
+	for path, numbers := range lines {
+		blamed, err := s.repo.BlameLines(ctx, commit.Hash.String(), path, numbers)
+		if err != nil {
+			return snapshot, err
+		}
+
+		authors[path] = make(map[int]string)
+		for i, line := range blamed {
+			authors[path][numbers[i]] = line.Author
+		}
+	}
+
+	for i, manager := range managers {
+		for _, iss := range manager.Issues {
+			path := filepath.ToSlash(iss.FilePath)
+			author := authors[path][iss.LineNumber]
+			if author == "" {
+				author = "unknown"
+			}
+
+			// each annotation has a manager per mode
+			snapshot.Add(annotations[i/2], path, author, depth)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// printHistory prints a row per snapshot, followed by the trend of the total, each annotation and
+// the directories and authors with the most annotations
+func printHistory(history issue.History, annotations []string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tCOMMIT\tTAGS\tTOTAL\t"+strings.Join(annotations, "\t"))
+	for _, s := range history.Snapshots {
+		row := []string{s.Date.Format("2006-01-02"), s.Commit[:7], strings.Join(s.Tags, " "), fmt.Sprint(s.Total)}
+		for _, annotation := range annotations {
+			row = append(row, fmt.Sprint(s.Annotations[annotation]))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	trends := []struct {
+		heading   string
+		dimension string
+		keys      []string
+	}{
+		{heading: "Total", dimension: issue.DimensionTotal, keys: []string{""}},
+		{heading: "Annotations", dimension: issue.DimensionAnnotation, keys: annotations},
+		{heading: "Directories", dimension: issue.DimensionDirectory, keys: history.Keys(issue.DimensionDirectory)},
+		{heading: "Authors", dimension: issue.DimensionAuthor, keys: history.Keys(issue.DimensionAuthor)},
+	}
+
+	for _, trend := range trends {
+		keys := trend.keys[:min(len(trend.keys), historyTopKeys)]
+		width := 0
+		for _, key := range keys {
+			width = max(width, len(historyLabel(key)))
+		}
+
+		fmt.Printf("\n%s\n", ui.AccentTextStyle.Render(trend.heading))
+		for _, key := range keys {
+			series := history.Series(trend.dimension, key)
+			fmt.Println(
+				fmt.Sprintf("  %-*s", width, historyLabel(key)),
+				ui.PrimaryTextStyle.Render(ui.Sparkline(series)),
+				ui.DimTextStyle.Render(fmt.Sprintf("%d → %d", series[0], series[len(series)-1])),
+			)
+		}
+	}
+}
+
+// historyLabel returns the label of a key, the total is counted with an empty key
+func historyLabel(key string) string {
+	if key == "" {
+		return "all"
+	}
+	return key
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
+	historyCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	historyCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+	historyCmd.Flags().String(flag_ref, "", flag_desc_history_ref)
+	historyCmd.Flags().Int(flag_every, 10, flag_desc_every)
+	historyCmd.Flags().Bool(flag_tags, false, flag_desc_tags)
+	historyCmd.Flags().Int(flag_limit, 20, flag_desc_limit)
+	historyCmd.Flags().Int(flag_depth, 1, flag_desc_depth)
+	historyCmd.Flags().String(flag_format, "", flag_desc_history_format)
+}
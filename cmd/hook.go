@@ -0,0 +1,207 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/spf13/cobra"
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manage the git hooks that run issue summoner",
+	Long: `Git hooks run issue summoner before changes are committed. The pre-commit hook scans the
+contents that are staged for the commit and blocks it when unreported annotations, or references that
+do not match the write back format such as @TODO(#), are staged. The optional commit-msg hook adds a
+Refs trailer to the commit message for each reported annotation that the commit removes.`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the pre-commit hook, and optionally the commit-msg hook, in the git directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		commitMsg, err := cmd.Flags().GetBool(flag_commit_msg)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		force, err := cmd.Flags().GetBool(flag_force)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		// the annotation is only pinned when it was passed, otherwise the hook follows the project config file
+		annotation := ""
+		if cmd.Flags().Changed(flag_annotation) {
+			annotation = settings.annotation.value
+		}
+
+		hooks := map[string]string{git.HookPreCommit: "scan --staged"}
+		if commitMsg {
+			hooks[git.HookCommitMsg] = "hook commit-msg"
+		}
+
+		for _, name := range []string{git.HookPreCommit, git.HookCommitMsg} {
+			command, ok := hooks[name]
+			if !ok {
+				continue
+			}
+
+			path, err := settings.repo.InstallHook(name, hookScript(command, annotation, name == git.HookCommitMsg), force)
+			if err != nil {
+				logger.Fatal(fmt.Sprintf("%s. Re-run with --%s to overwrite it", err.Error(), flag_force))
+			}
+			logger.Success(fmt.Sprintf("Installed the %s hook at %s", name, path))
+		}
+	},
+}
+
+var hookCommitMsgCmd = &cobra.Command{
+	Use:    "commit-msg <message file>",
+	Short:  "Add a Refs trailer for each reported annotation that the staged changes remove",
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		removed, err := removedReferences(cmd, settings)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		trailers := make([]string, 0, len(removed))
+		for _, id := range removed {
+			ref := fmt.Sprintf(strings.TrimSuffix(strings.TrimPrefix(settings.writeBack.value, "("), ")"), id)
+			trailers = append(trailers, "Refs: "+ref)
+		}
+
+		file, err := filepath.Abs(args[0])
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if err := settings.repo.AddTrailers(cmd.Context(), file, trailers); err != nil {
+			logger.Fatal(err.Error())
+		}
+	},
+}
+
+// removedReferences returns the issue numbers of reported annotations that exist in HEAD but are
+// removed by the staged changes. Annotations that are moved to another file are not removed.
+func removedReferences(cmd *cobra.Command, settings *settings) ([]int, error) {
+	before, err := settings.newIssueManager(issue.IssueModePurge)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := settings.newIssueManager(issue.IssueModePurge)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := settings.repo.StagedFiles(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	repo := settings.repo
+	staged, err := repo.StagedEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		// submodules and symlinks have no staged source, see [git.Repository.StagedEntries]
+		if _, ok := staged[file.Path]; !ok && !file.Deleted {
+			continue
+		}
+
+		// files that do not exist in HEAD, such as the files of the first commit, have no annotations to remove
+		if src, err := repo.ReadCommitted("HEAD", file.Path); err == nil {
+			if err := before.ScanSource(repo.WorkTree, file.Path, src); err != nil {
+				return nil, err
+			}
+		}
+
+		if file.Deleted {
+			continue
+		}
+
+		src, err := repo.ReadStaged(staged, file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := after.ScanSource(repo.WorkTree, file.Path, src); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining := make(map[int]bool)
+	for _, iss := range after.Issues {
+		remaining[iss.Comment.IssueNumber] = true
+	}
+
+	removed := make([]int, 0)
+	for _, iss := range before.Issues {
+		if id := iss.Comment.IssueNumber; !remaining[id] && !slices.Contains(removed, id) {
+			removed = append(removed, id)
+		}
+	}
+
+	slices.Sort(removed)
+	return removed, nil
+}
+
+// hookScript runs an issue summoner command from a hook. Commit-msg hooks receive the path of the
+// message file as their first argument.
+func hookScript(command, annotation string, messageFile bool) string {
+	script := strings.Builder{}
+	script.WriteString("#!/bin/sh\n")
+	script.WriteString(git.HookMarker + ", see <issue-summoner hook install>\n")
+	script.WriteString("# skip the hook with git commit --no-verify\n")
+	script.WriteString("exec issue-summoner " + command)
+
+	if annotation != "" {
+		script.WriteString(" --annotation '" + strings.ReplaceAll(annotation, "'", `'\''`) + "'")
+	}
+
+	if messageFile {
+		script.WriteString(` "$1"`)
+	}
+
+	script.WriteString("\n")
+	return script.String()
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookCommitMsgCmd)
+	hookInstallCmd.Flags().Bool(flag_commit_msg, false, flag_desc_commit_msg)
+	hookInstallCmd.Flags().Bool(flag_force, false, flag_desc_force)
+	for _, sub := range []*cobra.Command{hookInstallCmd, hookCommitMsgCmd} {
+		sub.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
+		sub.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+		sub.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestHooksSkipSubmodules runs the commands of the pre-commit and commit-msg hooks with a staged
+// submodule, whose commit is not in the object database of the repository
+func TestHooksSkipSubmodules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+
+	workTree := t.TempDir()
+	path := filepath.Join(workTree, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @E2E_FIXME(#1) reported issue\nfunc main() {}\n"), 0644))
+
+	runGit(t, workTree, "init", "-q")
+	runGit(t, workTree, "add", "main.go")
+	runGit(t, workTree, "commit", "-q", "-m", "initial commit")
+
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644))
+	runGit(t, workTree, "add", "main.go")
+	runGit(t, workTree, "update-index", "--add", "--cacheinfo", "160000,8582c19b3a3f1f1a4a5c5d2e0d6b1f2a3c4d5e6f,vendor/lib")
+
+	execute(t, "scan", "-p", workTree, "-a", "@E2E_FIXME", "-m", "scan", "--staged")
+
+	message := filepath.Join(dir, "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(message, []byte("Remove the reported issue\n"), 0644))
+	execute(t, "hook", "commit-msg", "-p", workTree, "-a", "@E2E_FIXME", message)
+
+	data, err := os.ReadFile(message)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "Refs: #1")
+}
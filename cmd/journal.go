@@ -0,0 +1,176 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+)
+
+// journalRecovery completes the write-backs of a previous report that created issues
+// but failed, or was interrupted, before writing the issue numbers to the source files
+type journalRecovery struct {
+	settings     *settings
+	manager      *issue.IssueManager // report mode manager that has walked the work tree
+	gitManager   git.GitManager
+	journal      *git.Journal
+	closeOrphans bool
+	logger       *common.Logger
+}
+
+// recover matches each pending journal entry to an annotation that has not been reported,
+// see [journalRecovery.match], and writes the issue number back to it. Entries without
+// a matching annotation are orphans, unless the issue number is already in the source.
+// When any write-back succeeds the work tree is walked again, since the positions of the
+// annotations changed, and the new manager is returned. An error is returned when a write-back
+// fails, since its annotations would otherwise be reported again.
+func (r *journalRecovery) recover(pending []git.JournalEntry) (*issue.IssueManager, error) {
+	sch, profile, target := r.settings.sch.value, r.settings.profile.value, r.settings.repo.Target()
+
+	matched := make(map[int]bool)
+	orphans := make([]git.JournalEntry, 0)
+	for _, entry := range pending {
+		index := r.match(entry, matched)
+		if index == -1 {
+			orphans = append(orphans, entry)
+			continue
+		}
+
+		matched[index] = true
+		if err := r.manager.Group(index, entry.IssueNumber); err != nil {
+			return nil, err
+		}
+	}
+
+	written, failed := 0, make([]error, 0)
+	for pathKey := range r.manager.IssueMap {
+		if err := r.manager.WriteIssues(pathKey); err != nil {
+			failed = append(failed, err)
+			continue
+		}
+
+		if err := r.journal.Remove(sch, profile, target, reportedIDs(r.manager, pathKey)...); err != nil {
+			return nil, err
+		}
+
+		written += len(r.manager.IssueMap[pathKey])
+	}
+
+	if written > 0 {
+		r.logger.Success(fmt.Sprintf("Wrote %d issue number(s) that were reported by a previous run", written))
+	}
+
+	if len(failed) > 0 {
+		return nil, fmt.Errorf(
+			"failed to write the issue numbers that were reported by a previous run, fix the files and re-run report to avoid duplicate issues: %w",
+			errors.Join(failed...),
+		)
+	}
+
+	if err := r.resolveOrphans(orphans); err != nil {
+		return nil, err
+	}
+
+	if written == 0 {
+		return r.manager, nil
+	}
+
+	manager, err := r.settings.newIssueManager(issue.IssueModeReport)
+	if err != nil {
+		return nil, err
+	}
+
+	return manager, r.settings.walk(manager)
+}
+
+// match returns the index of the first unmatched annotation for the entry, or -1. Annotations
+// are matched by the location of their fingerprint, followed by the content of the fingerprint,
+// which matches annotations in files that were renamed. Entries that were journaled without a
+// fingerprint are matched by file path and title.
+func (r *journalRecovery) match(entry git.JournalEntry, matched map[int]bool) int {
+	byContent := -1
+	for i, candidate := range r.manager.Issues {
+		if matched[i] {
+			continue
+		}
+
+		if entry.Fingerprint.IsZero() {
+			if candidate.FilePath == entry.FilePath && candidate.Title == entry.Title {
+				return i
+			}
+			continue
+		}
+
+		if candidate.Fingerprint.Location == entry.Fingerprint.Location {
+			return i
+		}
+
+		if candidate.Fingerprint.Content == entry.Fingerprint.Content && byContent == -1 {
+			byContent = i
+		}
+	}
+	return byContent
+}
+
+// resolveOrphans removes entries whose issue number was written back, which happens when
+// the process exits between writing the file and updating the journal. The remaining
+// orphans are closed when --close-orphans is set.
+func (r *journalRecovery) resolveOrphans(orphans []git.JournalEntry) error {
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	sch, profile, target := r.settings.sch.value, r.settings.profile.value, r.settings.repo.Target()
+
+	purger, err := r.settings.newIssueManager(issue.IssueModePurge)
+	if err != nil {
+		return err
+	}
+
+	if err := r.settings.walk(purger); err != nil {
+		return err
+	}
+
+	inSource := make(map[int]bool)
+	for _, reported := range purger.Issues {
+		inSource[reported.Comment.IssueNumber] = true
+	}
+
+	for _, orphan := range orphans {
+		if inSource[orphan.IssueNumber] {
+			if err := r.journal.Remove(sch, profile, target, orphan.IssueNumber); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !r.closeOrphans {
+			r.logger.Warning(fmt.Sprintf(
+				"Issue #%d <%s> was reported from %s but its annotation no longer exists. Re-run with --%s to close it",
+				orphan.IssueNumber,
+				orphan.Title,
+				orphan.FilePath,
+				flag_close_orphans,
+			))
+			continue
+		}
+
+		if err := r.gitManager.Close(orphan.IssueNumber); err != nil {
+			r.logger.Warning(err.Error())
+			continue
+		}
+
+		if err := r.journal.Remove(sch, profile, target, orphan.IssueNumber); err != nil {
+			return err
+		}
+
+		r.logger.Success(fmt.Sprintf("Closed orphaned issue #%d <%s>", orphan.IssueNumber, orphan.Title))
+	}
+
+	return nil
+}
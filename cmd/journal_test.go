@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRecoveryWriteFailure(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("read-only files can be written by root")
+	}
+
+	workTree := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workTree, ".git"), 0755))
+	config := "[remote \"origin\"]\n\turl = git@github.com:acme/api.git\n"
+	require.NoError(t, os.WriteFile(filepath.Join(workTree, ".git", "config"), []byte(config), 0644))
+
+	path := filepath.Join(workTree, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @E2E_FIXME journaled issue\nfunc main() {}\n"), 0444))
+
+	repo, err := git.NewRepository(workTree)
+	require.NoError(t, err)
+
+	s := &settings{
+		annotation: setting{value: "@E2E_FIXME"},
+		sch:        setting{value: git.Github},
+		writeBack:  setting{value: issue.DefaultWriteBack},
+		tracked:    setting{value: "false"},
+		repo:       repo,
+	}
+
+	manager, err := s.newIssueManager(issue.IssueModeReport)
+	require.NoError(t, err)
+	require.NoError(t, s.walk(manager))
+	require.Len(t, manager.Issues, 1)
+
+	journal, err := git.OpenJournal(repo)
+	require.NoError(t, err)
+	require.NoError(t, journal.Record(git.JournalEntry{
+		Sch:         git.Github,
+		Target:      repo.Target(),
+		IssueNumber: 7,
+		Title:       manager.Issues[0].Title,
+		FilePath:    "main.go",
+		Fingerprint: manager.Issues[0].Fingerprint,
+	}))
+
+	recovery := journalRecovery{settings: s, manager: manager, journal: journal, logger: common.NewLogger(false)}
+	_, err = recovery.recover(journal.Pending(git.Github, "", repo.Target()))
+	require.ErrorContains(t, err, "main.go", "annotations that were not written back would be reported again")
+	require.Len(t, journal.Pending(git.Github, "", repo.Target()), 1, "the entry is kept for the next run")
+}
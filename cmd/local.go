@@ -0,0 +1,105 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local",
+	Short: "Manage issues that were reported to the local source code host",
+	Long: `The local source code host stores reported issues in a file that lives inside of your
+repository (.issue-summoner/issues.json) instead of a remote platform. This allows the report and
+purge flows to work without network access. Use the subcommands to list, close and re-open local issues.
+Closed issues are removed from your source code when running <issue-summoner scan -m purge -s local>`,
+}
+
+var localListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List issues that were reported to the local source code host",
+	Run: func(cmd *cobra.Command, args []string) {
+		logger := getLogger(cmd)
+
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		issues, err := git.ReadLocalIssues(settings.repo)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		if len(issues) == 0 {
+			logger.Info("No local issues have been reported")
+			return
+		}
+
+		for _, iss := range issues {
+			state := ui.SuccessTextStyle.Render(iss.State)
+			if iss.State == git.LocalStateClosed {
+				state = ui.DimTextStyle.Render(iss.State)
+			}
+
+			fmt.Println(
+				ui.AccentTextStyle.Render(fmt.Sprintf("#%d", iss.ID)),
+				state,
+				ui.PrimaryTextStyle.Render(iss.Title),
+			)
+		}
+	},
+}
+
+var localCloseCmd = &cobra.Command{
+	Use:   "close <issue number>",
+	Short: "Close an issue that was reported to the local source code host",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setLocalIssueState(cmd, args[0], git.LocalStateClosed)
+	},
+}
+
+var localOpenCmd = &cobra.Command{
+	Use:   "open <issue number>",
+	Short: "Re-open an issue that was reported to the local source code host",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setLocalIssueState(cmd, args[0], git.LocalStateOpen)
+	},
+}
+
+func setLocalIssueState(cmd *cobra.Command, arg, state string) {
+	logger := getLogger(cmd)
+
+	settings, err := resolveSettings(cmd)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("expected an issue number but got %s", arg))
+	}
+
+	if err := git.SetLocalIssueState(settings.repo, id, state); err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	logger.Success(fmt.Sprintf("Local issue #%d is now %s", id, state))
+}
+
+func init() {
+	rootCmd.AddCommand(localCmd)
+	for _, sub := range []*cobra.Command{localListCmd, localCloseCmd, localOpenCmd} {
+		localCmd.AddCommand(sub)
+		sub.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+		sub.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2024 AntoninoAdornetto
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"golang.org/x/term"
+)
+
+// prompter asks the user to confirm the actions of a command. Questions are answered with yes
+// when --yes is set and fail, rather than block, when the command is not run from a terminal,
+// i.e. in a CI pipeline. Questions are not asked during a dry run, the value of --yes is used instead.
+type prompter struct {
+	yes         bool
+	dryRun      bool
+	interactive bool // stdin and stdout are attached to a terminal
+	scanner     *bufio.Scanner
+	logger      *common.Logger
+}
+
+func newPrompter(yes, dryRun bool, logger *common.Logger) *prompter {
+	return &prompter{
+		yes:         yes,
+		dryRun:      dryRun,
+		interactive: isInteractive(),
+		scanner:     bufio.NewScanner(os.Stdin),
+		logger:      logger,
+	}
+}
+
+// isInteractive reports whether both stdin and stdout are attached to a terminal
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// confirm asks a yes or no question. Closing stdin answers no.
+func (p *prompter) confirm(question string) (bool, error) {
+	if p.yes || p.dryRun {
+		return p.yes, nil
+	}
+
+	if !p.interactive {
+		return false, fmt.Errorf("%s %s", err_confirm_no_tty, question)
+	}
+
+	p.logger.PrintStdout(fmt.Sprintf("\n%s (y/n): ", question))
+	if !p.scanner.Scan() {
+		return false, p.scanner.Err()
+	}
+
+	switch strings.TrimSpace(p.scanner.Text()) {
+	case "y", "yes", "return":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
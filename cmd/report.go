@@ -4,11 +4,15 @@ Copyright © 2024 AntoninoAdornetto
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"sync"
 
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
@@ -31,122 +35,300 @@ Once issue annotations are discovered, you will be presented with a list of all
 that were located and you can select which ones you would like to report to a source code hosting 
 platform.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		annotation, path := getCommonFlags(cmd)
 		logger := getLogger(cmd)
 
-		srcCodeHost, err := cmd.Flags().GetString(flag_sch)
+		settings, err := resolveSettings(cmd)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		repo, err := git.NewRepository(path)
+		if err := settings.loadProfile(); err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		filter, err := reportFilter(cmd, settings)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		manager, err := issue.NewIssueManager([]byte(annotation), issue.IssueModeReport)
+		selectAll, err := cmd.Flags().GetBool(flag_all)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		if err := manager.Walk(repo.WorkTree); err != nil {
+		yes, err := cmd.Flags().GetBool(flag_yes)
+		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		if len(manager.Issues) == 0 {
-			logger.Info(no_issues + annotation)
-			return
+		dryRun, err := cmd.Flags().GetBool(flag_dry_run)
+		if err != nil {
+			logger.Fatal(err.Error())
 		}
 
-		gitManager, err := git.NewGitManager(srcCodeHost, repo)
+		labels, err := cmd.Flags().GetStringArray(flag_label)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		options := make([]ui.Item, len(manager.Issues))
-		for i, toReport := range manager.Issues {
-			options[i] = ui.Item{
-				Title: toReport.Title,
-				Desc:  toReport.Description,
-				ID:    i,
-			}
+		assignAuthor, err := cmd.Flags().GetBool(flag_assign_author)
+		if err != nil {
+			logger.Fatal(err.Error())
 		}
 
-		var quit bool
-		selections := ui.Selection{Options: make(map[int]bool)}
-		multiSelect := tea.NewProgram(
-			ui.InitMultiSelect(options, &selections, select_issues, &quit),
-		)
+		codeOwners, err := cmd.Flags().GetBool(flag_codeowners)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
 
-		if _, err := multiSelect.Run(); err != nil {
+		// the multi select requires a terminal, issues must be selected with flags otherwise
+		prompter := newPrompter(yes, dryRun, logger)
+		interactiveSelect := !selectAll && filter.TitleMatch == nil
+		if interactiveSelect && !prompter.interactive {
+			logger.Fatal(err_select_no_tty)
+		}
+
+		if dryRun {
+			settings.opts.DryRun = os.Stdout
+		}
+
+		annotation, srcCodeHost, repo := settings.annotation.value, settings.sch.value, settings.repo
+		manager, err := settings.newIssueManager(issue.IssueModeReport)
+		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		selectedCount := 0
-		for _, selected := range selections.Options {
-			if selected {
-				selectedCount++
-			}
+		if err := settings.walk(manager); err != nil {
+			logger.Fatal(err.Error())
 		}
 
-		switch selectedCount {
-		case 0:
-			logger.Info("No issues selected")
-			return
-		case 1:
-			logger.PrintStdout(
-				fmt.Sprintf("\nReport %d issue to %s? (y/n): ", selectedCount, srcCodeHost),
-			)
-		default:
-			logger.PrintStdout(
-				fmt.Sprintf("\nReport %d issues to %s? (y/n): ", selectedCount, srcCodeHost),
-			)
+		concurrency, err := cmd.Flags().GetInt(flag_concurrency)
+		if err != nil {
+			logger.Fatal(err.Error())
 		}
 
-		scanner := bufio.NewScanner(os.Stdin)
-		if !scanner.Scan() {
+		closeOrphans, err := cmd.Flags().GetBool(flag_close_orphans)
+		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		switch scanner.Text() {
-		case "y", "yes", "return":
-			break
-		default:
-			logger.Info("Aborting report request")
+		checkDuplicates, err := cmd.Flags().GetBool(flag_check_duplicates)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		gitManager, err := git.NewGitManager(srcCodeHost, repo, settings.opts)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		journal, err := git.OpenJournal(repo)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		profile, target := settings.profile.value, repo.Target()
+		if pending := journal.Pending(srcCodeHost, profile, target); len(pending) > 0 && dryRun {
+			logger.Info(fmt.Sprintf("Skipping the recovery of %d journaled issue(s) during a dry run", len(pending)))
+		} else if len(pending) > 0 {
+			recovery := journalRecovery{
+				settings:     settings,
+				manager:      manager,
+				gitManager:   gitManager,
+				journal:      journal,
+				closeOrphans: closeOrphans,
+				logger:       logger,
+			}
+
+			if manager, err = recovery.recover(pending); err != nil {
+				logger.Fatal(err.Error())
+			}
+		}
+
+		if len(manager.Issues) == 0 {
+			logger.Info(no_issues + annotation)
 			return
 		}
 
-		spinner := tea.NewProgram(ui.InitSpinner(fmt.Sprintf("Reporting to %s", srcCodeHost)))
-		go func() {
-			if _, err := spinner.Run(); err != nil {
+		candidates := make([]int, 0, len(manager.Issues))
+		for i, toReport := range manager.Issues {
+			if filter.Match(toReport) {
+				candidates = append(candidates, i)
+			}
+		}
+
+		// blame traverses the history of the repository, so only the candidates are attributed and only
+		// when the author is assigned with --assign-author or rendered by the template
+		if assignAuthor || manager.UsesAuthorship() {
+			if err := settings.attribute(cmd.Context(), manager, candidates); err != nil && assignAuthor {
 				logger.Fatal(err.Error())
+			} else if err != nil {
+				logger.Warning("Skipping the authors of the annotations: " + err.Error())
 			}
-		}()
+		}
 
-		defer func() {
-			if r := recover(); err != nil {
-				logger.Fatal(fmt.Sprintf("Failed to recover program with unexpected error: %s", r))
+		if codeOwners {
+			// invalid lines only skip their own rules, the rest of the file is still used
+			owners, err := repo.ReadCodeOwners()
+			if err != nil && owners.Path == "" {
+				logger.Fatal(err.Error())
+			} else if err != nil {
+				logger.Warning(strings.ReplaceAll(err.Error(), "\n", ", ") + ". Skipping the invalid lines")
 			}
 
-			if err := spinner.ReleaseTerminal(); err != nil {
+			if owners.Path == "" {
+				logger.Warning(fmt.Sprintf("No CODEOWNERS file was found in %s", strings.Join(git.CodeOwnersPaths, ", ")))
+			}
+
+			err = manager.SetOwners(func(path string) []string {
+				names, _ := owners.Owners(path)
+				return names
+			})
+			if err != nil {
 				logger.Fatal(err.Error())
 			}
-		}()
+		}
 
-		wg := sync.WaitGroup{}
-		wg.Add(selectedCount)
+		// an invalid access token would otherwise cause every issue in the batch to fail
+		if !dryRun {
+			if _, err := gitManager.Validate(); err != nil {
+				logger.Fatal(err.Error())
+			}
+		}
+
+		routing := router{
+			sch:          srcCodeHost,
+			authors:      settings.authors,
+			ownerLabels:  settings.ownerLabel,
+			assignAuthor: assignAuthor,
+		}
 
-		reportedChan := make(chan git.ReportResponse, selectedCount)
-		for index := range selections.Options {
+		routes := make(map[int]route, len(candidates))
+		for _, index := range candidates {
+			routes[index] = routing.route(manager.Issues[index])
+		}
+
+		selected := candidates
+		if interactiveSelect {
+			if selected, err = selectIssues(manager, candidates, routes); err != nil {
+				logger.Fatal(err.Error())
+			}
+		} else if codeOwners || assignAuthor {
+			for _, index := range selected {
+				if preview := routes[index].String(); preview != "" {
+					logger.Info(fmt.Sprintf("%s: %s", manager.Issues[index].Title, preview))
+				}
+			}
+		}
+
+		if len(selected) == 0 {
+			logger.Info("No issues selected")
+			return
+		}
+
+		// owners of issues that were not selected are not assigned, so they are not worth a warning
+		warned := make(map[string]bool)
+		for _, index := range selected {
+			for _, owner := range routes[index].unassigned {
+				if !warned[owner] {
+					warned[owner] = true
+					warnUnassigned(logger, srcCodeHost, owner)
+				}
+			}
+		}
+
+		if !dryRun {
+			noun := "issues"
+			if len(selected) == 1 {
+				noun = "issue"
+			}
+
+			confirmed, err := prompter.confirm(fmt.Sprintf("Report %d %s to %s?", len(selected), noun, srcCodeHost))
+			if err != nil {
+				logger.Fatal(err.Error())
+			}
+
+			if !confirmed {
+				logger.Info("Aborting report request")
+				return
+			}
+		}
+
+		requests := make([]git.ReportRequest, 0, len(selected))
+		for _, index := range selected {
 			toReport := manager.Issues[index]
-			req := git.ReportRequest{Title: toReport.Title, Body: toReport.Body, Index: index}
-			go func(request git.ReportRequest) {
-				defer wg.Done()
-				gitManager.Report(request, reportedChan)
-			}(req)
+			requests = append(requests, git.ReportRequest{
+				Title:     toReport.Title,
+				Body:      toReport.Body,
+				Labels:    slices.Concat(settings.labels, labels, routes[index].labels),
+				Assignees: routes[index].assignees,
+				Index:     index,
+			})
 		}
 
-		wg.Wait()
+		if checkDuplicates {
+			requests = linkDuplicates(gitManager, manager, requests, prompter, logger)
+		}
+
+		// the manager writes the payloads rather than reporting them, see [git.ManagerOptions.DryRun]
+		if dryRun {
+			for _, req := range requests {
+				res := make(chan git.ReportResponse, 1)
+				gitManager.Report(req, res)
+				if r := <-res; r.Err != nil {
+					logger.Warning(r.Err.Error())
+				}
+			}
+			return
+		}
+
+		if prompter.interactive {
+			spinner := tea.NewProgram(ui.InitSpinner(fmt.Sprintf("Reporting to %s", srcCodeHost)))
+			go func() {
+				if _, err := spinner.Run(); err != nil {
+					logger.Fatal(err.Error())
+				}
+			}()
+
+			defer func() {
+				if r := recover(); err != nil {
+					logger.Fatal(fmt.Sprintf("Failed to recover program with unexpected error: %s", r))
+				}
+
+				if err := spinner.ReleaseTerminal(); err != nil {
+					logger.Fatal(err.Error())
+				}
+			}()
+		}
+
+		// a bounded number of workers avoids tripping the secondary rate limits of the platform.
+		// Created issues are journaled right away so that a failed write-back can be recovered.
+		reportedChan := make(chan git.ReportResponse, len(requests))
+		common.ForEach(len(requests), concurrency, func(i int) {
+			res := make(chan git.ReportResponse, 1)
+			gitManager.Report(requests[i], res)
+
+			r := <-res
+			if r.Err == nil {
+				reported := manager.Issues[r.Index]
+				entry := git.JournalEntry{
+					Sch:         srcCodeHost,
+					Profile:     profile,
+					Target:      target,
+					IssueNumber: r.ID,
+					Title:       reported.Title,
+					FilePath:    reported.FilePath,
+					LineNumber:  reported.LineNumber,
+					Fingerprint: reported.Fingerprint,
+				}
+
+				if err := journal.Record(entry); err != nil {
+					logger.Warning(fmt.Sprintf("failed to journal issue #%d: %s", r.ID, err.Error()))
+				}
+			}
+
+			reportedChan <- r
+		})
 		close(reportedChan)
 
 		for r := range reportedChan {
@@ -164,6 +346,7 @@ platform.`,
 			reportCount++
 		}
 
+		wg := sync.WaitGroup{}
 		wg.Add(reportCount)
 		writeChan := make(chan writeIssueResult, reportCount)
 		for path := range manager.IssueMap {
@@ -180,6 +363,14 @@ platform.`,
 		wg.Wait()
 		close(writeChan)
 		for r := range writeChan {
+			if r.Err == nil {
+				if err := journal.Remove(srcCodeHost, profile, target, reportedIDs(manager, r.PathKey)...); err != nil {
+					logger.Warning(err.Error())
+				}
+			} else {
+				logger.Hint(hint_pending_write_back)
+			}
+
 			messages, err := manager.Results(r.PathKey, srcCodeHost, r.Err != nil)
 			if err != nil {
 				logger.Warning(err.Error())
@@ -197,10 +388,242 @@ platform.`,
 	},
 }
 
+// linkDuplicates searches the open issues of the repository for issues with the same fingerprint,
+// or title, as the requests. When duplicates are found, the user can link the annotations to the existing
+// issues, which writes the existing issue numbers back rather than reporting new issues.
+// The requests that should still be reported are returned.
+func linkDuplicates(
+	gitManager git.GitManager,
+	manager *issue.IssueManager,
+	requests []git.ReportRequest,
+	prompter *prompter,
+	logger *common.Logger,
+) []git.ReportRequest {
+	open, err := gitManager.ListOpen()
+	if err != nil {
+		logger.Warning("Skipping duplicate detection: " + err.Error())
+		return requests
+	}
+
+	duplicates := make(map[int]git.RemoteIssue)
+	for _, req := range requests {
+		fingerprint := manager.Issues[req.Index].Fingerprint
+		if remote, found := git.FindDuplicate(open, req.Title, fingerprint); found {
+			duplicates[req.Index] = remote
+			logger.Warning(fmt.Sprintf("<%s> matches open issue #%d", req.Title, remote.Number))
+		}
+	}
+
+	if len(duplicates) == 0 {
+		return requests
+	}
+
+	link, err := prompter.confirm(fmt.Sprintf(
+		"Link %d annotation(s) to the existing issues instead of reporting them?",
+		len(duplicates),
+	))
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	if !link {
+		return requests
+	}
+
+	remaining := make([]git.ReportRequest, 0, len(requests))
+	for _, req := range requests {
+		remote, ok := duplicates[req.Index]
+		if !ok {
+			remaining = append(remaining, req)
+			continue
+		}
+
+		if err := manager.Group(req.Index, remote.Number); err != nil {
+			logger.Warning(err.Error())
+		}
+	}
+
+	return remaining
+}
+
+// reportFilter selects the annotations within the --path flag, when it is a sub directory or file
+// of the work tree, whose title matches the --title-match flag
+func reportFilter(cmd *cobra.Command, settings *settings) (issue.Filter, error) {
+	filter := issue.Filter{}
+
+	lines, _, err := changedLines(cmd, settings)
+	if err != nil {
+		return filter, err
+	}
+	filter.Lines = lines
+
+	if titleMatch := stringFlag(cmd, flag_title_match); titleMatch != "" {
+		re, err := regexp.Compile(titleMatch)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --%s expression: %w", flag_title_match, err)
+		}
+		filter.TitleMatch = re
+	}
+
+	path := stringFlag(cmd, flag_path)
+	if path == "" {
+		return filter, nil
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filter, err
+	}
+
+	workTree, err := filepath.Abs(settings.repo.WorkTree)
+	if err != nil {
+		return filter, err
+	}
+
+	if filter.Path, err = filepath.Rel(workTree, abs); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// selectIssues presents the candidates, which are indices of [issue.IssueManager.Issues], in a multi
+// select and returns the indices that were selected
+func selectIssues(manager *issue.IssueManager, candidates []int, routes map[int]route) ([]int, error) {
+	options := make([]ui.Item, len(candidates))
+	for i, index := range candidates {
+		toReport := manager.Issues[index]
+		options[i] = ui.Item{
+			Title:   toReport.Title,
+			Desc:    toReport.Description,
+			Preview: routes[index].String(),
+			ID:      index,
+		}
+	}
+
+	var quit bool
+	selections := ui.Selection{Options: make(map[int]bool)}
+	multiSelect := tea.NewProgram(
+		ui.InitMultiSelect(options, &selections, select_issues, &quit),
+	)
+
+	if _, err := multiSelect.Run(); err != nil {
+		return nil, err
+	}
+
+	selected := make([]int, 0, len(candidates))
+	for _, index := range candidates {
+		if selections.Options[index] {
+			selected = append(selected, index)
+		}
+	}
+	return selected, nil
+}
+
+// router decides who each issue is assigned to and which labels are added to it, from the CODEOWNERS
+// owners of its file and, with --assign-author, the author of its annotation
+type router struct {
+	sch          string
+	authors      map[string]string   // usernames of commit authors by email, see [git.Assignee]
+	ownerLabels  map[string][]string // labels of each owner, see [common.ProjectConfig.OwnerLabels]
+	assignAuthor bool
+}
+
+// route is who an issue is assigned to and the labels that are added to it, see [router]
+type route struct {
+	assignees  []string
+	labels     []string
+	unassigned []string // owners and emails that could not be assigned, see [warnUnassigned]
+}
+
+func (r *router) route(toReport issue.Issue) route {
+	rt := route{}
+	for _, owner := range toReport.Owners {
+		if user, ok := git.OwnerAssignee(r.sch, owner, r.authors); ok {
+			rt.assign(user)
+		} else {
+			rt.unassigned = append(rt.unassigned, owner)
+		}
+
+		for _, label := range r.ownerLabels[owner] {
+			if !slices.Contains(rt.labels, label) {
+				rt.labels = append(rt.labels, label)
+			}
+		}
+	}
+
+	// lines that have not been committed yet have no author to assign
+	if r.assignAuthor && toReport.Committed() {
+		if user, ok := git.Assignee(r.sch, toReport.AuthorEmail, r.authors); ok {
+			rt.assign(user)
+		} else {
+			rt.unassigned = append(rt.unassigned, toReport.AuthorEmail)
+		}
+	}
+
+	return rt
+}
+
+func warnUnassigned(logger *common.Logger, sch, owner string) {
+	if strings.HasPrefix(owner, "@") {
+		logger.Warning(fmt.Sprintf("%s can not be assigned on %s", owner, sch))
+		return
+	}
+
+	logger.Warning(fmt.Sprintf(
+		"No %s user is known for %s, add it to the authors setting of %s",
+		sch,
+		owner,
+		common.ProjectConfigFile,
+	))
+}
+
+func (rt *route) assign(user string) {
+	if !slices.Contains(rt.assignees, user) {
+		rt.assignees = append(rt.assignees, user)
+	}
+}
+
+// String previews the route in the multi select, i.e. "assign: octocat · labels: payments"
+func (rt route) String() string {
+	parts := make([]string, 0, 2)
+	if len(rt.assignees) > 0 {
+		parts = append(parts, "assign: "+strings.Join(rt.assignees, ", "))
+	}
+	if len(rt.labels) > 0 {
+		parts = append(parts, "labels: "+strings.Join(rt.labels, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// reportedIDs returns the issue numbers that were grouped for the file
+func reportedIDs(manager *issue.IssueManager, pathKey string) []int {
+	ids := make([]int, 0, len(manager.IssueMap[pathKey]))
+	for _, entry := range manager.IssueMap[pathKey] {
+		ids = append(ids, entry.ReportedID)
+	}
+	return ids
+}
+
 func init() {
 	rootCmd.AddCommand(reportCmd)
-	reportCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
+	reportCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_report_path)
 	reportCmd.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
 	reportCmd.Flags().StringP(flag_sch, shortflag_sch, git.Github, flag_desc_sch)
 	reportCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	reportCmd.Flags().String(flag_token_file, "", flag_desc_token_file)
+	reportCmd.Flags().String(flag_profile, "", flag_desc_profile)
+	reportCmd.Flags().Int(flag_concurrency, common.DefaultConcurrency, flag_desc_concurrency)
+	reportCmd.Flags().Bool(flag_close_orphans, false, flag_desc_close_orphans)
+	reportCmd.Flags().Bool(flag_check_duplicates, true, flag_desc_check_duplicates)
+	reportCmd.Flags().Bool(flag_all, false, flag_desc_all)
+	reportCmd.Flags().String(flag_title_match, "", flag_desc_title_match)
+	reportCmd.Flags().StringArray(flag_label, nil, flag_desc_label)
+	reportCmd.Flags().Bool(flag_assign_author, false, flag_desc_assign_author)
+	reportCmd.Flags().Bool(flag_codeowners, false, flag_desc_codeowners)
+	reportCmd.Flags().BoolP(flag_yes, shortflag_yes, false, flag_desc_yes)
+	reportCmd.Flags().Bool(flag_dry_run, false, flag_desc_dry_run)
+	reportCmd.Flags().String(flag_since, "", flag_desc_since)
+	reportCmd.Flags().String(flag_diff, "", flag_desc_diff)
+	reportCmd.Flags().Bool(flag_tracked, false, flag_desc_tracked)
 }
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git/gittest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeGithubHost = "git.test"
+
+// TestReportAndPurge runs <issue-summoner report> and <issue-summoner scan -m purge> against a fake
+// github server, which covers the flags of both commands and the comments that they write back
+func TestReportAndPurge(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", "file")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv(git.HostTokenEnv(fakeGithubHost), "classic-token")
+
+	srv := gittest.NewGithub(t)
+	srv.AddToken("classic-token", gittest.Token{Scopes: []string{"repo"}, ExpiresAt: time.Now().Add(time.Hour)})
+
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+	conf[git.Github] = common.IssueSummonerConfig{
+		Hosts: map[string]common.HostConfig{
+			fakeGithubHost: {BaseUrl: srv.URL, ApiBaseUrl: srv.URL},
+		},
+	}
+	require.NoError(t, common.WriteToConfig(conf))
+
+	workTree := t.TempDir()
+	src := "package main\n\n// @E2E_FIXME first issue\nfunc main() {}\n\n// @E2E_FIXME second issue\nfunc run() {}\n"
+	path := filepath.Join(workTree, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+
+	runGit(t, workTree, "init", "-q")
+	runGit(t, workTree, "remote", "add", "origin", "git@"+fakeGithubHost+":acme/api.git")
+	runGit(t, workTree, "add", "main.go")
+	runGit(t, workTree, "commit", "-q", "-m", "initial commit")
+
+	execute(t, "report", "-p", workTree, "-a", "@E2E_FIXME", "--all", "--yes", "--label", "e2e")
+
+	issues := srv.Issues("acme", "api")
+	require.Len(t, issues, 2)
+	require.Equal(t, "first issue", issues[0].Title)
+	require.Equal(t, []string{"e2e"}, issues[0].Labels)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "@E2E_FIXME(#1) first issue")
+	require.Contains(t, string(data), "@E2E_FIXME(#2) second issue")
+
+	srv.CloseIssue("acme", "api", 1)
+	execute(t, "scan", "-p", workTree, "-a", "@E2E_FIXME", "-m", "purge")
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "first issue")
+	require.Contains(t, string(data), "@E2E_FIXME(#2) second issue")
+}
+
+// execute runs a command of the cli. Commands exit the process when they fail, see [common.Logger.Fatal].
+// Flags are reset first since cobra keeps their values between executions.
+func execute(t *testing.T, args ...string) {
+	resetFlags(rootCmd)
+	rootCmd.SetArgs(args)
+	require.NoError(t, rootCmd.Execute())
+}
+
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			_ = slice.Replace(nil)
+		} else {
+			_ = flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
+
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// runGit runs a git command in [dir] with a fixed identity
+func runGit(t *testing.T, dir string, args ...string) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	require.NoError(t, cmd.Run(), stderr.String())
+}
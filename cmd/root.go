@@ -4,8 +4,10 @@ Copyright © 2024 Antonino Adornetto
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
 	"github.com/spf13/cobra"
@@ -33,12 +35,27 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// the logo would corrupt machine readable output, see <issue-summoner scan --format>
+		if format := cmd.Flags().Lookup(flag_format); format != nil && format.Value.String() != "" {
+			return
+		}
+
+		// git hooks run on every commit, see <issue-summoner hook install>
+		if staged := cmd.Flags().Lookup(flag_staged); cmd.Hidden || staged != nil && staged.Value.String() == "true" {
+			return
+		}
+		fmt.Println(ui.AccentTextStyle.Render(Logo))
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	// requests that are in flight are cancelled when the user presses ctrl+c
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -54,5 +71,4 @@ func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	fmt.Println(ui.AccentTextStyle.Render(Logo))
 }
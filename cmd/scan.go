@@ -5,8 +5,12 @@ package cmd
 
 import (
 	"fmt"
-	"sync"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/ui"
@@ -26,66 +30,148 @@ purge mode will check the status of each reported issue and remove the correspon
 the source code hosting platform indicates it is in a resolved state. Both modes can be used to 
 print details about the issues, such as the description and location of the issues.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		annotation, path := getCommonFlags(cmd)
 		logger := getLogger(cmd)
 
+		settings, err := resolveSettings(cmd)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		annotation, repo := settings.annotation.value, settings.repo
+
 		verbose, err := cmd.Flags().GetBool(flag_verbose)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
+		blame, err := cmd.Flags().GetBool(flag_blame)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
 		mode, err := cmd.Flags().GetString(flag_mode)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		repo, err := git.NewRepository(path)
+		// machine readable output is written to stdout, messages would corrupt it
+		format := stringFlag(cmd, flag_format)
+		if format != "" {
+			if !slices.Contains(issue.Formats, format) {
+				logger.Fatal(fmt.Sprintf("unsupported --%s %q. Expected one of %v", flag_format, format, issue.Formats))
+			}
+			logger.SetOutput(os.Stderr)
+		}
+
+		manager, err := settings.newIssueManager(mode)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		// purge mode removes comments from the working tree, at the locations of the staged contents
+		if settings.cached && mode == issue.IssueModePurge {
+			logger.Fatal(fmt.Sprintf("--%s can not be used with purge mode", flag_cached))
+		}
+
+		staged, err := cmd.Flags().GetBool(flag_staged)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		manager, err := issue.NewIssueManager([]byte(annotation), mode)
+		// annotations are older than the age when their line was last changed before the cutoff
+		var cutoff time.Time
+		olderThan := stringFlag(cmd, flag_older_than)
+		if olderThan != "" {
+			age, err := common.ParseAge(olderThan)
+			if err != nil {
+				logger.Fatal(err.Error())
+			}
+			cutoff = time.Now().Add(-age)
+		}
+
+		// the files of a commit are read from the object database, there is no working tree to purge or diff
+		if settings.ref != "" {
+			if mode != issue.IssueModeScan || staged || settings.cached || cmd.Flags().Changed(flag_since) || cmd.Flags().Changed(flag_diff) {
+				logger.Fatal(fmt.Sprintf(
+					"--%s can not be used with purge mode, --%s, --%s, --%s or --%s",
+					flag_ref, flag_staged, flag_cached, flag_since, flag_diff,
+				))
+			}
+			at := fmt.Sprintf(" at %s (%s)", settings.ref, settings.commit.String()[:7])
+			if strings.HasPrefix(settings.commit.String(), strings.ToLower(settings.ref)) {
+				at = " at " + settings.commit.String()[:7]
+			}
+			annotation += at
+		}
+
+		if staged {
+			if mode != issue.IssueModeScan || cmd.Flags().Changed(flag_since) || cmd.Flags().Changed(flag_diff) || olderThan != "" || blame {
+				logger.Fatal(fmt.Sprintf(
+					"--%s can not be used with purge mode, --%s, --%s, --%s or --%s",
+					flag_staged, flag_since, flag_diff, flag_older_than, flag_blame,
+				))
+			}
+			scanStaged(cmd, settings, manager, format, logger)
+			return
+		}
+
+		lines, changes, err := changedLines(cmd, settings)
 		if err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		if err := manager.Walk(repo.WorkTree); err != nil {
+		if err := settings.walk(manager); err != nil {
 			logger.Fatal(err.Error())
 		}
 
-		if len(manager.Issues) == 0 {
+		if lines != nil {
+			manager.Retain(issue.Filter{Lines: lines})
+			annotation += " in lines " + changes
+		}
+
+		// blame traverses the history of the repository, so the authors are only attributed on request
+		if blame || !cutoff.IsZero() {
+			if err := settings.attribute(cmd.Context(), manager, nil); err != nil {
+				logger.Fatal(err.Error())
+			}
+		}
+
+		if !cutoff.IsZero() {
+			manager.Retain(issue.Filter{Before: cutoff})
+			annotation += " older than " + olderThan
+		}
+
+		if len(manager.Issues) == 0 && format == "" {
 			logger.Success(fmt.Sprintf("Scan finished: %s %s", no_issues, annotation))
 			return
 		}
 
 		var msg string
+		statuses := make(map[int]issue.Status)
 
 		issueCount, purgeCount := len(manager.Issues), 0
 		if mode == issue.IssueModePurge {
-			wg := sync.WaitGroup{}
-			wg.Add(issueCount)
-
-			sourceCodeHost, err := cmd.Flags().GetString(flag_sch)
-			if err != nil {
+			if err := settings.loadProfile(); err != nil {
 				logger.Fatal(err.Error())
 			}
 
+			sourceCodeHost := settings.sch.value
 			logger.Info("Checking statuses of reported issues on " + sourceCodeHost)
 
-			gitManager, err := git.NewGitManager(sourceCodeHost, repo)
+			gitManager, err := git.NewGitManager(sourceCodeHost, repo, settings.opts)
 			if err != nil {
 				logger.Fatal(err.Error())
 			}
 
-			statusChan := make(chan git.StatusResponse, issueCount)
-			for _, iss := range manager.Issues {
-				go func(toCheck issue.Issue) {
-					defer wg.Done()
-					gitManager.GetStatus(iss.Comment.IssueNumber, toCheck.Index, statusChan)
-				}(iss)
+			concurrency, err := cmd.Flags().GetInt(flag_concurrency)
+			if err != nil {
+				logger.Fatal(err.Error())
 			}
 
-			wg.Wait()
+			statusChan := make(chan git.StatusResponse, issueCount)
+			common.ForEach(issueCount, concurrency, func(i int) {
+				toCheck := manager.Issues[i]
+				gitManager.GetStatus(toCheck.Comment.IssueNumber, toCheck.Index, statusChan)
+			})
 			close(statusChan)
 
 			for c := range statusChan {
@@ -100,6 +186,7 @@ print details about the issues, such as the description and location of the issu
 						),
 					)
 				case c.Resolved:
+					statuses[c.Index] = issue.StatusResolved
 					if err := manager.Group(c.Index, currentIssue.Comment.IssueNumber); err != nil {
 						logger.Warning("Failed to group")
 					}
@@ -107,6 +194,8 @@ print details about the issues, such as the description and location of the issu
 					logger.Warning(
 						fmt.Sprintf("Issue <%s> has not been resolved yet", currentIssue.Title),
 					)
+				default:
+					statuses[c.Index] = issue.StatusOpen
 				}
 			}
 
@@ -133,6 +222,14 @@ print details about the issues, such as the description and location of the issu
 			msg = fmt.Sprintf("Found %d issue annotations using %s", len(manager.Issues), annotation)
 		}
 
+		if format != "" {
+			if err := manager.Export(statuses).Write(os.Stdout, format); err != nil {
+				logger.Fatal(err.Error())
+			}
+			logger.Success(msg)
+			return
+		}
+
 		if verbose {
 			for _, iss := range manager.Issues {
 				fmt.Printf("\n\n")
@@ -162,6 +259,32 @@ print details about the issues, such as the description and location of the issu
 					ui.PrimaryTextStyle.Render(fmt.Sprintf("%d", iss.LineNumber)),
 				)
 
+				if iss.Commit != "" {
+					fmt.Println(
+						ui.AccentTextStyle.Render("Commit: "),
+						ui.PrimaryTextStyle.Render(iss.Commit),
+					)
+				}
+
+				if iss.Author != "" {
+					author := iss.Author
+					if iss.AuthorEmail != "" {
+						author += " <" + iss.AuthorEmail + ">"
+					}
+
+					fmt.Println(
+						ui.AccentTextStyle.Render("Author: "),
+						ui.PrimaryTextStyle.Render(author),
+					)
+				}
+
+				if iss.Committed() {
+					fmt.Println(
+						ui.AccentTextStyle.Render("Added: "),
+						ui.PrimaryTextStyle.Render(fmt.Sprintf("%s in %s", iss.CreatedAt.Format("2006-01-02"), iss.CommitSHA[:7])),
+					)
+				}
+
 				if mode == issue.IssueModePurge && iss.Comment.IssueNumber != 0 {
 					fmt.Println(
 						ui.AccentTextStyle.Render("Issue number: "),
@@ -179,12 +302,105 @@ print details about the issues, such as the description and location of the issu
 	},
 }
 
+// scanStaged scans the contents of the files that are staged for the next commit, in scan and lint mode,
+// and exits with status code 1 when unreported annotations or malformed references are staged. It is
+// run by the pre-commit hook, see <issue-summoner hook install>.
+func scanStaged(cmd *cobra.Command, settings *settings, manager *issue.IssueManager, format string, logger *common.Logger) {
+	lint, err := settings.newIssueManager(issue.IssueModeLint)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	files, err := settings.repo.StagedFiles(cmd.Context())
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	// annotations that were committed before do not block the commit, only the lines that it adds
+	added, err := settings.repo.AddedStaged(cmd.Context())
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	staged, err := settings.repo.StagedEntries()
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	for _, file := range files {
+		// submodules and symlinks have no staged source, see [git.Repository.StagedEntries]
+		if _, ok := staged[file.Path]; !ok || file.Deleted || len(added[file.Path]) == 0 {
+			continue
+		}
+
+		src, err := settings.repo.ReadStaged(staged, file.Path)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+
+		for _, m := range []*issue.IssueManager{manager, lint} {
+			if err := m.ScanSource(settings.repo.WorkTree, file.Path, src); err != nil {
+				logger.Fatal(err.Error())
+			}
+		}
+	}
+
+	manager.Retain(issue.Filter{Lines: added})
+	lint.Retain(issue.Filter{Lines: added})
+
+	if format != "" {
+		if err := manager.Export(nil).Write(os.Stdout, format); err != nil {
+			logger.Fatal(err.Error())
+		}
+	}
+
+	annotation := settings.annotation.value
+	if len(manager.Issues) == 0 && len(lint.Issues) == 0 {
+		logger.Success(fmt.Sprintf("Scan finished: no unreported %s annotations are staged", annotation))
+		return
+	}
+
+	for _, iss := range manager.Issues {
+		logger.Print(fmt.Sprintf("%s:%d  unreported  %s %s", iss.FilePath, iss.LineNumber, annotation, iss.Title))
+	}
+
+	for _, iss := range lint.Issues {
+		logger.Print(fmt.Sprintf(
+			"%s:%d  malformed  the reference of %s does not match the write back format %s",
+			iss.FilePath,
+			iss.LineNumber,
+			annotation,
+			settings.writeBack.value,
+		))
+	}
+
+	logger.Warning(fmt.Sprintf(
+		"%d unreported annotation(s) and %d malformed reference(s) are staged",
+		len(manager.Issues),
+		len(lint.Issues),
+	))
+	logger.Hint(hint_staged)
+	os.Exit(1)
+}
+
 func init() {
 	rootCmd.AddCommand(scanCmd)
 	scanCmd.Flags().StringP(flag_annotation, shortflag_annotation, "@TODO", flag_desc_annotation)
 	scanCmd.Flags().BoolP(flag_debug, shortflag_debug, false, flag_desc_debug)
+	scanCmd.Flags().String(flag_token_file, "", flag_desc_token_file)
+	scanCmd.Flags().String(flag_profile, "", flag_desc_profile)
+	scanCmd.Flags().Int(flag_concurrency, common.DefaultConcurrency, flag_desc_concurrency)
 	scanCmd.Flags().StringP(flag_mode, shortflag_mode, issue.IssueModeScan, flag_desc_mode)
 	scanCmd.Flags().StringP(flag_path, shortflag_path, "", flag_desc_path)
 	scanCmd.Flags().StringP(flag_sch, shortflag_sch, git.Github, flag_desc_sch)
 	scanCmd.Flags().BoolP(flag_verbose, shortflag_verbose, false, flag_desc_verbose)
+	scanCmd.Flags().String(flag_format, "", flag_desc_format)
+	scanCmd.Flags().Bool(flag_blame, false, flag_desc_blame)
+	scanCmd.Flags().String(flag_since, "", flag_desc_since)
+	scanCmd.Flags().String(flag_diff, "", flag_desc_diff)
+	scanCmd.Flags().Bool(flag_staged, false, flag_desc_staged)
+	scanCmd.Flags().Bool(flag_tracked, false, flag_desc_tracked)
+	scanCmd.Flags().Bool(flag_cached, false, flag_desc_cached)
+	scanCmd.Flags().String(flag_ref, "", flag_desc_ref)
+	scanCmd.Flags().String(flag_older_than, "", flag_desc_older_than)
 }
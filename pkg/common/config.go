@@ -8,14 +8,43 @@ import (
 	"time"
 )
 
+// IssueSummonerConfig is a profile. Profiles that are named after a source code host, i.e. github,
+// are the default profile for that host. Additional profiles, such as work or personal, allow
+// multiple accounts to be used with the same source code host and must set [IssueSummonerConfig.Sch].
 type IssueSummonerConfig struct {
-	Auth AuthConfig `json:"auth"`
+	Sch          string                `json:"sch,omitempty"`          // source code host of the profile, defaults to the profile name
+	Host         string                `json:"host,omitempty"`         // overrides the host derived from the remote url
+	BaseUrl      string                `json:"baseUrl,omitempty"`      // overrides the web url of the host
+	ApiBaseUrl   string                `json:"apiBaseUrl,omitempty"`   // overrides the api url of the host
+	TokenSource  string                `json:"tokenSource,omitempty"`  // env:<NAME>, file:<PATH> or helper:<COMMAND>
+	Labels       []string              `json:"labels,omitempty"`       // labels applied to every issue reported with the profile
+	Repositories []string              `json:"repositories,omitempty"` // owner/name or host/owner/name globs that select the profile
+	Auth         AuthConfig            `json:"auth"`
+	WorkItemType string                `json:"workItemType,omitempty"` // azure devops work item type, i.e. Task, Bug
+	ClosedState  string                `json:"closedState,omitempty"`  // azure devops state of closed work items, i.e. Closed, Done
+	Hosts        map[string]HostConfig `json:"hosts,omitempty"`        // keyed by host name, i.e. github.com
+}
+
+// HostConfig contains the endpoints and credentials for a single host of a source code
+// hosting platform. It allows self hosted instances, such as GitHub Enterprise Server, to
+// be used. Empty endpoints are derived from the host name.
+type HostConfig struct {
+	BaseUrl          string     `json:"baseUrl,omitempty"`          // i.e. https://git.corp.example
+	ApiBaseUrl       string     `json:"apiBaseUrl,omitempty"`       // i.e. https://git.corp.example/api/v3
+	ClientID         string     `json:"clientId,omitempty"`         // OAuth app client id used for the device flow
+	DeviceCodeUrl    string     `json:"deviceCodeUrl,omitempty"`    // device flow verification code endpoint
+	AccessTokenUrl   string     `json:"accessTokenUrl,omitempty"`   // device flow access token endpoint
+	CredentialHelper string     `json:"credentialHelper,omitempty"` // command that prints a token, i.e. gh auth token --hostname {host}
+	Auth             AuthConfig `json:"auth"`
 }
 
 type AuthConfig struct {
-	AccessToken string    `json:"accessToken"`
-	CreatedAt   time.Time `json:"createdAt"`
-	ExpiresAt   time.Time `json:"expiresAt"`
+	AccessToken     string    `json:"accessToken,omitempty"` // plain text tokens, see [MigrateTokens]
+	CreatedAt       time.Time `json:"createdAt"`
+	ExpiresAt       time.Time `json:"expiresAt"`
+	CredentialStore string    `json:"credentialStore,omitempty"` // store that holds the token, see [NewCredentialStore]
+	CredentialKey   string    `json:"credentialKey,omitempty"`   // key the token is stored under, see [CredentialKey]
+	RefreshKey      string    `json:"refreshKey,omitempty"`      // key the refresh token is stored under, if the token can be refreshed
 }
 
 type Config = map[string]IssueSummonerConfig
@@ -28,6 +57,7 @@ var (
 		"github":    {},
 		"gitlab":    {},
 		"bitbucket": {},
+		"azure":     {},
 	}
 )
 
@@ -80,16 +110,38 @@ func WriteToConfig(conf Config) error {
 	return err
 }
 
-func getConfigFile() (*os.File, error) {
+// ConfigDir returns the directory that user specific configuration data is stored in.
+// The location depends on the operating system.
+func ConfigDir() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	path := filepath.Join(dir, "issue-summoner")
-	if err := os.MkdirAll(path, 0755); err != nil {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func getConfigFile() (*os.File, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "config.json"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// config files that were created by earlier versions were readable by everyone
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
 		return nil, err
 	}
 
-	return os.OpenFile(filepath.Join(path, "config.json"), os.O_RDWR|os.O_CREATE, 0666)
+	return f, nil
 }
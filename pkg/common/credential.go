@@ -0,0 +1,580 @@
+package common
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+/*
+ACCESS TOKENS ARE KEPT OUT OF CONFIG.JSON BY STORING THEM IN A [CredentialStore]. THE
+[AuthConfig] OF A SOURCE CODE HOST ONLY RECORDS WHICH STORE, AND UNDER WHICH KEY, THE
+TOKEN WAS WRITTEN TO. THE STORE IS SELECTED WITH THE ISSUE_SUMMONER_CREDENTIAL_STORE
+ENV VARIABLE:
+
+- `keyring`: THE OPERATING SYSTEM KEYRING. SECRET SERVICE (secret-tool) ON LINUX AND THE
+KEYCHAIN (security) ON MACOS.
+
+- `encrypted`: A FILE THAT IS ENCRYPTED WITH A PASSPHRASE, READ FROM THE ISSUE_SUMMONER_PASSPHRASE
+ENV VARIABLE. INTENDED FOR HEADLESS LINUX MACHINES THAT DO NOT RUN A SECRET SERVICE.
+
+- `file`: A PLAIN JSON FILE THAT IS ONLY READABLE BY THE CURRENT USER (0600).
+
+WHEN THE ENV VARIABLE IS NOT SET, THE KEYRING IS PREFERRED, FOLLOWED BY THE ENCRYPTED FILE
+WHEN A PASSPHRASE IS SET, AND LASTLY THE PLAIN FILE.
+*/
+
+const (
+	CredentialStoreKeyring   = "keyring"
+	CredentialStoreEncrypted = "encrypted"
+	CredentialStoreFile      = "file"
+	credentialStoreEnv       = "ISSUE_SUMMONER_CREDENTIAL_STORE"
+	credentialPassphraseEnv  = "ISSUE_SUMMONER_PASSPHRASE"
+	credentialService        = "issue-summoner"
+	credentialFile           = "credentials.json"
+	credentialEncryptedFile  = "credentials.enc"
+	pbkdf2Iterations         = 310000
+)
+
+var ErrCredentialNotFound = errors.New("credential not found")
+
+type CredentialStore interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, secret string) error
+	Delete(key string) error
+}
+
+// NewCredentialStore returns the store that is selected by the ISSUE_SUMMONER_CREDENTIAL_STORE
+// env variable, or the most secure store that is available when the variable is not set.
+func NewCredentialStore() (CredentialStore, error) {
+	return newCredentialStore(os.Getenv(credentialStoreEnv))
+}
+
+func newCredentialStore(name string) (CredentialStore, error) {
+	switch name {
+	case CredentialStoreKeyring:
+		return newKeyringStore()
+	case CredentialStoreEncrypted:
+		return newEncryptedFileStore(os.Getenv(credentialPassphraseEnv))
+	case CredentialStoreFile:
+		return newFileStore()
+	case "":
+		if store, err := newKeyringStore(); err == nil {
+			return store, nil
+		}
+
+		if passphrase := os.Getenv(credentialPassphraseEnv); passphrase != "" {
+			return newEncryptedFileStore(passphrase)
+		}
+
+		return newFileStore()
+	default:
+		return nil, fmt.Errorf(
+			"unsupported credential store %s. expected one of the following: %s %s %s",
+			name,
+			CredentialStoreKeyring,
+			CredentialStoreEncrypted,
+			CredentialStoreFile,
+		)
+	}
+}
+
+// CredentialKey returns the key that an access token is stored under, i.e. github/git.corp.example
+func CredentialKey(sch, host string) string {
+	if host == "" {
+		return sch
+	}
+	return sch + "/" + host
+}
+
+// NewAuthConfig writes the access token to the credential store and returns an [AuthConfig]
+// that references it. The token itself is never written to config.json.
+func NewAuthConfig(key, token string) (AuthConfig, error) {
+	store, err := NewCredentialStore()
+	if err != nil {
+		return AuthConfig{}, err
+	}
+
+	if err := store.Set(key, token); err != nil {
+		return AuthConfig{}, err
+	}
+
+	return AuthConfig{
+		CreatedAt:       time.Now(),
+		CredentialStore: store.Name(),
+		CredentialKey:   key,
+	}, nil
+}
+
+// Stored reports whether an access token has been stored for the auth config
+func (auth AuthConfig) Stored() bool {
+	return auth.AccessToken != "" || auth.CredentialKey != ""
+}
+
+// Token returns the access token for the auth config. Tokens that were written to
+// config.json, prior to credential stores being supported, are returned as is.
+func (auth AuthConfig) Token() (string, error) {
+	if auth.AccessToken != "" || auth.CredentialKey == "" {
+		return auth.AccessToken, nil
+	}
+
+	store, err := newCredentialStore(auth.CredentialStore)
+	if err != nil {
+		return "", err
+	}
+
+	return store.Get(auth.CredentialKey)
+}
+
+// Expired reports whether the access token has passed its expiration date. Tokens
+// without an expiration date never expire.
+func (auth AuthConfig) Expired() bool {
+	return !auth.ExpiresAt.IsZero() && time.Now().After(auth.ExpiresAt)
+}
+
+// SetRefreshToken writes a refresh token to the credential store that holds the access token
+func (auth *AuthConfig) SetRefreshToken(token string) error {
+	if auth.CredentialKey == "" {
+		return errors.New("the access token must be stored before a refresh token can be stored")
+	}
+
+	store, err := newCredentialStore(auth.CredentialStore)
+	if err != nil {
+		return err
+	}
+
+	key := auth.CredentialKey + "/refresh"
+	if err := store.Set(key, token); err != nil {
+		return err
+	}
+
+	auth.RefreshKey = key
+	return nil
+}
+
+// RefreshToken returns the refresh token for the auth config. An empty string is
+// returned when the access token cannot be refreshed.
+func (auth AuthConfig) RefreshToken() (string, error) {
+	if auth.RefreshKey == "" {
+		return "", nil
+	}
+
+	store, err := newCredentialStore(auth.CredentialStore)
+	if err != nil {
+		return "", err
+	}
+
+	return store.Get(auth.RefreshKey)
+}
+
+// MigrateTokens moves access tokens that are stored in plain text, in config.json, to the
+// credential store. The number of migrated tokens is returned. The config must be written
+// with [WriteToConfig] afterwards.
+func MigrateTokens(conf Config, store CredentialStore) (int, error) {
+	migrated := 0
+
+	migrate := func(auth *AuthConfig, key string) error {
+		if auth.AccessToken == "" {
+			return nil
+		}
+
+		if err := store.Set(key, auth.AccessToken); err != nil {
+			return err
+		}
+
+		auth.AccessToken = ""
+		auth.CredentialStore = store.Name()
+		auth.CredentialKey = key
+		migrated++
+		return nil
+	}
+
+	for sch, entry := range conf {
+		if err := migrate(&entry.Auth, CredentialKey(sch, "")); err != nil {
+			return migrated, err
+		}
+
+		for host, hostConf := range entry.Hosts {
+			if err := migrate(&hostConf.Auth, CredentialKey(sch, host)); err != nil {
+				return migrated, err
+			}
+			entry.Hosts[host] = hostConf
+		}
+
+		conf[sch] = entry
+	}
+
+	return migrated, nil
+}
+
+// keyringStore shells out to the keyring cli of the operating system so that
+// no cgo or dbus bindings are required
+type keyringStore struct {
+	bin string
+}
+
+func newKeyringStore() (*keyringStore, error) {
+	var bin string
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		bin = "secret-tool"
+	case "darwin":
+		bin = "security"
+	default:
+		return nil, fmt.Errorf("the %s credential store is not supported on %s", CredentialStoreKeyring, runtime.GOOS)
+	}
+
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return nil, fmt.Errorf("the %s credential store requires %s: %w", CredentialStoreKeyring, bin, err)
+	}
+
+	return &keyringStore{bin: path}, nil
+}
+
+func (k *keyringStore) Name() string {
+	return CredentialStoreKeyring
+}
+
+func (k *keyringStore) Get(key string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command(k.bin, "find-generic-password", "-s", credentialService, "-a", key, "-w")
+	} else {
+		cmd = exec.Command(k.bin, "lookup", "service", credentialService, "account", key)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	secret := strings.TrimSpace(string(out))
+	if err == nil && secret != "" {
+		return secret, nil
+	}
+
+	// secret-tool exits with 1, without any output, when the key is missing and security exits with
+	// errSecItemNotFound. Other failures, such as an unreachable dbus session or a locked keychain,
+	// are returned so that they are not mistaken for a missing token.
+	var exitErr *exec.ExitError
+	if err == nil || (errors.As(err, &exitErr) && k.notFound(exitErr.ExitCode(), secret, stderr.String())) {
+		return "", fmt.Errorf("%w: %s in %s", ErrCredentialNotFound, key, CredentialStoreKeyring)
+	}
+
+	return "", fmt.Errorf("failed to read %s from %s: %s %w", key, CredentialStoreKeyring, strings.TrimSpace(stderr.String()), err)
+}
+
+func (k *keyringStore) notFound(code int, stdout, stderr string) bool {
+	if runtime.GOOS == "darwin" {
+		return code == 44
+	}
+	return code == 1 && stdout == "" && strings.TrimSpace(stderr) == ""
+}
+
+func (k *keyringStore) Set(key, secret string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		// a trailing -w prompts for the secret, and its confirmation, so that it is not visible in the
+		// arguments of the process to other users
+		cmd = exec.Command(k.bin, "add-generic-password", "-U", "-s", credentialService, "-a", key, "-w")
+		cmd.Stdin = strings.NewReader(secret + "\n" + secret + "\n")
+	} else {
+		label := fmt.Sprintf("%s (%s)", credentialService, key)
+		cmd = exec.Command(k.bin, "store", "--label", label, "service", credentialService, "account", key)
+		cmd.Stdin = strings.NewReader(secret)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store %s in %s: %s %w", key, CredentialStoreKeyring, out, err)
+	}
+
+	return nil
+}
+
+func (k *keyringStore) Delete(key string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		cmd = exec.Command(k.bin, "delete-generic-password", "-s", credentialService, "-a", key)
+	} else {
+		cmd = exec.Command(k.bin, "clear", "service", credentialService, "account", key)
+	}
+
+	return cmd.Run()
+}
+
+// fileStore keeps secrets in a json file that can only be read by the current user
+type fileStore struct {
+	path string
+}
+
+func newFileStore() (*fileStore, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &fileStore{path: filepath.Join(dir, credentialFile)}, nil
+}
+
+func (f *fileStore) Name() string {
+	return CredentialStoreFile
+}
+
+func (f *fileStore) Get(key string) (string, error) {
+	secrets, err := f.read()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", ErrCredentialNotFound, key, f.path)
+	}
+
+	return secret, nil
+}
+
+func (f *fileStore) Set(key, secret string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = secret
+	return f.write(secrets)
+}
+
+func (f *fileStore) Delete(key string) error {
+	secrets, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	delete(secrets, key)
+	return f.write(secrets)
+}
+
+func (f *fileStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return secrets, nil
+	}
+
+	return secrets, json.Unmarshal(data, &secrets)
+}
+
+func (f *fileStore) write(secrets map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	return writePrivateFile(f.path, data)
+}
+
+// encryptedFileStore keeps secrets in a file that is encrypted with AES-256-GCM.
+// The key is derived from a passphrase using PBKDF2-HMAC-SHA256 and a random salt.
+type encryptedFileStore struct {
+	path       string
+	passphrase string
+}
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+func newEncryptedFileStore(passphrase string) (*encryptedFileStore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf(
+			"the %s credential store requires a passphrase. Set the %s env variable",
+			CredentialStoreEncrypted,
+			credentialPassphraseEnv,
+		)
+	}
+
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedFileStore{
+		path:       filepath.Join(dir, credentialEncryptedFile),
+		passphrase: passphrase,
+	}, nil
+}
+
+func (e *encryptedFileStore) Name() string {
+	return CredentialStoreEncrypted
+}
+
+func (e *encryptedFileStore) Get(key string) (string, error) {
+	secrets, err := e.read()
+	if err != nil {
+		return "", err
+	}
+
+	secret, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s in %s", ErrCredentialNotFound, key, e.path)
+	}
+
+	return secret, nil
+}
+
+func (e *encryptedFileStore) Set(key, secret string) error {
+	secrets, err := e.read()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = secret
+	return e.write(secrets)
+}
+
+func (e *encryptedFileStore) Delete(key string) error {
+	secrets, err := e.read()
+	if err != nil {
+		return err
+	}
+
+	delete(secrets, key)
+	return e.write(secrets)
+}
+
+func (e *encryptedFileStore) read() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return secrets, nil
+		}
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(e.passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s. Check the %s env variable", e.path, credentialPassphraseEnv)
+	}
+
+	return secrets, json.Unmarshal(plaintext, &secrets)
+}
+
+func (e *encryptedFileStore) write(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(e.passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	return writePrivateFile(e.path, data)
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := pbkdf2SHA256([]byte(passphrase), salt, pbkdf2Iterations, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 implements PBKDF2 (RFC 8018) with HMAC-SHA256 as the pseudorandom function
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, blocks*hashLen)
+	buf := make([]byte, 4)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u := prf.Sum(nil)
+
+		t := bytes.Clone(u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
+
+// writePrivateFile writes to a temporary file, that is only accessible by the
+// current user, and renames it so that secrets are never left partially written
+func writePrivateFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,202 @@
+package common_test
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestConfigDir points the user configuration directory at a temporary directory
+// so that tests never read or write the config files of the user running them
+func setTestConfigDir(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+
+	configDir, err := common.ConfigDir()
+	require.NoError(t, err)
+	return configDir
+}
+
+func TestCredentialStores(t *testing.T) {
+	testCases := []struct {
+		name       string
+		store      string
+		passphrase string
+		file       string
+	}{
+		{
+			name:  "Should store tokens in a file that is only readable by the current user",
+			store: common.CredentialStoreFile,
+			file:  "credentials.json",
+		},
+		{
+			name:       "Should store tokens in a passphrase encrypted file",
+			store:      common.CredentialStoreEncrypted,
+			passphrase: "correct horse battery staple",
+			file:       "credentials.enc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setTestConfigDir(t)
+			t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", tc.store)
+			t.Setenv("ISSUE_SUMMONER_PASSPHRASE", tc.passphrase)
+
+			auth, err := common.NewAuthConfig(common.CredentialKey("github", "github.com"), "secret-token")
+			require.NoError(t, err)
+			require.Empty(t, auth.AccessToken)
+			require.Equal(t, tc.store, auth.CredentialStore)
+			require.Equal(t, "github/github.com", auth.CredentialKey)
+
+			token, err := auth.Token()
+			require.NoError(t, err)
+			require.Equal(t, "secret-token", token)
+
+			data, err := os.ReadFile(filepath.Join(dir, tc.file))
+			require.NoError(t, err)
+			if tc.passphrase != "" {
+				require.NotContains(t, string(data), "secret-token")
+			}
+
+			if runtime.GOOS != "windows" {
+				info, err := os.Stat(filepath.Join(dir, tc.file))
+				require.NoError(t, err)
+				require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestEncryptedCredentialStoreWrongPassphrase(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", common.CredentialStoreEncrypted)
+	t.Setenv("ISSUE_SUMMONER_PASSPHRASE", "first passphrase")
+
+	auth, err := common.NewAuthConfig("azure", "secret-token")
+	require.NoError(t, err)
+
+	t.Setenv("ISSUE_SUMMONER_PASSPHRASE", "second passphrase")
+	_, err = auth.Token()
+	require.Error(t, err)
+}
+
+func TestKeyringCredentialStoreGet(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the keyring cli is faked with a shell script of secret-tool")
+	}
+
+	testCases := []struct {
+		name      string
+		script    string
+		expected  string
+		notFound  bool
+		errSubstr string
+	}{
+		{name: "Should read the secret", script: "echo secret-token", expected: "secret-token"},
+		{name: "Should report missing keys", script: "exit 1", notFound: true},
+		{
+			name:      "Should return other failures",
+			script:    "echo 'Cannot autolaunch D-Bus without X11 $DISPLAY' >&2; exit 1",
+			errSubstr: "Cannot autolaunch D-Bus",
+		},
+		{name: "Should return other exit codes", script: "exit 2", errSubstr: "exit status 2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			bin := filepath.Join(t.TempDir(), "secret-tool")
+			require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"+tc.script+"\n"), 0755))
+
+			secret, err := common.NewKeyringStore(bin).Get("github")
+			switch {
+			case tc.notFound:
+				require.ErrorIs(t, err, common.ErrCredentialNotFound)
+			case tc.errSubstr != "":
+				require.ErrorContains(t, err, tc.errSubstr)
+				require.NotErrorIs(t, err, common.ErrCredentialNotFound, "a broken keyring is not a missing token")
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, secret)
+			}
+		})
+	}
+}
+
+// the test vectors of PBKDF2-HMAC-SHA256 from RFC 7914, section 11
+func TestPBKDF2SHA256(t *testing.T) {
+	testCases := []struct {
+		password   string
+		salt       string
+		iterations int
+		expected   string
+	}{
+		{
+			password:   "passwd",
+			salt:       "salt",
+			iterations: 1,
+			expected:   "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			password:   "Password",
+			salt:       "NaCl",
+			iterations: 80000,
+			expected:   "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+
+	for _, tc := range testCases {
+		key := common.PBKDF2SHA256([]byte(tc.password), []byte(tc.salt), tc.iterations, 64)
+		require.Equal(t, tc.expected, hex.EncodeToString(key))
+	}
+}
+
+func TestMigrateTokens(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", common.CredentialStoreFile)
+
+	store, err := common.NewCredentialStore()
+	require.NoError(t, err)
+
+	conf := common.Config{
+		"github": {
+			Auth: common.AuthConfig{AccessToken: "legacy-token"},
+			Hosts: map[string]common.HostConfig{
+				"git.corp.example": {Auth: common.AuthConfig{AccessToken: "ghes-token"}},
+			},
+		},
+		"gitlab": {},
+	}
+
+	migrated, err := common.MigrateTokens(conf, store)
+	require.NoError(t, err)
+	require.Equal(t, 2, migrated)
+
+	require.Empty(t, conf["github"].Auth.AccessToken)
+	require.Empty(t, conf["github"].Hosts["git.corp.example"].Auth.AccessToken)
+
+	token, err := conf["github"].Auth.Token()
+	require.NoError(t, err)
+	require.Equal(t, "legacy-token", token)
+
+	token, err = conf["github"].Hosts["git.corp.example"].Auth.Token()
+	require.NoError(t, err)
+	require.Equal(t, "ghes-token", token)
+
+	require.NoError(t, common.WriteToConfig(conf))
+	if runtime.GOOS != "windows" {
+		dir, err := common.ConfigDir()
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(dir, "config.json"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
@@ -0,0 +1,9 @@
+package common
+
+// NewKeyringStore creates a keyring store that runs [bin] rather than the keyring cli of the
+// operating system
+func NewKeyringStore(bin string) CredentialStore {
+	return &keyringStore{bin: bin}
+}
+
+var PBKDF2SHA256 = pbkdf2SHA256
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// length of each fingerprint hash, in hex characters
+const fingerprintLength = 16
+
+var fingerprintMarker = regexp.MustCompile(
+	`<!--\s*issue-summoner fingerprint=([0-9a-f]+) content=([0-9a-f]+)\s*-->`,
+)
+
+// Fingerprint identifies an annotation independent of its position in the file, so that
+// comments can be matched to reported issues after code moves. Location changes when the
+// comment moves to another file or symbol, while Content only changes when the text of the
+// comment, or the symbol that encloses it, changes. Content is used to match comments in
+// files that were renamed.
+type Fingerprint struct {
+	Location string `json:"location,omitempty"` // hash of the comment text, enclosing symbol and file path
+	Content  string `json:"content,omitempty"`  // hash of the comment text and enclosing symbol
+}
+
+// NewFingerprint hashes the comment text, which is normalized by ignoring case and repeated
+// whitespace, the name of the enclosing symbol and the path of the file relative to the work tree.
+// [occurrence] distinguishes identical comments within the same symbol and is 1 for the first.
+func NewFingerprint(text, symbol, path string, occurrence int) Fingerprint {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	content := text + "\x00" + symbol
+	if occurrence > 1 {
+		content += fmt.Sprintf("\x00%d", occurrence)
+	}
+
+	return Fingerprint{
+		Location: hashFingerprint(content + "\x00" + filepath.ToSlash(path)),
+		Content:  hashFingerprint(content),
+	}
+}
+
+func hashFingerprint(val string) string {
+	sum := sha256.Sum256([]byte(val))
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+// Marker returns an html comment that is embedded in the body of reported issues. Html
+// comments are not rendered by github, which keeps the marker hidden from readers.
+func (fp Fingerprint) Marker() string {
+	return fmt.Sprintf("<!-- issue-summoner fingerprint=%s content=%s -->", fp.Location, fp.Content)
+}
+
+// ParseFingerprint locates the marker in the body of an issue
+func ParseFingerprint(body string) (Fingerprint, bool) {
+	match := fingerprintMarker.FindStringSubmatch(body)
+	if match == nil {
+		return Fingerprint{}, false
+	}
+	return Fingerprint{Location: match[1], Content: match[2]}, true
+}
+
+// IsZero reports whether the fingerprint was not computed, i.e. it was read from an older journal
+func (fp Fingerprint) IsZero() bool {
+	return fp.Location == "" && fp.Content == ""
+}
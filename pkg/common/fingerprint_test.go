@@ -0,0 +1,38 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	fp := common.NewFingerprint("Handle nested   comments", "Lex", "pkg/lexer/lexer.go", 1)
+
+	// case and whitespace do not change the fingerprint
+	require.Equal(t, fp, common.NewFingerprint("handle nested comments", "Lex", "pkg/lexer/lexer.go", 1))
+
+	renamed := common.NewFingerprint("handle nested comments", "Lex", "pkg/scanner/lexer.go", 1)
+	require.NotEqual(t, fp.Location, renamed.Location)
+	require.Equal(t, fp.Content, renamed.Content)
+
+	moved := common.NewFingerprint("handle nested comments", "Scan", "pkg/lexer/lexer.go", 1)
+	require.NotEqual(t, fp.Content, moved.Content)
+
+	second := common.NewFingerprint("handle nested comments", "Lex", "pkg/lexer/lexer.go", 2)
+	require.NotEqual(t, fp.Location, second.Location)
+
+	body := "### Description\nfix me\n\n" + fp.Marker()
+	parsed, ok := common.ParseFingerprint(body)
+	require.True(t, ok)
+	require.Equal(t, fp, parsed)
+
+	parsed, ok = common.ParseFingerprint(common.MarkdownToHTML(body))
+	require.True(t, ok)
+	require.Equal(t, fp, parsed)
+
+	_, ok = common.ParseFingerprint("### Description\nfix me")
+	require.False(t, ok)
+	require.True(t, common.Fingerprint{}.IsZero())
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime/debug"
 	"time"
@@ -25,6 +26,7 @@ type Logger struct {
 	hintStyle      lipgloss.Style
 	infoStyle      lipgloss.Style
 	debugIndicator bool
+	out            io.Writer // destination of every message other than errors and prompts
 }
 
 func NewLogger(debugIndicator bool) *Logger {
@@ -35,15 +37,22 @@ func NewLogger(debugIndicator bool) *Logger {
 		hintStyle:      ui.SecondaryTextStyle,
 		infoStyle:      ui.DimTextStyle,
 		debugIndicator: debugIndicator,
+		out:            os.Stdout,
 	}
 }
 
+// SetOutput changes the destination of messages. Commands that write machine readable
+// output to stdout, such as <issue-summoner scan --format json>, log to stderr instead.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.out = w
+}
+
 func (l *Logger) Fatal(message string) {
 	level := l.errorStyle.Render(fmt.Sprintf(fatal_level, getTimeStamp()))
-	fmt.Fprintf(os.Stderr, level, message)
+	fmt.Fprintf(os.Stderr, "%s %s\n", level, message)
 
 	if l.debugIndicator {
-		fmt.Printf("\n%s\n", string(debug.Stack()))
+		fmt.Fprintf(os.Stderr, "\n%s\n", string(debug.Stack()))
 	}
 
 	os.Exit(1)
@@ -51,26 +60,26 @@ func (l *Logger) Fatal(message string) {
 
 func (l *Logger) Success(message string) {
 	level := l.successStyle.Render(fmt.Sprintf(success_level, getTimeStamp()))
-	fmt.Printf("%s %s\n", level, message)
+	fmt.Fprintf(l.out, "%s %s\n", level, message)
 }
 
 func (l *Logger) Warning(message string) {
 	level := l.warningStyle.Render(fmt.Sprintf(warn_level, getTimeStamp()))
-	fmt.Printf("%s %s\n", level, message)
+	fmt.Fprintf(l.out, "%s %s\n", level, message)
 }
 
 func (l *Logger) Hint(message string) {
 	level := l.hintStyle.Render(fmt.Sprintf(hint_level, getTimeStamp()))
-	fmt.Printf("%s %s\n", level, message)
+	fmt.Fprintf(l.out, "%s %s\n", level, message)
 }
 
 func (l *Logger) Print(message string) {
-	fmt.Println(message)
+	fmt.Fprintln(l.out, message)
 }
 
 func (l *Logger) Info(message string) {
 	level := l.infoStyle.Render(fmt.Sprintf(info_level, getTimeStamp()))
-	fmt.Printf("%s %s\n", level, message)
+	fmt.Fprintf(l.out, "%s %s\n", level, message)
 }
 
 func (l *Logger) PrintStdout(message string) {
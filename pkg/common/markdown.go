@@ -0,0 +1,72 @@
+package common
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdCode       = regexp.MustCompile("`([^`]+)`")
+	mdBoldItalic = regexp.MustCompile(`\*\*\*([^*]+)\*\*\*`)
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalic     = regexp.MustCompile(`\*([^*]+)\*`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdHeading    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+)
+
+// MarkdownToHTML converts the small subset of markdown that is used by the issue
+// template (headings, unordered lists, code spans, emphasis and links) into html.
+// Some hosting platforms, such as azure devops, render issue descriptions as html
+// rather than markdown. It is not meant to be a complete markdown implementation.
+func MarkdownToHTML(md string) string {
+	buf := strings.Builder{}
+	inList := false
+
+	closeList := func() {
+		if inList {
+			buf.WriteString("</ul>")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			closeList()
+		case strings.HasPrefix(line, "<!--") && strings.HasSuffix(line, "-->"):
+			// html comments, such as the fingerprint marker, are kept as is
+			closeList()
+			buf.WriteString(line)
+		case mdHeading.MatchString(line):
+			closeList()
+			match := mdHeading.FindStringSubmatch(line)
+			level := len(match[1])
+			buf.WriteString(fmt.Sprintf("<h%d>%s</h%d>", level, renderInline(match[2]), level))
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			if !inList {
+				buf.WriteString("<ul>")
+				inList = true
+			}
+			buf.WriteString("<li>" + renderInline(line[2:]) + "</li>")
+		default:
+			closeList()
+			buf.WriteString("<p>" + renderInline(line) + "</p>")
+		}
+	}
+
+	closeList()
+	return buf.String()
+}
+
+func renderInline(text string) string {
+	text = html.EscapeString(text)
+	text = mdCode.ReplaceAllString(text, "<code>$1</code>")
+	text = mdLink.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = mdBoldItalic.ReplaceAllString(text, "<strong><em>$1</em></strong>")
+	text = mdBold.ReplaceAllString(text, "<strong>$1</strong>")
+	return mdItalic.ReplaceAllString(text, "<em>$1</em>")
+}
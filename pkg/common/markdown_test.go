@@ -0,0 +1,43 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		md       string
+		expected string
+	}{
+		{
+			name:     "Should convert headings and paragraphs",
+			md:       "### Description\nfix the <bug>",
+			expected: "<h3>Description</h3><p>fix the &lt;bug&gt;</p>",
+		},
+		{
+			name:     "Should convert unordered lists with emphasis and code spans",
+			md:       "- ***File name:*** `main.go`\n- ***Line number:*** `4`\n\ndone",
+			expected: "<ul><li><strong><em>File name:</em></strong> <code>main.go</code></li><li><strong><em>Line number:</em></strong> <code>4</code></li></ul><p>done</p>",
+		},
+		{
+			name:     "Should convert links",
+			md:       "- created by [issue-summoner](https://github.com/AntoninoAdornetto/issue-summoner)",
+			expected: `<ul><li>created by <a href="https://github.com/AntoninoAdornetto/issue-summoner">issue-summoner</a></li></ul>`,
+		},
+		{
+			name:     "Should keep html comments",
+			md:       "done\n\n<!-- issue-summoner fingerprint=abc content=def -->",
+			expected: "<p>done</p><!-- issue-summoner fingerprint=abc content=def -->",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, common.MarkdownToHTML(tc.md))
+		})
+	}
+}
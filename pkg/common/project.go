@@ -0,0 +1,93 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const ProjectConfigFile = ".issue-summoner.yaml"
+
+// ProjectConfig contains the settings, for a single repository, that are read from the
+// .issue-summoner.yaml file at the root of the working tree. The file is meant to be
+// committed so that a team shares the same settings. Command line flags take precedence
+// over the project config, which takes precedence over the profile in config.json.
+type ProjectConfig struct {
+	Annotation  string              `yaml:"annotation,omitempty"`  // annotation to search for, see the --annotation flag
+	Sch         string              `yaml:"sch,omitempty"`         // source code host, or external backend, to report issues to
+	Profile     string              `yaml:"profile,omitempty"`     // config.json profile, see [IssueSummonerConfig]
+	Repository  string              `yaml:"repository,omitempty"`  // owner/name of the repository to report issues to
+	Labels      []string            `yaml:"labels,omitempty"`      // labels applied to every reported issue
+	Template    string              `yaml:"template,omitempty"`    // issue body template, relative to the working tree
+	Include     []string            `yaml:"include,omitempty"`     // globs of files to scan, i.e. src/**/*.go
+	Exclude     []string            `yaml:"exclude,omitempty"`     // globs of files to skip, i.e. **/*_test.go
+	WriteBack   string              `yaml:"writeBack,omitempty"`   // format of the issue number written to the annotation, i.e. (#%d)
+	Check       Policy              `yaml:"check,omitempty"`       // rules that are enforced by <issue-summoner check>
+	Tracked     bool                `yaml:"tracked,omitempty"`     // scan the files in the git index rather than walking the working tree
+	Authors     map[string]string   `yaml:"authors,omitempty"`     // usernames of commit authors by email, see <issue-summoner report --assign-author>
+	OwnerLabels map[string][]string `yaml:"ownerLabels,omitempty"` // labels of the issues that are routed to a CODEOWNERS owner, see <issue-summoner report --codeowners>
+	Path        string              `yaml:"-"`                     // location of the file, empty when the file does not exist
+}
+
+// Policy contains the annotation rules of a repository, which <issue-summoner check> enforces
+// in CI. Rules that are not set are disabled.
+type Policy struct {
+	Annotations    []string       `yaml:"annotations,omitempty"`    // annotations to check, defaults to the annotation setting
+	RequireIssue   bool           `yaml:"requireIssue,omitempty"`   // every annotation must reference an issue, i.e. @TODO(#12)
+	MaxCount       map[string]int `yaml:"maxCount,omitempty"`       // maximum number of each annotation, i.e. "@TODO": 50
+	ForbiddenPaths []string       `yaml:"forbiddenPaths,omitempty"` // globs of files that may not contain annotations
+	MaxAge         string         `yaml:"maxAge,omitempty"`         // maximum age of an annotation, see [ParseAge]
+}
+
+// ParseAge parses a duration that may be written in days or weeks, i.e. 90d or 12w, in addition
+// to the units of [time.ParseDuration]
+func ParseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(age, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q. Expected a number of days or weeks, i.e. 90d or 12w", age)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q. Expected a number of days or weeks, i.e. 90d or 12w", age)
+	}
+	return duration, nil
+}
+
+// ReadProjectConfig reads the project config file from the working tree. An empty
+// config is returned when the file does not exist.
+func ReadProjectConfig(workTree string) (ProjectConfig, error) {
+	var conf ProjectConfig
+	path := filepath.Join(workTree, ProjectConfigFile)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return conf, nil
+		}
+		return conf, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&conf); err != nil && !errors.Is(err, io.EOF) {
+		return conf, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	conf.Path = path
+	return conf, nil
+}
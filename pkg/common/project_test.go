@@ -0,0 +1,125 @@
+package common_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProjectConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		expected common.ProjectConfig
+		err      bool
+	}{
+		{
+			name: "Should read every setting from the project config file",
+			contents: `annotation: "@FIXME"
+sch: local
+profile: work
+repository: acme/api
+labels: [tech-debt, cleanup]
+template: .github/issue.tmpl
+include: ["src/**"]
+exclude: ["**/*_test.go"]
+writeBack: "(ENG-%d)"
+tracked: true
+authors:
+  jane@example.com: jdoe
+ownerLabels:
+  "@acme/payments": [payments]
+check:
+  requireIssue: true
+  maxCount:
+    "@FIXME": 10
+  forbiddenPaths: ["cmd/**"]
+  maxAge: 90d
+`,
+			expected: common.ProjectConfig{
+				Annotation:  "@FIXME",
+				Sch:         "local",
+				Profile:     "work",
+				Repository:  "acme/api",
+				Labels:      []string{"tech-debt", "cleanup"},
+				Template:    ".github/issue.tmpl",
+				Include:     []string{"src/**"},
+				Exclude:     []string{"**/*_test.go"},
+				WriteBack:   "(ENG-%d)",
+				Tracked:     true,
+				Authors:     map[string]string{"jane@example.com": "jdoe"},
+				OwnerLabels: map[string][]string{"@acme/payments": {"payments"}},
+				Check: common.Policy{
+					RequireIssue:   true,
+					MaxCount:       map[string]int{"@FIXME": 10},
+					ForbiddenPaths: []string{"cmd/**"},
+					MaxAge:         "90d",
+				},
+			},
+		},
+		{
+			name:     "Should accept an empty project config file",
+			contents: "",
+		},
+		{
+			name:     "Should reject unknown settings",
+			contents: "annotations: \"@FIXME\"\n",
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, common.ProjectConfigFile)
+			require.NoError(t, os.WriteFile(path, []byte(tc.contents), 0644))
+
+			conf, err := common.ReadProjectConfig(dir)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			tc.expected.Path = path
+			require.Equal(t, tc.expected, conf)
+		})
+	}
+}
+
+func TestReadProjectConfigMissing(t *testing.T) {
+	conf, err := common.ReadProjectConfig(t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, common.ProjectConfig{}, conf)
+}
+
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		age      string
+		expected time.Duration
+		err      bool
+	}{
+		{age: "90d", expected: 90 * 24 * time.Hour},
+		{age: "2w", expected: 14 * 24 * time.Hour},
+		{age: "36h", expected: 36 * time.Hour},
+		{age: "-1d", err: true},
+		{age: "soon", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.age, func(t *testing.T) {
+			age, err := common.ParseAge(tc.age)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, age)
+		})
+	}
+}
@@ -1,10 +1,17 @@
 package common
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 func ConstructURL(base string, qParams map[string]string, paths ...string) (string, error) {
@@ -25,21 +32,270 @@ func ConstructURL(base string, qParams map[string]string, paths ...string) (stri
 	return u, nil
 }
 
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxRetries  = 4
+	DefaultBaseDelay   = time.Second
+	DefaultMaxDelay    = 30 * time.Second
+	DefaultMaxWait     = 5 * time.Minute
+	DefaultConcurrency = 4
+)
+
+// Client is shared by every source code host so that rate limits, which are tracked per
+// account rather than per request, are respected across go routines. Idempotent requests are
+// retried with exponential backoff when the network fails or the server responds with a 5xx
+// status. Other requests, such as the POST that creates an issue, may have succeeded on the
+// server in that case, so they are only retried when they were rate limited. Rate limited
+// requests wait for the duration found in the Retry-After or X-RateLimit-Reset
+// headers and pause every other request that is sent with the client in the meantime.
+type Client struct {
+	HTTP       *http.Client
+	MaxRetries int           // number of retries after the initial attempt
+	BaseDelay  time.Duration // delay prior to the first retry, doubled for every retry
+	MaxDelay   time.Duration // upper bound of the backoff delay
+	MaxWait    time.Duration // rate limits that reset later than MaxWait are returned to the caller
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// DefaultClient is used by [Request] and [RequestContext]
+var DefaultClient = NewClient()
+
+func NewClient() *Client {
+	return &Client{
+		HTTP:       &http.Client{Timeout: DefaultTimeout},
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+		MaxWait:    DefaultMaxWait,
+	}
+}
+
 func Request(method, url string, body io.Reader, h http.Header) (*http.Response, []byte, error) {
-	var res []byte
-	req, err := http.NewRequest(method, url, body)
+	return RequestContext(context.Background(), method, url, body, h)
+}
+
+func RequestContext(ctx context.Context, method, url string, body io.Reader, h http.Header) (*http.Response, []byte, error) {
+	return DefaultClient.Do(ctx, method, url, body, h)
+}
+
+// Do sends the request and returns the response along with its body. The body of the request
+// is buffered so that it can be sent again when the request is retried.
+func (c *Client) Do(ctx context.Context, method, url string, body io.Reader, h http.Header) (*http.Response, []byte, error) {
+	var payload []byte
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		payload = data
+	}
+
+	idempotent := idempotentMethod(method)
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForPause(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		resp, data, err := c.send(ctx, method, url, payload, h)
+		last := attempt >= c.MaxRetries
+
+		if err != nil {
+			if ctx.Err() != nil || last || !idempotent {
+				return nil, nil, err
+			}
+
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if wait, limited := rateLimitWait(resp, data); limited {
+			if last || wait > c.MaxWait {
+				return resp, data, nil
+			}
+
+			if wait <= 0 {
+				wait = c.backoff(attempt)
+			}
+
+			c.pause(wait)
+			continue
+		}
+
+		// the remaining requests have been used up, so the next request would be rejected
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, ok := rateLimitReset(resp); ok && reset <= c.MaxWait {
+				c.pause(reset)
+			}
+		}
+
+		if resp.StatusCode >= http.StatusInternalServerError && idempotent && !last {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		return resp, data, nil
+	}
+}
+
+// idempotentMethod reports whether sending a request again has the same effect as sending it once
+func idempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, url string, payload []byte, h http.Header) (*http.Response, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return nil, res, err
+		return nil, nil, err
+	}
+
+	if h != nil {
+		req.Header = h.Clone()
 	}
 
-	req.Header = h
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return nil, res, err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
 	return resp, data, err
 }
+
+// backoff doubles the base delay for every attempt and adds up to 50% jitter so that
+// go routines that failed at the same time do not retry at the same time
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.BaseDelay << attempt
+	if delay <= 0 || delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+func (c *Client) pause(wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if until := time.Now().Add(wait); until.After(c.pausedUntil) {
+		c.pausedUntil = until
+	}
+}
+
+func (c *Client) waitForPause(ctx context.Context) error {
+	c.mu.Lock()
+	wait := time.Until(c.pausedUntil)
+	c.mu.Unlock()
+
+	return sleep(ctx, wait)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimitWait reports whether the response was rate limited and how long to wait before
+// retrying. A wait of 0 indicates that the server did not say how long to wait. GitHub
+// responds to secondary rate limits with a 403 status code rather than 429.
+func rateLimitWait(resp *http.Response, data []byte) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		break
+	case http.StatusForbidden:
+		limited := resp.Header.Get("Retry-After") != "" ||
+			resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			bytes.Contains(bytes.ToLower(data), []byte("rate limit"))
+		if !limited {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if retryAfter := strings.TrimSpace(resp.Header.Get("Retry-After")); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return time.Until(at), true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := rateLimitReset(resp); ok {
+			return reset, true
+		}
+	}
+
+	return 0, true
+}
+
+// rateLimitReset returns the time until the X-RateLimit-Reset header, which is a unix timestamp
+func rateLimitReset(resp *http.Response) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Until(time.Unix(reset, 0)) + time.Second, true
+}
+
+// ForEach invokes fn for every index in [0, n) using at most [workers] go routines and
+// returns once every invocation has finished
+func ForEach(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = DefaultConcurrency
+	}
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,205 @@
+package common_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient() *common.Client {
+	client := common.NewClient()
+	client.BaseDelay = time.Millisecond
+	client.MaxDelay = 5 * time.Millisecond
+	return client
+}
+
+func TestClientRetries(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string           // defaults to PUT
+		failures int              // number of requests that fail prior to succeeding
+		fail     http.HandlerFunc // response for failed requests
+		maxWait  time.Duration    // zero keeps the default
+		status   int              // expected status code
+		requests int32            // expected number of requests
+	}{
+		{
+			name:     "Should retry requests that fail with a 5xx status code",
+			failures: 2,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			status:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "Should retry requests that were rate limited",
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			status:   http.StatusOK,
+			requests: 2,
+		},
+		{
+			name:     "Should retry requests that hit a secondary rate limit",
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+			},
+			status:   http.StatusOK,
+			requests: 2,
+		},
+		{
+			name:     "Should return rate limited responses that reset after the max wait",
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", "4102444800")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			maxWait:  time.Second,
+			status:   http.StatusForbidden,
+			requests: 1,
+		},
+		{
+			name:     "Should stop retrying after the max number of retries",
+			failures: 10,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			status:   http.StatusServiceUnavailable,
+			requests: common.DefaultMaxRetries + 1,
+		},
+		{
+			name:     "Should not retry POST requests that fail with a 5xx status code",
+			method:   http.MethodPost,
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			status:   http.StatusBadGateway,
+			requests: 1,
+		},
+		{
+			name:     "Should retry POST requests that were rate limited",
+			method:   http.MethodPost,
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			status:   http.StatusOK,
+			requests: 2,
+		},
+		{
+			name:     "Should not retry client errors",
+			failures: 1,
+			fail: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			status:   http.StatusNotFound,
+			requests: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				require.Equal(t, "payload", string(body))
+
+				if int(requests.Add(1)) <= tc.failures {
+					tc.fail(w, r)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			client := newTestClient()
+			if tc.maxWait != 0 {
+				client.MaxWait = tc.maxWait
+			}
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodPut
+			}
+
+			resp, _, err := client.Do(context.Background(), method, srv.URL, strings.NewReader("payload"), nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode)
+			require.Equal(t, tc.requests, requests.Load())
+		})
+	}
+}
+
+func TestClientNetworkErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	client := newTestClient()
+	_, _, err := client.Do(context.Background(), http.MethodPost, srv.URL, strings.NewReader("payload"), nil)
+	require.Error(t, err)
+	require.Equal(t, int32(1), requests.Load(), "the issue may have been created before the connection was lost")
+
+	requests.Store(0)
+	_, _, err = client.Do(context.Background(), http.MethodGet, srv.URL, nil, nil)
+	require.Error(t, err)
+	require.Equal(t, int32(common.DefaultMaxRetries+1), requests.Load())
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := newTestClient()
+	client.BaseDelay = time.Hour
+	client.MaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.Do(ctx, "GET", srv.URL, nil, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestForEach(t *testing.T) {
+	var running, peak, calls atomic.Int32
+	common.ForEach(20, 3, func(i int) {
+		current := running.Add(1)
+		for {
+			max := peak.Load()
+			if current <= max || peak.CompareAndSwap(max, current) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		calls.Add(1)
+	})
+
+	require.Equal(t, int32(20), calls.Load())
+	require.LessOrEqual(t, peak.Load(), int32(3))
+}
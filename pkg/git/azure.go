@@ -0,0 +1,497 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+)
+
+const (
+	azureBaseUrl             = "https://dev.azure.com"
+	azureApiVersion          = "7.1"
+	azureDefaultWorkItemType = "Task"
+	azureDefaultClosedState  = "Removed"
+)
+
+// work item states, across the default azure boards processes (Basic, Agile, Scrum, CMMI),
+// that indicate the work item is no longer being worked on.
+var azureResolvedStates = []string{"closed", "done", "resolved", "removed"}
+
+type azureManager struct {
+	conf         common.Config
+	repo         *Repository
+	profile      Profile
+	baseUrl      string // organization url, overridden by the profile for azure devops server
+	opts         ManagerOptions
+	headers      http.Header
+	reportURL    string
+	workItemType string
+	closedState  string // state that orphaned work items are moved to, see [azureManager.Close]
+	token        string // resolved access token, see [resolveToken]
+}
+
+func newAzureManager(conf common.Config, repo *Repository, profile Profile, opts ManagerOptions) (*azureManager, error) {
+	if repo.Project == "" {
+		return nil, fmt.Errorf(
+			"failed to locate azure devops project from remote url %s. Expected a dev.azure.com remote",
+			repo.remoteUrl,
+		)
+	}
+
+	azure := &azureManager{
+		conf:         conf,
+		repo:         repo,
+		profile:      profile,
+		baseUrl:      azureBaseUrl,
+		opts:         opts,
+		workItemType: azureDefaultWorkItemType,
+		closedState:  azureDefaultClosedState,
+	}
+
+	entry := profile.Config
+	if entry.WorkItemType != "" {
+		azure.workItemType = entry.WorkItemType
+	}
+
+	if entry.ClosedState != "" {
+		azure.closedState = entry.ClosedState
+	}
+
+	if entry.ApiBaseUrl != "" {
+		azure.baseUrl = strings.TrimSuffix(entry.ApiBaseUrl, "/")
+	}
+
+	if opts.ApiBaseUrl != "" {
+		azure.baseUrl = strings.TrimSuffix(opts.ApiBaseUrl, "/")
+	}
+
+	token, _, err := resolveToken(tokenRequest{
+		host:      repo.Host,
+		tokenFile: opts.TokenFile,
+		source:    entry.TokenSource,
+		envVars:   []string{"AZURE_DEVOPS_EXT_PAT"},
+		helper:    entry.Hosts[repo.Host].CredentialHelper,
+		stored:    entry.Auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	azure.token = token
+	azure.prepareHeaders()
+
+	paths := []string{
+		repo.UserName,
+		repo.Project,
+		"_apis",
+		"wit",
+		"workitems",
+		"$" + azure.workItemType,
+	}
+
+	params := map[string]string{"api-version": azureApiVersion}
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	azure.reportURL = u
+	return azure, nil
+}
+
+// azure devops personal access tokens are sent using basic authentication
+// with an empty user name.
+func (azure *azureManager) prepareHeaders() {
+	creds := base64.StdEncoding.EncodeToString([]byte(":" + azure.token))
+	header := make(http.Header)
+	header.Add("Accept", "application/json")
+	header.Add("Authorization", "Basic "+creds)
+	azure.headers = header
+}
+
+// Authorize opens the personal access token page for the organization and reads
+// the token, created by the user, from stdin. Azure devops does not offer a device
+// flow for OAuth apps that is comparable to GitHub's, so a personal access token
+// with the "Work Items (Read & Write)" scope is required.
+func (azure *azureManager) Authorize() error {
+	tokenPage := fmt.Sprintf("%s/%s/_usersSettings/tokens", azure.baseUrl, azure.repo.UserName)
+	fmt.Printf(
+		"Create a personal access token with the Work Items (Read & Write) scope at %s\n",
+		tokenPage,
+	)
+
+	if err := azure.opts.openBrowser(tokenPage); err != nil {
+		fmt.Printf("Failed to open default browser. Please open a browser and visit %s\n", tokenPage)
+	}
+
+	fmt.Print("Paste your personal access token: ")
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return errors.New("failed to read personal access token from stdin")
+	}
+
+	return azure.StoreToken(scanner.Text())
+}
+
+// StoreToken writes a personal access token to the credential store and references it in config.json
+func (azure *azureManager) StoreToken(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("expected a personal access token but got an empty string")
+	}
+
+	auth, err := common.NewAuthConfig(common.CredentialKey(azure.profile.Name, ""), token)
+	if err != nil {
+		return err
+	}
+
+	entry, ok := azure.conf[azure.profile.Name]
+	if !ok {
+		entry = azure.profile.Config
+	}
+
+	entry.Auth = auth
+	azure.conf[azure.profile.Name] = entry
+	return common.WriteToConfig(azure.conf)
+}
+
+// Validate requests the project of the repository. Azure devops does not report the owner, scopes
+// or expiration date of personal access tokens, so only revoked and expired tokens are detected.
+// Requests with an invalid token are answered with a sign in page and a 203 status code.
+func (azure *azureManager) Validate() (TokenInfo, error) {
+	info := TokenInfo{}
+	if azure.token == "" {
+		return info, &AuthError{Sch: Azure, Profile: azure.profile.Name, Reason: "was not found"}
+	}
+
+	paths := []string{azure.repo.UserName, "_apis", "projects", azure.repo.Project}
+	params := map[string]string{"api-version": azureApiVersion}
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		return info, err
+	}
+
+	resp, data, err := azure.opts.request("GET", u, nil, azure.headers)
+	if err != nil {
+		return info, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return info, nil
+	case http.StatusUnauthorized, http.StatusNonAuthoritativeInfo:
+		return info, &AuthError{Sch: Azure, Profile: azure.profile.Name, Reason: "is invalid, expired or has been revoked"}
+	default:
+		errRes := onGetIssueError(data)
+		return info, fmt.Errorf("failed to validate access token: %s with status code: %d", errRes.Message, resp.StatusCode)
+	}
+}
+
+func (azure *azureManager) Authenticated() bool {
+	_, err := azure.Validate()
+	return err == nil
+}
+
+// json patch operation, used to set fields when creating work items
+type azurePatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+type azureWorkItemResponse struct {
+	ID     int            `json:"id"`
+	Fields map[string]any `json:"fields"`
+}
+
+func (azure *azureManager) Report(issue ReportRequest, res chan ReportResponse) {
+	result := ReportResponse{Index: issue.Index}
+
+	operations := []azurePatchOperation{
+		{Op: "add", Path: "/fields/System.Title", Value: issue.Title},
+		{Op: "add", Path: "/fields/System.Description", Value: common.MarkdownToHTML(issue.Body)},
+	}
+
+	// azure boards has no labels, tags are used instead
+	if labels := mergeLabels(azure.profile.Config.Labels, issue.Labels); len(labels) > 0 {
+		operations = append(operations, azurePatchOperation{
+			Op:    "add",
+			Path:  "/fields/System.Tags",
+			Value: strings.Join(labels, "; "),
+		})
+	}
+
+	// work items have a single assignee
+	if len(issue.Assignees) > 0 {
+		operations = append(operations, azurePatchOperation{
+			Op:    "add",
+			Path:  "/fields/System.AssignedTo",
+			Value: issue.Assignees[0],
+		})
+	}
+
+	data, err := json.Marshal(operations)
+	if err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	if skip, err := azure.opts.dryRun("POST", azure.reportURL, data); skip || err != nil {
+		if err != nil {
+			result.Err = fmt.Errorf(errReport, issue.Title, err)
+		}
+		res <- result
+		return
+	}
+
+	headers := azure.headers.Clone()
+	headers.Set("Content-Type", "application/json-patch+json")
+
+	resp, data, err := azure.opts.request("POST", azure.reportURL, bytes.NewBuffer(data), headers)
+	if err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		result.Err = createIssueErr(data, resp.StatusCode, issue.Title)
+		res <- result
+		return
+	}
+
+	workItem := azureWorkItemResponse{}
+	if err := json.Unmarshal(data, &workItem); err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	result.ID = workItem.ID
+	res <- result
+}
+
+func (azure *azureManager) GetStatus(issueNum, index int, status chan StatusResponse) {
+	res := StatusResponse{Index: index, Resolved: false}
+
+	paths := []string{
+		azure.repo.UserName,
+		azure.repo.Project,
+		"_apis",
+		"wit",
+		"workitems",
+		strconv.Itoa(issueNum),
+	}
+
+	params := map[string]string{"api-version": azureApiVersion, "fields": "System.State"}
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	resp, data, err := azure.opts.request("GET", u, nil, azure.headers)
+	if err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errRes := onGetIssueError(data)
+		res.Err = fmt.Errorf("%s with status code: %d", errRes.Message, resp.StatusCode)
+		status <- res
+		return
+	}
+
+	workItem := azureWorkItemResponse{}
+	if err := json.Unmarshal(data, &workItem); err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	state, _ := workItem.Fields["System.State"].(string)
+	state = strings.ToLower(state)
+	for _, resolved := range azureResolvedStates {
+		if state == resolved {
+			res.Resolved = true
+			break
+		}
+	}
+
+	status <- res
+}
+
+// Close moves the work item to the closed state of the profile, Removed by default, which keeps
+// its history and comments. States differ between azure boards processes, so the state can be
+// configured with closedState, i.e. Closed for the Agile process.
+func (azure *azureManager) Close(issueNum int) error {
+	paths := []string{
+		azure.repo.UserName,
+		azure.repo.Project,
+		"_apis",
+		"wit",
+		"workitems",
+		strconv.Itoa(issueNum),
+	}
+
+	params := map[string]string{"api-version": azureApiVersion}
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal([]azurePatchOperation{
+		{Op: "add", Path: "/fields/System.State", Value: azure.closedState},
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := azure.headers.Clone()
+	headers.Set("Content-Type", "application/json-patch+json")
+
+	resp, data, err := azure.opts.request("PATCH", u, bytes.NewBuffer(data), headers)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errRes := onGetIssueError(data)
+		return fmt.Errorf(
+			"failed to move work item #%d to the %s state: %s with status code: %d",
+			issueNum,
+			azure.closedState,
+			errRes.Message,
+			resp.StatusCode,
+		)
+	}
+
+	return nil
+}
+
+// azure devops limits the number of work items that can be requested at once
+const azureBatchSize = 200
+
+type azureWiqlRequest struct {
+	Query string `json:"query"`
+}
+
+type azureWiqlResponse struct {
+	WorkItems []struct {
+		ID int `json:"id"`
+	} `json:"workItems"`
+}
+
+type azureWorkItemsResponse struct {
+	Value []azureWorkItemResponse `json:"value"`
+}
+
+// ListOpen queries the work items of the project that are not in a resolved state and
+// requests their titles and descriptions in batches
+func (azure *azureManager) ListOpen() ([]RemoteIssue, error) {
+	states := make([]string, len(azureResolvedStates))
+	for i, state := range azureResolvedStates {
+		states[i] = "'" + state + "'"
+	}
+
+	query := fmt.Sprintf(
+		"SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [System.State] NOT IN (%s)",
+		strings.Join(states, ", "),
+	)
+
+	paths := []string{azure.repo.UserName, azure.repo.Project, "_apis", "wit", "wiql"}
+	params := map[string]string{"api-version": azureApiVersion}
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(azureWiqlRequest{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	headers := azure.headers.Clone()
+	headers.Set("Content-Type", "application/json")
+
+	resp, data, err := azure.opts.request("POST", u, bytes.NewBuffer(data), headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errRes := onGetIssueError(data)
+		return nil, fmt.Errorf("failed to query open work items: %s with status code: %d", errRes.Message, resp.StatusCode)
+	}
+
+	wiql := azureWiqlResponse{}
+	if err := json.Unmarshal(data, &wiql); err != nil {
+		return nil, err
+	}
+
+	open := make([]RemoteIssue, 0, len(wiql.WorkItems))
+	for start := 0; start < len(wiql.WorkItems); start += azureBatchSize {
+		batch := wiql.WorkItems[start:min(start+azureBatchSize, len(wiql.WorkItems))]
+		ids := make([]string, len(batch))
+		for i, item := range batch {
+			ids[i] = strconv.Itoa(item.ID)
+		}
+
+		workItems, err := azure.workItems(ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range workItems {
+			title, _ := item.Fields["System.Title"].(string)
+			body, _ := item.Fields["System.Description"].(string)
+			open = append(open, RemoteIssue{Number: item.ID, Title: title, Body: body})
+		}
+	}
+
+	return open, nil
+}
+
+func (azure *azureManager) workItems(ids []string) ([]azureWorkItemResponse, error) {
+	paths := []string{azure.repo.UserName, azure.repo.Project, "_apis", "wit", "workitems"}
+	params := map[string]string{
+		"api-version": azureApiVersion,
+		"ids":         strings.Join(ids, ","),
+		"fields":      "System.Title,System.Description",
+	}
+
+	u, err := common.ConstructURL(azure.baseUrl, params, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, data, err := azure.opts.request("GET", u, nil, azure.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errRes := onGetIssueError(data)
+		return nil, fmt.Errorf("failed to request work items: %s with status code: %d", errRes.Message, resp.StatusCode)
+	}
+
+	res := azureWorkItemsResponse{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Value, nil
+}
@@ -0,0 +1,208 @@
+package git_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+const azureProjectPath = "/acme/payments/_apis"
+
+// newAzureTestManager creates an azure devops manager that sends its requests to the handler. Requests
+// without the "pat" token are answered with a sign in page, as azure devops does.
+func newAzureTestManager(t *testing.T, token string, handler http.HandlerFunc) git.GitManager {
+	setTestConfigDir(t)
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", token)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(":pat"))
+		if r.Header.Get("Authorization") != expected {
+			w.WriteHeader(http.StatusNonAuthoritativeInfo)
+			fmt.Fprint(w, "<html>Sign in to your account</html>")
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://dev.azure.com/acme/payments/_git/api"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Azure, repo, git.ManagerOptions{ApiBaseUrl: srv.URL})
+	require.NoError(t, err)
+	return manager
+}
+
+func TestAzureReport(t *testing.T) {
+	var operations []map[string]string
+	manager := newAzureTestManager(t, "pat", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, azureProjectPath+"/wit/workitems/$Task", r.URL.Path)
+		require.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&operations))
+		fmt.Fprint(w, `{"id": 42, "fields": {"System.State": "New"}}`)
+	})
+
+	res := make(chan git.ReportResponse, 1)
+	manager.Report(git.ReportRequest{
+		Title:     "azure issue",
+		Body:      "### Description\n\n- `main.go`",
+		Labels:    []string{"ci", "payments"},
+		Assignees: []string{"jane@example.com", "john@example.com"},
+		Index:     2,
+	}, res)
+
+	reported := <-res
+	require.NoError(t, reported.Err)
+	require.Equal(t, 42, reported.ID)
+	require.Equal(t, 2, reported.Index)
+	require.Equal(t, []map[string]string{
+		{"op": "add", "path": "/fields/System.Title", "value": "azure issue"},
+		{"op": "add", "path": "/fields/System.Description", "value": "<h3>Description</h3><ul><li><code>main.go</code></li></ul>"},
+		{"op": "add", "path": "/fields/System.Tags", "value": "ci; payments"},
+		{"op": "add", "path": "/fields/System.AssignedTo", "value": "jane@example.com"},
+	}, operations, "work items have a single assignee")
+}
+
+func TestAzureValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		token   string
+		status  int
+		authErr bool
+		err     bool
+	}{
+		{name: "Should validate tokens that can read the project", token: "pat", status: http.StatusOK},
+		{name: "Should reject tokens that are answered with a sign in page", token: "revoked", authErr: true},
+		{name: "Should reject unauthorized tokens", token: "pat", status: http.StatusUnauthorized, authErr: true},
+		{name: "Should reject missing tokens", authErr: true},
+		{name: "Should return other errors", token: "pat", status: http.StatusNotFound, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := newAzureTestManager(t, tc.token, func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/acme/_apis/projects/payments", r.URL.Path)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, `{"message": "project not found"}`)
+			})
+
+			_, err := manager.Validate()
+			switch {
+			case tc.authErr:
+				authErr := &git.AuthError{}
+				require.ErrorAs(t, err, &authErr)
+			case tc.err:
+				require.ErrorContains(t, err, "status code: 404")
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAzureGetStatus(t *testing.T) {
+	states := []string{"New", "Active", "Resolved", "Closed", "Done", "Removed", "To Do"}
+	manager := newAzureTestManager(t, "pat", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, azureProjectPath+"/wit/workitems/"))
+		require.NoError(t, err)
+		require.Equal(t, "System.State", r.URL.Query().Get("fields"))
+
+		if id >= len(states) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "work item does not exist"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %d, "fields": {"System.State": %q}}`, id, states[id])
+	})
+
+	statuses := make(chan git.StatusResponse, len(states)+1)
+	for id := range len(states) + 1 {
+		manager.GetStatus(id, id, statuses)
+	}
+	close(statuses)
+
+	resolved := make(map[int]bool)
+	for s := range statuses {
+		if s.Index == len(states) {
+			require.ErrorContains(t, s.Err, "status code: 404")
+			continue
+		}
+		require.NoError(t, s.Err)
+		resolved[s.Index] = s.Resolved
+	}
+
+	require.Equal(t, map[int]bool{0: false, 1: false, 2: true, 3: true, 4: true, 5: true, 6: false}, resolved)
+}
+
+func TestAzureListOpen(t *testing.T) {
+	const count = 450
+
+	mu := sync.Mutex{}
+	batches := make([]int, 0)
+	manager := newAzureTestManager(t, "pat", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == azureProjectPath+"/wit/wiql":
+			query := map[string]string{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&query))
+			require.Contains(t, query["query"], "NOT IN ('closed', 'done', 'resolved', 'removed')")
+
+			items := make([]map[string]int, count)
+			for i := range items {
+				items[i] = map[string]int{"id": i + 1}
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"workItems": items}))
+		case r.Method == "GET" && r.URL.Path == azureProjectPath+"/wit/workitems":
+			ids := strings.Split(r.URL.Query().Get("ids"), ",")
+			mu.Lock()
+			batches = append(batches, len(ids))
+			mu.Unlock()
+
+			values := make([]map[string]any, len(ids))
+			for i, id := range ids {
+				number, err := strconv.Atoi(id)
+				require.NoError(t, err)
+				values[i] = map[string]any{"id": number, "fields": map[string]string{"System.Title": "work item " + id}}
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"value": values}))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	open, err := manager.ListOpen()
+	require.NoError(t, err)
+	require.Len(t, open, count)
+	require.Equal(t, git.RemoteIssue{Number: count, Title: fmt.Sprintf("work item %d", count)}, open[count-1])
+	require.Equal(t, []int{200, 200, 50}, batches, "work items are requested in batches of 200")
+}
+
+func TestAzureClose(t *testing.T) {
+	var operations []map[string]string
+	manager := newAzureTestManager(t, "pat", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "PATCH", r.Method, "work items are closed rather than deleted")
+		require.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+
+		if r.URL.Path != azureProjectPath+"/wit/workitems/7" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "work item does not exist"}`)
+			return
+		}
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&operations))
+		fmt.Fprint(w, `{"id": 7, "fields": {"System.State": "Removed"}}`)
+	})
+
+	require.NoError(t, manager.Close(7))
+	require.Equal(t, []map[string]string{{"op": "add", "path": "/fields/System.State", "value": "Removed"}}, operations)
+	require.ErrorContains(t, manager.Close(8), "status code: 404")
+}
@@ -0,0 +1,411 @@
+package git
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NotCommittedYet is the author of lines that differ from the HEAD commit, as reported by <git blame>
+const NotCommittedYet = "Not Committed Yet"
+
+// BlameLine is the commit that last changed a line of a file
+type BlameLine struct {
+	Commit      string // name of the commit, which consists of zeros when the line was not committed
+	Author      string
+	AuthorEmail string
+	Time        time.Time // author time of the commit
+}
+
+// Committed reports whether the line was changed by a commit, rather than in the work tree
+func (line BlameLine) Committed() bool {
+	return line.Commit != "" && line.Commit != Hash{}.String()
+}
+
+// LineTimes returns the time that each line of the file was last changed, see [Repository.Blame].
+// [path] is relative to the work tree and the first element is the first line of the file. Lines
+// that have not been committed yet are reported at the current time.
+func (repo *Repository) LineTimes(ctx context.Context, path string) ([]time.Time, error) {
+	lines, err := repo.Blame(ctx, "", path)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, len(lines))
+	for i, line := range lines {
+		times[i] = line.Time
+	}
+	return times, nil
+}
+
+// Blame returns the commit that last changed each line of a file. The file of the work tree is
+// blamed when [rev] is empty, otherwise the file of the [rev] commit, see [Repository.BlameLines].
+func (repo *Repository) Blame(ctx context.Context, rev, path string) ([]BlameLine, error) {
+	return repo.BlameLines(ctx, rev, path, nil)
+}
+
+// BlameLines returns the commit that last changed each of the [lines] of a file, starting at 1, or
+// every line of the file when [lines] is nil. Only the history of the requested lines is traversed,
+// from [rev] towards the root commits, by diffing the file with each parent of a commit. A line
+// is blamed on the commit whose parents do not contain it, renamed files are not followed.
+//
+// The file of the work tree is blamed when [rev] is empty. Lines that differ from the HEAD commit,
+// or every line when the file is untracked, are reported as [NotCommittedYet] at the current time.
+// The result contains an element per requested line, lines past the end of the file are zero.
+func (repo *Repository) BlameLines(ctx context.Context, rev, name string, lines []int) ([]BlameLine, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	b := &blamer{
+		repo:    repo,
+		path:    name,
+		commits: make(map[Hash]*Commit),
+		trees:   make(map[Hash][]TreeEntry),
+		blobs:   make(map[Hash][][]byte),
+		pending: make(map[Hash]*blameEntry),
+	}
+
+	var err error
+	if b.shallow, err = repo.shallowCommits(); err != nil {
+		return nil, err
+	}
+
+	var content [][]byte
+	var start blameEntry
+	var matches []int // line of the file in HEAD that matches each line of the work tree
+	if rev == "" {
+		content, start, matches, err = b.workTree()
+	} else {
+		content, start, err = b.commit(rev)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lines == nil {
+		lines = make([]int, len(content))
+		for i := range lines {
+			lines[i] = i + 1
+		}
+	}
+
+	uncommitted := BlameLine{Commit: Hash{}.String(), Author: NotCommittedYet, Time: time.Now()}
+	b.result = make([]BlameLine, len(lines))
+	start.lines = make(map[int][]int)
+	for i, line := range lines {
+		if line < 1 || line > len(content) {
+			continue
+		}
+
+		n := line - 1
+		if rev == "" {
+			if start.commit == nil || matches[n] < 0 {
+				b.result[i] = uncommitted
+				continue
+			}
+			n = matches[n]
+		}
+		start.lines[n] = append(start.lines[n], i)
+	}
+
+	if err := b.run(ctx, start); err != nil {
+		return nil, err
+	}
+	return b.result, nil
+}
+
+// blameEntry is a commit that the blame of some lines was passed to
+type blameEntry struct {
+	commit *Commit
+	blob   Hash          // contents of the file in [commit]
+	lines  map[int][]int // line of the file in [commit], starting at 0, to the indices of the result
+}
+
+type blamer struct {
+	repo    *Repository
+	path    string
+	shallow map[Hash]bool
+	commits map[Hash]*Commit
+	trees   map[Hash][]TreeEntry
+	blobs   map[Hash][][]byte // lines of each blob, including their line endings
+	pending map[Hash]*blameEntry
+	queue   blameQueue
+	result  []BlameLine
+}
+
+// commit starts the blame at the file of the [rev] commit
+func (b *blamer) commit(rev string) ([][]byte, blameEntry, error) {
+	hash, err := b.repo.ResolveRevision(rev)
+	if err != nil {
+		return nil, blameEntry{}, err
+	}
+
+	commit, err := b.readCommit(hash)
+	if err != nil {
+		return nil, blameEntry{}, err
+	}
+
+	blob, ok, err := b.lookup(commit)
+	if err != nil {
+		return nil, blameEntry{}, err
+	} else if !ok {
+		return nil, blameEntry{}, fmt.Errorf("%w: %s does not exist in %s", ErrObjectNotFound, b.path, rev)
+	}
+
+	content, err := b.readBlob(blob)
+	return content, blameEntry{commit: commit, blob: blob}, err
+}
+
+// workTree starts the blame at the file of the work tree, whose lines are matched to the lines of
+// the file in the HEAD commit. The entry is empty when HEAD is unborn or does not contain the file.
+func (b *blamer) workTree() ([][]byte, blameEntry, []int, error) {
+	data, err := os.ReadFile(filepath.Join(b.repo.WorkTree, filepath.FromSlash(b.path)))
+	if err != nil {
+		return nil, blameEntry{}, nil, err
+	}
+	content := splitLines(data)
+
+	packed, err := b.repo.readPackedRefs()
+	if err != nil {
+		return nil, blameEntry{}, nil, err
+	}
+
+	head, ok, err := b.repo.readRef("HEAD", packed, 0)
+	if err != nil || !ok {
+		return content, blameEntry{}, nil, err
+	}
+
+	commit, err := b.readCommit(head)
+	if err != nil {
+		return nil, blameEntry{}, nil, err
+	}
+
+	blob, ok, err := b.lookup(commit)
+	if err != nil || !ok {
+		return content, blameEntry{}, nil, err
+	}
+
+	committed, err := b.readBlob(blob)
+	if err != nil {
+		return nil, blameEntry{}, nil, err
+	}
+
+	return content, blameEntry{commit: commit, blob: blob}, matchLines(committed, content), nil
+}
+
+// run passes the blame of the requested lines from [start] to the parents of each commit, newest
+// first, until every line is blamed on a commit
+func (b *blamer) run(ctx context.Context, start blameEntry) error {
+	if len(start.lines) > 0 {
+		b.push(start.commit, start.blob, start.lines)
+	}
+
+	for b.queue.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry := heap.Pop(&b.queue).(*blameEntry)
+		delete(b.pending, entry.commit.Hash)
+
+		if err := b.pass(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pass moves the blame of the lines of [entry] that were not changed by its commit to the parents.
+// All lines are passed to a parent that contains the same file. The lines that remain were changed
+// by the commit.
+func (b *blamer) pass(entry *blameEntry) error {
+	remaining := entry.lines
+	if b.shallow[entry.commit.Hash] {
+		b.blame(entry.line(), remaining)
+		return nil
+	}
+
+	for _, hash := range entry.commit.Parents {
+		if len(remaining) == 0 {
+			break
+		}
+
+		parent, err := b.readCommit(hash)
+		if err != nil {
+			return err
+		}
+
+		blob, ok, err := b.lookup(parent)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		if blob == entry.blob {
+			b.push(parent, blob, remaining)
+			remaining = nil
+			break
+		}
+
+		before, err := b.readBlob(blob)
+		if err != nil {
+			return err
+		}
+
+		after, err := b.readBlob(entry.blob)
+		if err != nil {
+			return err
+		}
+
+		matches := matchLines(before, after)
+		passed := make(map[int][]int)
+		for line, indices := range remaining {
+			if match := matches[line]; match >= 0 {
+				passed[match] = append(passed[match], indices...)
+				delete(remaining, line)
+			}
+		}
+
+		if len(passed) > 0 {
+			b.push(parent, blob, passed)
+		}
+	}
+
+	b.blame(entry.line(), remaining)
+	return nil
+}
+
+// push queues the lines that were passed to a commit, the lines that are passed to a commit by
+// multiple children, such as both sides of a merge, are blamed together
+func (b *blamer) push(commit *Commit, blob Hash, lines map[int][]int) {
+	if entry, ok := b.pending[commit.Hash]; ok {
+		for line, indices := range lines {
+			entry.lines[line] = append(entry.lines[line], indices...)
+		}
+		return
+	}
+
+	entry := &blameEntry{commit: commit, blob: blob, lines: lines}
+	b.pending[commit.Hash] = entry
+	heap.Push(&b.queue, entry)
+}
+
+func (b *blamer) blame(line BlameLine, lines map[int][]int) {
+	for _, indices := range lines {
+		for _, i := range indices {
+			b.result[i] = line
+		}
+	}
+}
+
+func (entry *blameEntry) line() BlameLine {
+	return BlameLine{
+		Commit:      entry.commit.Hash.String(),
+		Author:      entry.commit.Author.Name,
+		AuthorEmail: entry.commit.Author.Email,
+		Time:        entry.commit.Author.When,
+	}
+}
+
+func (b *blamer) readCommit(hash Hash) (*Commit, error) {
+	if commit, ok := b.commits[hash]; ok {
+		return commit, nil
+	}
+
+	commit, err := b.repo.ReadCommit(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	b.commits[hash] = &commit
+	return &commit, nil
+}
+
+func (b *blamer) readBlob(hash Hash) ([][]byte, error) {
+	if lines, ok := b.blobs[hash]; ok {
+		return lines, nil
+	}
+
+	data, err := b.repo.ReadBlob(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	b.blobs[hash] = splitLines(data)
+	return b.blobs[hash], nil
+}
+
+// lookup returns the blob of the file in a commit. False is returned when the commit does not contain
+// the file, or when the path is not a regular file. Trees are shared by most commits, so they are
+// read once.
+func (b *blamer) lookup(commit *Commit) (Hash, bool, error) {
+	tree := commit.Tree
+	parts := strings.Split(b.path, "/")
+	for i, part := range parts {
+		entries, ok := b.trees[tree]
+		if !ok {
+			var err error
+			if entries, err = b.repo.readTreeObject(tree); err != nil {
+				return Hash{}, false, err
+			}
+			b.trees[tree] = entries
+		}
+
+		found := false
+		for _, entry := range entries {
+			if entry.Path != part {
+				continue
+			}
+
+			if i == len(parts)-1 && entry.Regular() {
+				return entry.Hash, true, nil
+			} else if i < len(parts)-1 && entry.Mode == ModeDir {
+				tree, found = entry.Hash, true
+			}
+			break
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	return Hash{}, false, nil
+}
+
+// splitLines splits a file into lines, which keep their line endings
+func splitLines(data []byte) [][]byte {
+	lines := bytes.SplitAfter(data, []byte{'\n'})
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// blameQueue orders the commits that lines were passed to by their commit time, newest first, which
+// visits the children of a commit before the commit itself, unless the clocks of their committers
+// were skewed
+type blameQueue []*blameEntry
+
+func (q blameQueue) Len() int { return len(q) }
+
+func (q blameQueue) Less(i, j int) bool {
+	return q[i].commit.Committer.When.After(q[j].commit.Committer.When)
+}
+
+func (q blameQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *blameQueue) Push(x any) { *q = append(*q, x.(*blameEntry)) }
+
+func (q *blameQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,148 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryLineTimes(t *testing.T) {
+	dir := newGitRepository(t)
+	t.Setenv("GIT_AUTHOR_DATE", "2024-01-02T00:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-02T00:00:00Z")
+
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644))
+	runGit(t, dir, "add", "main.go")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// uncommitted\nfunc main() {}\n"), 0644))
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	times, err := repo.LineTimes(context.Background(), "main.go")
+	require.NoError(t, err)
+	require.Len(t, times, 4)
+
+	committed := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	require.True(t, times[0].Equal(committed))
+	require.True(t, times[3].Equal(committed))
+	require.True(t, times[2].After(committed))
+
+	_, err = repo.LineTimes(context.Background(), "missing.go")
+	require.Error(t, err)
+}
+
+func TestRepositoryBlame(t *testing.T) {
+	dir := newGitRepository(t)
+	path := filepath.Join(dir, "main.go")
+
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @TODO first\n"), 0644))
+	runGit(t, dir, "add", "main.go")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	initial := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @TODO first\n// @TODO second\n"), 0644))
+	runGit(t, dir, "-c", "user.name=other", "-c", "user.email=other@example.com", "commit", "-q", "-a", "-m", "second",
+		"--author", "Jane Doe <jane@example.com>")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	lines, err := repo.Blame(context.Background(), "", "main.go")
+	require.NoError(t, err)
+	require.Len(t, lines, 4)
+	require.Equal(t, initial, lines[2].Commit)
+	require.Equal(t, "test", lines[2].Author)
+	require.Equal(t, "test@example.com", lines[2].AuthorEmail)
+	require.Equal(t, "Jane Doe", lines[3].Author)
+	require.Equal(t, "jane@example.com", lines[3].AuthorEmail)
+
+	// the file of an older commit is blamed, rather than the file of the work tree
+	lines, err = repo.Blame(context.Background(), initial, "main.go")
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+
+	_, err = repo.Blame(context.Background(), "--all", "main.go")
+	require.Error(t, err)
+}
+
+func TestRepositoryBlameLines(t *testing.T) {
+	dir := newGitRepository(t)
+	path := filepath.Join(dir, "main.go")
+
+	commit := func(contents, author string) string {
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+		runGit(t, dir, "add", "main.go")
+		runGit(t, dir, "commit", "-q", "-m", author, "--author", author+" <"+author+"@example.com>")
+		return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	}
+
+	runGit(t, dir, "checkout", "-q", "-b", "main")
+	funcs := "\nfunc a() {}\n\nfunc b() {}\n\nfunc c() {}\n"
+	initial := commit("package main\n\n// @TODO first\nfunc main() {}\n"+funcs, "jane")
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	feature := commit("package main\n\n// @TODO first\nfunc main() {}\n"+funcs+"\n// @TODO feature\n", "john")
+	runGit(t, dir, "checkout", "-q", "main")
+	changed := commit("package main\n\n// @TODO first, changed\nfunc main() {}\n"+funcs, "jane")
+	runGit(t, dir, "merge", "-q", "--no-ff", "-m", "merge", "feature")
+	merged := string(mustRead(t, path))
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	// every line is compared with <git blame>, the lines of both sides of the merge are traversed
+	lines, err := repo.Blame(context.Background(), "HEAD", "main.go")
+	require.NoError(t, err)
+	require.Len(t, lines, strings.Count(merged, "\n"))
+
+	expected := make([]string, 0)
+	for _, line := range strings.Split(runGit(t, dir, "blame", "--porcelain", "HEAD", "--", "main.go"), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) == 40 {
+			expected = append(expected, fields[0])
+		}
+	}
+
+	actual := make([]string, 0)
+	for _, line := range lines {
+		actual = append(actual, line.Commit)
+	}
+	require.Equal(t, expected, actual)
+	require.Contains(t, actual, initial)
+	require.Contains(t, actual, feature)
+	require.Contains(t, actual, changed)
+
+	// only the requested lines are blamed, lines past the end of the file are zero
+	line := strings.Count(merged[:strings.Index(merged, "// @TODO feature")], "\n") + 1
+	subset, err := repo.BlameLines(context.Background(), "HEAD", "main.go", []int{line, 100})
+	require.NoError(t, err)
+	require.Len(t, subset, 2)
+	require.Equal(t, lines[line-1], subset[0])
+	require.Equal(t, "john", subset[0].Author)
+	require.Equal(t, "john@example.com", subset[0].AuthorEmail)
+	require.True(t, subset[0].Committed())
+	require.Equal(t, git.BlameLine{}, subset[1])
+
+	// untracked files have not been committed yet
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0644))
+	untracked, err := repo.BlameLines(context.Background(), "", "new.go", []int{1})
+	require.NoError(t, err)
+	require.Equal(t, git.NotCommittedYet, untracked[0].Author)
+	require.False(t, untracked[0].Committed())
+
+	_, err = repo.BlameLines(context.Background(), "HEAD", "new.go", []int{1})
+	require.ErrorIs(t, err, git.ErrObjectNotFound)
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return data
+}
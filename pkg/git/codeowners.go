@@ -0,0 +1,162 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// CodeOwnersPaths are the locations of the CODEOWNERS file, relative to the work tree, in the order
+// that they are searched. The first file that exists is used.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// OwnerRule is a line of a CODEOWNERS file: a pattern, followed by the owners of the matching paths
+type OwnerRule struct {
+	Pattern string
+	Owners  []string // @user, @org/team or email. Empty when the rule removes the owners of the paths
+	Line    int
+}
+
+// CodeOwners are the rules of a CODEOWNERS file
+type CodeOwners struct {
+	Path  string // relative to the work tree, empty when the repository does not have a CODEOWNERS file
+	Rules []OwnerRule
+}
+
+// ReadCodeOwners reads the first CODEOWNERS file of [CodeOwnersPaths]. The zero value is returned
+// when none of them exist. The valid rules are returned along with the error when some of the lines
+// of the file are invalid, see [ParseCodeOwners].
+func (repo *Repository) ReadCodeOwners() (CodeOwners, error) {
+	for _, path := range CodeOwnersPaths {
+		file, err := os.Open(filepath.Join(repo.WorkTree, filepath.FromSlash(path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return CodeOwners{}, err
+		}
+		defer file.Close()
+
+		rules, err := ParseCodeOwners(file)
+		if rules == nil {
+			return CodeOwners{}, err
+		} else if err != nil {
+			return CodeOwners{Path: path, Rules: rules}, fmt.Errorf("invalid %s: %w", path, err)
+		}
+		return CodeOwners{Path: path, Rules: rules}, nil
+	}
+
+	return CodeOwners{}, nil
+}
+
+// ParseCodeOwners parses the rules of a CODEOWNERS file. Blank lines, comments and the section
+// headers of GitLab, i.e. [Docs] or ^[Optional], are skipped. Spaces in patterns are escaped with
+// a backslash, as is a # at the start of a pattern. Invalid lines do not stop the parsing, the valid
+// rules are returned along with the errors of the invalid lines. Nil rules are returned when the
+// reader fails.
+func ParseCodeOwners(r io.Reader) ([]OwnerRule, error) {
+	rules := make([]OwnerRule, 0)
+	errs := make([]error, 0)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+
+		fields := splitOwnerFields(line)
+		rule := OwnerRule{Pattern: fields[0], Owners: make([]string, 0, len(fields)-1), Line: n}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+
+		if strings.HasPrefix(rule.Pattern, "!") || !doublestar.ValidatePattern(strings.Trim(rule.Pattern, "/")) {
+			errs = append(errs, fmt.Errorf("line %d: unsupported pattern %q", n, rule.Pattern))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, errors.Join(errs...)
+}
+
+// splitOwnerFields splits a rule at whitespace that is not escaped with a backslash
+func splitOwnerFields(line string) []string {
+	fields := make([]string, 0)
+	field := strings.Builder{}
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && (line[i+1] == ' ' || line[i+1] == '\t' || line[i+1] == '#'):
+			field.WriteByte(line[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteByte(c)
+		}
+	}
+
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// Owners returns the owners of a file, relative to the work tree, according to the last rule that
+// matches it. False is returned when no rule matches the file.
+func (owners CodeOwners) Owners(path string) ([]string, bool) {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for i := len(owners.Rules) - 1; i >= 0; i-- {
+		if matchOwnerPattern(owners.Rules[i].Pattern, path) {
+			return owners.Rules[i].Owners, true
+		}
+	}
+	return nil, false
+}
+
+// matchOwnerPattern matches a path with the gitignore style pattern of a rule. Patterns that start
+// with, or contain, a slash are relative to the root of the work tree, other patterns match at any
+// depth. Patterns match the files within matching directories, except for patterns that end with /*,
+// which only match the files that are directly within the directory.
+func matchOwnerPattern(pattern, path string) bool {
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if pattern == "" || pattern == "**" {
+		return true
+	}
+
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+
+	if !dir {
+		if match, _ := doublestar.Match(pattern, path); match {
+			return true
+		}
+	}
+
+	if strings.HasSuffix(pattern, "/*") {
+		return false
+	}
+
+	match, _ := doublestar.Match(pattern+"/**", path)
+	return match
+}
@@ -0,0 +1,136 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []git.OwnerRule
+		err      bool
+	}{
+		{
+			name:     "Should parse patterns and owners",
+			content:  "*.go @octocat @acme/backend jane@example.com\n",
+			expected: []git.OwnerRule{{Pattern: "*.go", Owners: []string{"@octocat", "@acme/backend", "jane@example.com"}, Line: 1}},
+		},
+		{
+			name:     "Should skip blank lines, comments and sections",
+			content:  "# owners\n\n[Docs]\n^[Optional] @octocat\n/docs/ @writers # inline comment\n",
+			expected: []git.OwnerRule{{Pattern: "/docs/", Owners: []string{"@writers"}, Line: 5}},
+		},
+		{
+			name:     "Should keep rules without owners",
+			content:  "/vendor/\n",
+			expected: []git.OwnerRule{{Pattern: "/vendor/", Owners: []string{}, Line: 1}},
+		},
+		{
+			name:     "Should unescape spaces in patterns",
+			content:  "my\\ file.go @octocat\n",
+			expected: []git.OwnerRule{{Pattern: "my file.go", Owners: []string{"@octocat"}, Line: 1}},
+		},
+		{
+			name:     "Should reject negated patterns and keep the valid rules",
+			content:  "!*.go @octocat\n*.md @writers\n",
+			expected: []git.OwnerRule{{Pattern: "*.md", Owners: []string{"@writers"}, Line: 2}},
+			err:      true,
+		},
+		{
+			name:     "Should reject invalid patterns and keep the valid rules",
+			content:  "*.md @writers\nsrc/[*.go @octocat\n",
+			expected: []git.OwnerRule{{Pattern: "*.md", Owners: []string{"@writers"}, Line: 1}},
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := git.ParseCodeOwners(strings.NewReader(tc.content))
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, rules)
+		})
+	}
+}
+
+func TestCodeOwnersOwners(t *testing.T) {
+	rules, err := git.ParseCodeOwners(strings.NewReader(strings.Join([]string{
+		"*                @default",
+		"*.js             @frontend",
+		"/build/logs/     @ops",
+		"docs/*           @writers",
+		"apps/            @apps",
+		"/pkg/git/*.go    @git",
+		"/pkg/git/vendor/",
+	}, "\n")))
+	require.NoError(t, err)
+	owners := git.CodeOwners{Rules: rules}
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected []string
+		found    bool
+	}{
+		{name: "Should match every file with *", path: "main.go", expected: []string{"@default"}, found: true},
+		{name: "Should match extensions at any depth", path: "web/src/app.js", expected: []string{"@frontend"}, found: true},
+		{name: "Should match the contents of anchored directories", path: "build/logs/2024/out.log", expected: []string{"@ops"}, found: true},
+		{name: "Should not match anchored directories elsewhere", path: "src/build/logs/out.log", expected: []string{"@default"}, found: true},
+		{name: "Should match the files directly within a directory with /*", path: "docs/README.md", expected: []string{"@writers"}, found: true},
+		{name: "Should not match nested files with /*", path: "docs/api/README.md", expected: []string{"@default"}, found: true},
+		{name: "Should match unanchored directories at any depth", path: "services/apps/api/main.go", expected: []string{"@apps"}, found: true},
+		{name: "Should prefer the last matching rule", path: "pkg/git/git.go", expected: []string{"@git"}, found: true},
+		{name: "Should remove the owners with rules without owners", path: "pkg/git/vendor/lib.go", expected: []string{}, found: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, found := owners.Owners(filepath.FromSlash(tc.path))
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, result)
+		})
+	}
+
+	_, found := git.CodeOwners{}.Owners("main.go")
+	require.False(t, found, "files are not owned without rules")
+}
+
+func TestRepositoryReadCodeOwners(t *testing.T) {
+	dir := newGitRepository(t)
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	owners, err := repo.ReadCodeOwners()
+	require.NoError(t, err)
+	require.Empty(t, owners.Path, "the repository does not have a CODEOWNERS file")
+
+	write := func(path, content string) {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("docs/CODEOWNERS", "* @docs\n")
+	write("CODEOWNERS", "* @root\n")
+	owners, err = repo.ReadCodeOwners()
+	require.NoError(t, err)
+	require.Equal(t, "CODEOWNERS", owners.Path, "the root is searched before docs")
+	require.Equal(t, []string{"@root"}, owners.Rules[0].Owners)
+
+	write(".github/CODEOWNERS", "!* @github\n* @github\n")
+	owners, err = repo.ReadCodeOwners()
+	require.ErrorContains(t, err, ".github/CODEOWNERS", ".github is searched first")
+	require.Equal(t, ".github/CODEOWNERS", owners.Path)
+	require.Equal(t, []string{"@github"}, owners.Rules[0].Owners, "the valid rules are kept")
+}
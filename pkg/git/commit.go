@@ -0,0 +1,250 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature is the author or committer of a commit
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// Commit is a parsed commit object
+type Commit struct {
+	Hash      Hash
+	Tree      Hash
+	Parents   []Hash // the first parent is the branch that was merged into
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// ReadCommit reads and parses a commit from the object database
+func (repo *Repository) ReadCommit(hash Hash) (Commit, error) {
+	kind, data, err := repo.ReadObject(hash)
+	if err != nil {
+		return Commit{}, err
+	}
+
+	if kind != ObjectCommit {
+		return Commit{}, fmt.Errorf("object %s is a %s, not a %s", hash, kind, ObjectCommit)
+	}
+
+	commit, err := parseCommit(data)
+	if err != nil {
+		return Commit{}, fmt.Errorf("invalid commit %s: %w", hash, err)
+	}
+
+	commit.Hash = hash
+	return commit, nil
+}
+
+// FirstParents returns the commits of the first parent history of [start], the history of the
+// branch without the commits of the branches that were merged into it, beginning with [start].
+// At most [limit] commits are returned, all of them when limit is 0. The history of shallow
+// clones ends at the shallow boundary.
+func (repo *Repository) FirstParents(start Hash, limit int) ([]Commit, error) {
+	shallow, err := repo.shallowCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]Commit, 0)
+	seen := make(map[Hash]bool)
+	for hash := start; limit == 0 || len(commits) < limit; {
+		if seen[hash] {
+			return nil, fmt.Errorf("commit %s is its own ancestor", hash)
+		}
+		seen[hash] = true
+
+		commit, err := repo.ReadCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+
+		if len(commit.Parents) == 0 || shallow[hash] {
+			break
+		}
+		hash = commit.Parents[0]
+	}
+
+	return commits, nil
+}
+
+// Tags returns the short names of the tags of each commit. Annotated tags are peeled and tags of
+// other objects, such as trees, are skipped.
+func (repo *Repository) Tags() (map[Hash][]string, error) {
+	refs, err := repo.readPackedRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(repo.Dir, "refs", "tags")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(repo.Dir, path)
+		if err != nil {
+			return err
+		}
+
+		ref := filepath.ToSlash(rel)
+		hash, ok, err := repo.readRef(ref, nil, 0)
+		if err != nil {
+			return err
+		} else if ok {
+			refs[ref] = hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[Hash][]string)
+	for ref, hash := range refs {
+		name, ok := strings.CutPrefix(ref, "refs/tags/")
+		if !ok {
+			continue
+		}
+
+		commit, err := repo.peelCommit(name, hash)
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, err
+		} else if err != nil {
+			continue
+		}
+		tags[commit] = append(tags[commit], name)
+	}
+
+	for _, names := range tags {
+		sort.Strings(names)
+	}
+	return tags, nil
+}
+
+// shallowCommits reads the commits whose parents were not fetched by a shallow clone
+func (repo *Repository) shallowCommits() (map[Hash]bool, error) {
+	file, err := os.Open(filepath.Join(repo.Dir, "shallow"))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[Hash]bool{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	shallow := make(map[Hash]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, err := hex.DecodeString(strings.TrimSpace(scanner.Text()))
+		if err != nil || len(name) != len(Hash{}) {
+			return nil, fmt.Errorf("invalid shallow commit %q", scanner.Text())
+		}
+		shallow[Hash(name)] = true
+	}
+
+	return shallow, scanner.Err()
+}
+
+// parseCommit parses the headers of a commit, which are followed by an empty line and the message
+func parseCommit(data []byte) (Commit, error) {
+	commit := Commit{}
+	headers, message, _ := bytes.Cut(data, []byte("\n\n"))
+	commit.Message = string(message)
+
+	hasTree := false
+	for _, line := range bytes.Split(headers, []byte{'\n'}) {
+		key, value, _ := bytes.Cut(line, []byte{' '})
+
+		var err error
+		switch string(key) {
+		case "tree":
+			commit.Tree, err = parseHash(value)
+			hasTree = true
+		case "parent":
+			var parent Hash
+			parent, err = parseHash(value)
+			commit.Parents = append(commit.Parents, parent)
+		case "author":
+			commit.Author, err = parseSignature(value)
+		case "committer":
+			commit.Committer, err = parseSignature(value)
+		}
+
+		if err != nil {
+			return commit, fmt.Errorf("invalid %s header: %w", key, err)
+		}
+	}
+
+	if !hasTree {
+		return commit, errors.New("missing tree header")
+	}
+	return commit, nil
+}
+
+// parseSignature parses the author or committer of a commit: <name> <<email>> <unix time> <timezone>
+func parseSignature(value []byte) (Signature, error) {
+	open, end := bytes.IndexByte(value, '<'), bytes.LastIndexByte(value, '>')
+	if open < 0 || end < open {
+		return Signature{}, errors.New("missing email")
+	}
+
+	sig := Signature{
+		Name:  strings.TrimSpace(string(value[:open])),
+		Email: string(value[open+1 : end]),
+	}
+
+	fields := strings.Fields(string(value[end+1:]))
+	if len(fields) != 2 {
+		return sig, errors.New("missing date")
+	}
+
+	unix, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sig, fmt.Errorf("invalid date %q", fields[0])
+	}
+
+	// the timezone is an offset of hours and minutes, i.e. +0130
+	zone := fields[1]
+	offset, err := strconv.Atoi(zone)
+	if err != nil || len(zone) != 5 {
+		return sig, fmt.Errorf("invalid timezone %q", zone)
+	}
+	if offset < 0 {
+		offset = -offset
+	}
+
+	seconds := (offset/100*60 + offset%100) * 60
+	if zone[0] == '-' {
+		seconds = -seconds
+	}
+
+	sig.When = time.Unix(unix, 0).In(time.FixedZone(zone, seconds))
+	return sig, nil
+}
+
+func parseHash(value []byte) (Hash, error) {
+	name, err := hex.DecodeString(string(value))
+	if err != nil || len(name) != len(Hash{}) {
+		return Hash{}, fmt.Errorf("invalid object name %q", value)
+	}
+	return Hash(name), nil
+}
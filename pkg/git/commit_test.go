@@ -0,0 +1,90 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryFirstParents(t *testing.T) {
+	dir := newGitRepository(t)
+	t.Setenv("GIT_AUTHOR_DATE", "2024-01-02T10:00:00+0130")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-02T10:00:00+0130")
+
+	commit := func(name, message string) string {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(message+"\n"), 0644))
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-q", "-m", message)
+		return strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	}
+
+	runGit(t, dir, "checkout", "-q", "-b", "main")
+	first := commit("main.go", "first")
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	feature := commit("feature.go", "feature")
+	runGit(t, dir, "checkout", "-q", "main")
+	second := commit("main.go", "second")
+	runGit(t, dir, "merge", "-q", "--no-ff", "-m", "merge", "feature")
+	merge := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	head, err := repo.ResolveRevision("HEAD")
+	require.NoError(t, err)
+
+	commits, err := repo.FirstParents(head, 0)
+	require.NoError(t, err)
+
+	names := make([]string, 0)
+	for _, c := range commits {
+		names = append(names, c.Hash.String())
+	}
+	require.Equal(t, []string{merge, second, first}, names, "the merged commits are not part of the first parent history")
+	require.NotContains(t, names, feature)
+
+	require.Len(t, commits[0].Parents, 2)
+	require.Equal(t, "merge\n", commits[0].Message)
+	require.Equal(t, "test", commits[0].Author.Name)
+	require.Equal(t, "test@example.com", commits[0].Author.Email)
+
+	_, offset := commits[0].Committer.When.Zone()
+	require.Equal(t, 90*60, offset)
+	require.True(t, commits[0].Committer.When.Equal(time.Date(2024, 1, 2, 8, 30, 0, 0, time.UTC)))
+
+	limited, err := repo.FirstParents(head, 2)
+	require.NoError(t, err)
+	require.Len(t, limited, 2)
+}
+
+func TestRepositoryTags(t *testing.T) {
+	dir := newGitRepository(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	runGit(t, dir, "add", "main.go")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	head := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	tree := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD^{tree}"))
+
+	runGit(t, dir, "tag", "-a", "v1.0", "-m", "annotated")
+	runGit(t, dir, "tag", "release/v1.0")
+	runGit(t, dir, "tag", "tree", tree)
+	runGit(t, dir, "pack-refs", "--all")
+	runGit(t, dir, "tag", "loose")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	tags, err := repo.Tags()
+	require.NoError(t, err)
+	require.Len(t, tags, 1, "tags of trees are skipped")
+
+	for commit, names := range tags {
+		require.Equal(t, head, commit.String())
+		require.Equal(t, []string{"loose", "release/v1.0", "v1.0"}, names)
+	}
+}
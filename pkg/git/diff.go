@@ -0,0 +1,285 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader matches the line numbers of a hunk header, i.e. @@ -10,2 +12,3 @@
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// LineRange is an inclusive range of line numbers, starting at 1
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Changes are the lines that were added to each file, keyed by the path relative to the work tree
+type Changes map[string][]LineRange
+
+// Overlaps reports whether any line between [start] and [end], inclusive, was added to the file
+func (c Changes) Overlaps(path string, start, end int) bool {
+	if end < start {
+		end = start
+	}
+
+	for _, r := range c[filepath.ToSlash(path)] {
+		if r.Start <= end && start <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// AddedSince returns the lines that were added to the work tree since [ref], including changes that
+// have not been committed. Untracked files, that are not ignored, are added in their entirety.
+func (repo *Repository) AddedSince(ctx context.Context, ref string) (Changes, error) {
+	changes, err := repo.added(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := repo.git(ctx, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			changes[path] = []LineRange{{Start: 1, End: int(^uint(0) >> 1)}}
+		}
+	}
+
+	return changes, nil
+}
+
+// AddedIn returns the lines that were added by a revision range, such as main...feature, which
+// compares the head of the feature branch with the commit it was branched from
+func (repo *Repository) AddedIn(ctx context.Context, revisions string) (Changes, error) {
+	return repo.added(ctx, revisions)
+}
+
+// AddedStaged returns the lines that are added by the changes that are staged for the next commit,
+// compared to HEAD. Renamed files only add the lines that were changed and every line of a staged
+// file is added when HEAD does not exist yet.
+func (repo *Repository) AddedStaged(ctx context.Context) (Changes, error) {
+	out, err := repo.git(ctx, "diff", "--cached", "--find-renames", "--unified=0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", "--")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiff(out)
+}
+
+func (repo *Repository) added(ctx context.Context, revisions string) (Changes, error) {
+	if revisions == "" || strings.HasPrefix(revisions, "-") {
+		return nil, fmt.Errorf("invalid revision %q", revisions)
+	}
+
+	out, err := repo.git(ctx, "diff", "--unified=0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", revisions, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDiff(out)
+}
+
+// git runs a git command in the work tree and returns stdout
+func (repo *Repository) git(ctx context.Context, args ...string) ([]byte, error) {
+	stderr := bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repo.WorkTree}, args...)...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// parseDiff reads the added lines from a diff without context lines. The destination of each file
+// is read from the +++ line, which is /dev/null for deleted files, and each hunk header contains the
+// first line and the number of lines that were added, i.e. +12,3. A missing count is 1. The lines of
+// a hunk are skipped so that added lines, which may begin with ++, are not mistaken for a destination.
+func parseDiff(out []byte) (Changes, error) {
+	changes := make(Changes)
+	path := ""
+	remaining := 0 // removed and added lines of the current hunk
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if remaining > 0 {
+			if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+				remaining--
+			}
+			continue
+		}
+
+		if dest, ok := strings.CutPrefix(line, "+++ "); ok {
+			path = ""
+			if dest == "/dev/null" {
+				continue
+			}
+
+			if strings.HasPrefix(dest, "\"") {
+				unquoted, err := strconv.Unquote(dest)
+				if err != nil {
+					return nil, fmt.Errorf("invalid diff path %s", dest)
+				}
+				dest = unquoted
+			}
+			path = strings.TrimPrefix(dest, "b/")
+			continue
+		}
+
+		match := hunkHeader.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		start, _ := strconv.Atoi(match[2])
+		added := hunkCount(match[3])
+		remaining = hunkCount(match[1]) + added
+
+		if added > 0 && path != "" {
+			changes[path] = append(changes[path], LineRange{Start: start, End: start + added - 1})
+		}
+	}
+
+	return changes, scanner.Err()
+}
+
+func hunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(count)
+	return n
+}
+
+// matchLines matches each line of [b] to a line of [a] that was not changed, according to the
+// shortest edit script between the files (Myers, linear space). The result contains the index
+// of the matching line of [a] for each line of [b], or -1 when the line was added. Lines are
+// compared without their line endings.
+func matchLines(a, b [][]byte) []int {
+	ids := make(map[string]int)
+	intern := func(lines [][]byte) []int {
+		interned := make([]int, len(lines))
+		for i, line := range lines {
+			key := string(bytes.TrimSuffix(bytes.TrimSuffix(line, []byte{'\n'}), []byte{'\r'}))
+			id, ok := ids[key]
+			if !ok {
+				id = len(ids)
+				ids[key] = id
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+
+	d := differ{a: intern(a), b: intern(b), matches: make([]int, len(b))}
+	for i := range d.matches {
+		d.matches[i] = -1
+	}
+
+	size := 2*(len(a)+len(b)+1) + 3
+	d.forward, d.backward = make([]int, size), make([]int, size)
+	d.compare(0, len(a), 0, len(b))
+	return d.matches
+}
+
+type differ struct {
+	a, b     []int
+	matches  []int
+	forward  []int // furthest reaching x of each diagonal, searching from the start of the files
+	backward []int // furthest reaching x of each diagonal, searching from the end of the files
+}
+
+// compare matches the lines of a[aLo:aHi] and b[bLo:bHi] by splitting them at the middle snake of
+// their shortest edit script, until one of the halves is empty
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.matches[bLo] = aLo
+		aLo, bLo = aLo+1, bLo+1
+	}
+
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		d.matches[bHi-1] = aHi - 1
+		aHi, bHi = aHi-1, bHi-1
+	}
+
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+	for i := 0; i < u-x; i++ {
+		d.matches[y+i] = x + i
+	}
+
+	d.compare(aLo, x, bLo, y)
+	d.compare(u, aHi, v, bHi)
+}
+
+// middleSnake searches for the shortest edit script from both ends of the files at once and returns
+// the start and end of the snake, a run of equal lines, where the searches overlap
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta, odd := n-m, (n-m)%2 != 0
+	offset := n + m + 1
+	d.forward[offset+1], d.backward[offset+1] = 0, 0
+
+	for edits := 0; edits <= (n+m+1)/2; edits++ {
+		for k := -edits; k <= edits; k += 2 {
+			x := d.forward[offset+k-1] + 1
+			if k == -edits || (k != edits && d.forward[offset+k-1] < d.forward[offset+k+1]) {
+				x = d.forward[offset+k+1]
+			}
+
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x, y = x+1, y+1
+			}
+			d.forward[offset+k] = x
+
+			// the diagonal of the backward search that corresponds to k is delta - k
+			if odd && delta-k >= -(edits-1) && delta-k <= edits-1 && x+d.backward[offset+delta-k] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		for k := -edits; k <= edits; k += 2 {
+			x := d.backward[offset+k-1] + 1
+			if k == -edits || (k != edits && d.backward[offset+k-1] < d.backward[offset+k+1]) {
+				x = d.backward[offset+k+1]
+			}
+
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x, y = x+1, y+1
+			}
+			d.backward[offset+k] = x
+
+			if !odd && delta-k >= -edits && delta-k <= edits && x+d.forward[offset+delta-k] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+
+	// unreachable, the searches overlap within (n+m+1)/2 edits
+	return aLo, bLo, aLo, bLo
+}
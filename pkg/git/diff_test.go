@@ -0,0 +1,77 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangesOverlaps(t *testing.T) {
+	changes := git.Changes{"src/main.go": {{Start: 3, End: 5}, {Start: 10, End: 10}}}
+
+	testCases := []struct {
+		name       string
+		path       string
+		start, end int
+		expected   bool
+	}{
+		{name: "Should match a single added line", path: "src/main.go", start: 10, end: 10, expected: true},
+		{name: "Should match comments that start before the range", path: "src/main.go", start: 1, end: 3, expected: true},
+		{name: "Should match comments that end after the range", path: "src/main.go", start: 5, end: 8, expected: true},
+		{name: "Should not match lines between ranges", path: "src/main.go", start: 6, end: 9, expected: false},
+		{name: "Should not match other files", path: "src/other.go", start: 3, end: 3, expected: false},
+		{name: "Should treat an end before the start as a single line", path: "src/main.go", start: 4, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, changes.Overlaps(tc.path, tc.start, tc.end))
+		})
+	}
+}
+
+func TestRepositoryAdded(t *testing.T) {
+	dir := newGitRepository(t)
+	run := func(args ...string) {
+		runGit(t, dir, args...)
+	}
+
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("removed.go", "package main\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("tag", "base")
+
+	// the second line begins with ++ which must not be mistaken for the destination of a file
+	write("main.go", "package main\n++ not a header\n\nfunc main() {}\nfunc other() {}\n")
+	run("rm", "-q", "removed.go")
+	run("add", ".")
+	run("commit", "-q", "-m", "feature")
+
+	write("main.go", "// uncommitted\npackage main\n++ not a header\n\nfunc main() {}\nfunc other() {}\n")
+	write("untracked.go", "package main\n")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	added, err := repo.AddedIn(context.Background(), "base...HEAD")
+	require.NoError(t, err)
+	require.Equal(t, git.Changes{"main.go": {{Start: 2, End: 2}, {Start: 5, End: 5}}}, added)
+
+	since, err := repo.AddedSince(context.Background(), "base")
+	require.NoError(t, err)
+	require.Equal(t, []git.LineRange{{Start: 1, End: 1}, {Start: 3, End: 3}, {Start: 6, End: 6}}, since["main.go"])
+	require.True(t, since.Overlaps("untracked.go", 1, 1))
+	require.NotContains(t, since, "removed.go")
+
+	_, err = repo.AddedIn(context.Background(), "missing...HEAD")
+	require.Error(t, err)
+}
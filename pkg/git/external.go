@@ -0,0 +1,288 @@
+package git
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+/*
+EXTERNAL BACKENDS ALLOW ISSUE SUMMONER TO REPORT ISSUES TO TRACKERS THAT ARE NOT BUILT IN.
+WHEN [NewGitManager] IS INVOKED WITH A SOURCE CODE HOST THAT IS NOT SUPPORTED, AN EXECUTABLE
+NAMED issue-summoner-backend-<sch> IS LOOKED UP ON THE PATH. THE EXECUTABLE IS INVOKED ONCE
+PER OPERATION AND COMMUNICATES USING JSON OVER STDIO:
+
+- STDIN: A SINGLE [ExternalRequest] OBJECT
+- STDOUT: A SINGLE [ExternalResponse] OBJECT
+- STDERR: FORWARDED TO THE USER, WHICH CAN BE USED FOR AUTHORIZATION INSTRUCTIONS
+
+SUPPORTED METHODS AND THEIR PARAMS/RESULTS:
+
+- authorize:     PARAMS {} OR {"token": string}           RESULT {}
+- authenticated: PARAMS {}                                RESULT {"authenticated": bool, "owner"?: string, "expiresAt"?: RFC 3339}
+- report:        PARAMS {"title": string, "body": string, "labels"?: [string], "assignees"?: [string]} RESULT {"id": int}
+- status:        PARAMS {"issueNumber": int}              RESULT {"resolved": bool}
+- close:         PARAMS {"issueNumber": int}              RESULT {}
+- list:          PARAMS {}                                RESULT {"issues": [{"id": int, "title": string, "body": string}]}
+
+BACKENDS THAT DO NOT SUPPORT THE LIST METHOD CAN RESPOND WITH AN ERROR, WHICH SKIPS DUPLICATE DETECTION.
+*/
+
+const (
+	ExternalBackendPrefix   = "issue-summoner-backend-"
+	ExternalProtocolVersion = 1
+	ExternalMethodAuthorize = "authorize"
+	ExternalMethodAuth      = "authenticated"
+	ExternalMethodReport    = "report"
+	ExternalMethodStatus    = "status"
+	ExternalMethodClose     = "close"
+	ExternalMethodList      = "list"
+)
+
+type ExternalRequest struct {
+	Version    int                `json:"version"`
+	Method     string             `json:"method"`
+	Repository ExternalRepository `json:"repository"`
+	Params     json.RawMessage    `json:"params"`
+}
+
+type ExternalRepository struct {
+	WorkTree  string `json:"workTree"`
+	Owner     string `json:"owner"`
+	Name      string `json:"name"`
+	Project   string `json:"project,omitempty"`
+	RemoteUrl string `json:"remoteUrl"`
+}
+
+type ExternalResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type externalReportParams struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+type externalReportResult struct {
+	ID int `json:"id"`
+}
+
+type externalAuthorizeParams struct {
+	Token string `json:"token,omitempty"`
+}
+
+// externalStatusParams are sent for both the status and close methods
+type externalStatusParams struct {
+	IssueNumber int `json:"issueNumber"`
+}
+
+type externalStatusResult struct {
+	Resolved bool `json:"resolved"`
+}
+
+type externalListResult struct {
+	Issues []struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	} `json:"issues"`
+}
+
+type externalAuthResult struct {
+	Authenticated bool      `json:"authenticated"`
+	Owner         string    `json:"owner,omitempty"`
+	ExpiresAt     time.Time `json:"expiresAt,omitempty"`
+}
+
+type externalManager struct {
+	name    string // name of the backend, i.e. the --sch flag value
+	path    string // location of the backend executable
+	repo    *Repository
+	profile Profile
+	opts    ManagerOptions
+}
+
+// newExternalManager locates the executable for the backend on the PATH.
+// [exec.ErrNotFound] is returned when there is no backend with the given name.
+func newExternalManager(name string, repo *Repository, profile Profile, opts ManagerOptions) (*externalManager, error) {
+	path, err := exec.LookPath(ExternalBackendPrefix + name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalManager{name: name, path: path, repo: repo, profile: profile, opts: opts}, nil
+}
+
+func (ext *externalManager) Authorize() error {
+	return ext.call(ExternalMethodAuthorize, externalAuthorizeParams{}, nil)
+}
+
+// StoreToken hands a personal access token to the backend, which is responsible for storing it
+func (ext *externalManager) StoreToken(token string) error {
+	return ext.call(ExternalMethodAuthorize, externalAuthorizeParams{Token: token}, nil)
+}
+
+func (ext *externalManager) Validate() (TokenInfo, error) {
+	var res externalAuthResult
+	if err := ext.call(ExternalMethodAuth, struct{}{}, &res); err != nil {
+		return TokenInfo{}, err
+	}
+
+	info := TokenInfo{Owner: res.Owner, ExpiresAt: res.ExpiresAt}
+	if !res.Authenticated {
+		return info, &AuthError{Sch: ext.name, Profile: ext.profile.Name, Reason: "was rejected by the backend"}
+	}
+
+	return info, nil
+}
+
+func (ext *externalManager) Authenticated() bool {
+	_, err := ext.Validate()
+	return err == nil
+}
+
+func (ext *externalManager) Report(issue ReportRequest, res chan ReportResponse) {
+	result := ReportResponse{Index: issue.Index}
+
+	var reported externalReportResult
+	params := externalReportParams{
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Labels:    mergeLabels(ext.profile.Config.Labels, issue.Labels),
+		Assignees: issue.Assignees,
+	}
+
+	if ext.opts.DryRun != nil {
+		req, err := ext.encode(ExternalMethodReport, params)
+		if err == nil {
+			_, err = ext.opts.dryRun("EXEC", ext.path, req)
+		}
+		if err != nil {
+			result.Err = fmt.Errorf(errReport, issue.Title, err)
+		}
+		res <- result
+		return
+	}
+
+	if err := ext.call(ExternalMethodReport, params, &reported); err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	result.ID = reported.ID
+	res <- result
+}
+
+func (ext *externalManager) GetStatus(issueNum, index int, status chan StatusResponse) {
+	res := StatusResponse{Index: index, Resolved: false}
+
+	var result externalStatusResult
+	if err := ext.call(ExternalMethodStatus, externalStatusParams{IssueNumber: issueNum}, &result); err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	res.Resolved = result.Resolved
+	status <- res
+}
+
+func (ext *externalManager) Close(issueNum int) error {
+	return ext.call(ExternalMethodClose, externalStatusParams{IssueNumber: issueNum}, nil)
+}
+
+func (ext *externalManager) ListOpen() ([]RemoteIssue, error) {
+	var res externalListResult
+	if err := ext.call(ExternalMethodList, struct{}{}, &res); err != nil {
+		return nil, err
+	}
+
+	open := make([]RemoteIssue, 0, len(res.Issues))
+	for _, issue := range res.Issues {
+		open = append(open, RemoteIssue{Number: issue.ID, Title: issue.Title, Body: issue.Body})
+	}
+	return open, nil
+}
+
+// call executes the backend with a request for [method] and decodes the result
+// into [out]. [out] can be nil when the result of the method is not needed.
+func (ext *externalManager) call(method string, params any, out any) error {
+	req, err := ext.encode(method, params)
+	if err != nil {
+		return err
+	}
+
+	stdout := bytes.Buffer{}
+	cmd := exec.CommandContext(ext.opts.context(), ext.path)
+	cmd.Stdin = bytes.NewReader(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	runErr := cmd.Run()
+
+	var res ExternalResponse
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("%s backend failed to handle %s request: %w", ext.name, method, runErr)
+		}
+		return fmt.Errorf("%s backend returned an invalid %s response: %w", ext.name, method, err)
+	}
+
+	if res.Error != "" {
+		return fmt.Errorf("%s backend: %s", ext.name, res.Error)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("%s backend failed to handle %s request: %w", ext.name, method, runErr)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if len(res.Result) == 0 {
+		return errors.New(ext.name + " backend did not return a result for " + method)
+	}
+
+	return json.Unmarshal(res.Result, out)
+}
+
+// externalBackendName returns a normalized backend name. Backend names are
+// used in file names so they may not contain path separators.
+func externalBackendName(sch string) (string, bool) {
+	name := strings.TrimSpace(sch)
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	return name, true
+}
+
+// encode builds the request that is written to the stdin of the backend
+func (ext *externalManager) encode(method string, params any) ([]byte, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(ExternalRequest{
+		Version: ExternalProtocolVersion,
+		Method:  method,
+		Repository: ExternalRepository{
+			WorkTree:  ext.repo.WorkTree,
+			Owner:     ext.repo.UserName,
+			Name:      ext.repo.RepoName,
+			Project:   ext.repo.Project,
+			RemoteUrl: ext.repo.remoteUrl,
+		},
+		Params: rawParams,
+	})
+}
@@ -0,0 +1,126 @@
+package git_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeBackendEnv = "ISSUE_SUMMONER_FAKE_BACKEND"
+
+// TestMain allows the test binary to act as an external backend. When the fake backend
+// environment variable is set, the binary handles a single protocol request and exits.
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeBackendEnv) == "1" {
+		runFakeBackend()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runFakeBackend creates issues with an id of 42 and considers even issue numbers resolved
+func runFakeBackend() {
+	var req git.ExternalRequest
+	res := git.ExternalResponse{}
+	encoder := json.NewEncoder(os.Stdout)
+
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		res.Error = err.Error()
+		_ = encoder.Encode(res)
+		return
+	}
+
+	var result any
+	switch req.Method {
+	case git.ExternalMethodAuthorize:
+		result = struct{}{}
+	case git.ExternalMethodAuth:
+		result = map[string]bool{"authenticated": req.Repository.Name == "issue-summoner"}
+	case git.ExternalMethodReport:
+		var params map[string]string
+		_ = json.Unmarshal(req.Params, &params)
+		if params["title"] == "" {
+			res.Error = "title is required"
+		}
+		result = map[string]int{"id": 42}
+	case git.ExternalMethodStatus:
+		var params map[string]int
+		_ = json.Unmarshal(req.Params, &params)
+		result = map[string]bool{"resolved": params["issueNumber"]%2 == 0}
+	case git.ExternalMethodClose:
+		result = struct{}{}
+	case git.ExternalMethodList:
+		result = map[string]any{"issues": []map[string]any{{"id": 42, "title": "external issue"}}}
+	default:
+		res.Error = "unsupported method " + req.Method
+	}
+
+	res.Result, _ = json.Marshal(result)
+	_ = encoder.Encode(res)
+}
+
+func installFakeBackend(t *testing.T, name string) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(exe, filepath.Join(dir, git.ExternalBackendPrefix+name)))
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(fakeBackendEnv, "1")
+}
+
+func TestExternalManager(t *testing.T) {
+	setTestConfigDir(t)
+	installFakeBackend(t, "fake")
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager("fake", repo, git.ManagerOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Authorize())
+	require.True(t, manager.Authenticated())
+
+	reported := make(chan git.ReportResponse, 2)
+	manager.Report(git.ReportRequest{Title: "external issue", Index: 3}, reported)
+	manager.Report(git.ReportRequest{Title: "", Index: 4}, reported)
+
+	res := <-reported
+	require.NoError(t, res.Err)
+	require.Equal(t, 42, res.ID)
+	require.Equal(t, 3, res.Index)
+
+	res = <-reported
+	require.ErrorContains(t, res.Err, "title is required")
+
+	status := make(chan git.StatusResponse, 2)
+	manager.GetStatus(42, 0, status)
+	manager.GetStatus(43, 1, status)
+
+	require.True(t, (<-status).Resolved)
+	require.False(t, (<-status).Resolved)
+	require.NoError(t, manager.Close(42))
+
+	open, err := manager.ListOpen()
+	require.NoError(t, err)
+	require.Equal(t, []git.RemoteIssue{{Number: 42, Title: "external issue"}}, open)
+}
+
+func TestNewGitManagerUnknownBackend(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("PATH", t.TempDir())
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager("does-not-exist", repo, git.ManagerOptions{})
+	require.Error(t, err)
+	require.Nil(t, manager)
+}
@@ -3,6 +3,10 @@ package git
 import (
 	"errors"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 )
@@ -13,19 +17,148 @@ const (
 	Github    sourceCodeHost = "github"
 	Gitlab    sourceCodeHost = "gitlab"
 	Bitbucket sourceCodeHost = "bitbucket"
+	Azure     sourceCodeHost = "azure"
+	Local     sourceCodeHost = "local"
 )
 
 type GitManager interface {
 	Authorize() error
+	StoreToken(token string) error // stores a personal access token, i.e. <issue-summoner authorize --with-token>
 	Report(issue ReportRequest, res chan ReportResponse)
 	GetStatus(issueNum, index int, res chan StatusResponse)
+	Close(issueNum int) error         // closes an issue that should not have been reported, see [Journal]
+	ListOpen() ([]RemoteIssue, error) // open issues of the repository, used to detect duplicates
+	Validate() (TokenInfo, error)     // verifies the access token with the source code host, see [AuthError]
 	Authenticated() bool
 }
 
+// TokenInfo describes an access token that was verified by [GitManager.Validate]
+type TokenInfo struct {
+	Owner     string    // account the token belongs to, empty when the host does not disclose it
+	Scopes    []string  // OAuth scopes granted to the token. Empty for fine-grained tokens
+	ExpiresAt time.Time // zero when the token does not expire
+}
+
+// AuthError is returned by [GitManager.Validate] when an access token is missing, expired,
+// revoked or lacks the permissions that are required to report issues.
+type AuthError struct {
+	Sch     sourceCodeHost
+	Profile string // set for named profiles, see [Profile]
+	Reason  string
+}
+
+func (e *AuthError) Error() string {
+	command := "issue-summoner authorize -s " + e.Sch
+	if e.Profile != "" && e.Profile != e.Sch {
+		command += " --profile " + e.Profile
+	}
+
+	return fmt.Sprintf("%s access token %s. Please re-run <%s>", e.Sch, e.Reason, command)
+}
+
+// RemoteIssue is an open issue that exists on the source code host
+type RemoteIssue struct {
+	Number int
+	Title  string
+	Body   string
+}
+
+// FindDuplicate returns the open issue that was reported for the same annotation. Issues are
+// matched by the fingerprint marker in their body, see [common.Fingerprint], followed by the
+// content of the fingerprint, which matches annotations in files that were renamed, and lastly
+// by title. Titles are compared without regard to case or repeated whitespace.
+func FindDuplicate(open []RemoteIssue, title string, fp common.Fingerprint) (RemoteIssue, bool) {
+	var byContent, byTitle *RemoteIssue
+	title = normalizeTitle(title)
+
+	for i, remote := range open {
+		if remoteFp, ok := common.ParseFingerprint(remote.Body); ok && !fp.IsZero() {
+			if remoteFp.Location == fp.Location {
+				return remote, true
+			}
+
+			if remoteFp.Content == fp.Content && byContent == nil {
+				byContent = &open[i]
+			}
+		}
+
+		if title != "" && normalizeTitle(remote.Title) == title && byTitle == nil {
+			byTitle = &open[i]
+		}
+	}
+
+	switch {
+	case byContent != nil:
+		return *byContent, true
+	case byTitle != nil:
+		return *byTitle, true
+	default:
+		return RemoteIssue{}, false
+	}
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
 type ReportRequest struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
-	Index int    // index location in [IssueManager.Issues] slice in the issue package
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels,omitempty"`    // merged with the labels of the profile
+	Assignees []string `json:"assignees,omitempty"` // usernames, or emails, of the source code host. See [Assignee]
+	Index     int      // index location in [IssueManager.Issues] slice in the issue package
+}
+
+// githubNoreply matches the private email of a GitHub user, i.e. 1234+octocat@users.noreply.github.com
+var githubNoreply = regexp.MustCompile(`(?i)^(?:\d+\+)?([a-z0-9-]+)@users\.noreply\.github\.com$`)
+
+// Assignee returns the user of a source code host that an issue can be assigned to, for the email of
+// a commit author. [users] maps emails to usernames, such as the authors of the project config, and
+// takes precedence. GitHub users are derived from their noreply email, the other hosts accept the
+// email itself. False is returned when the user is not known.
+func Assignee(sch sourceCodeHost, email string, users map[string]string) (string, bool) {
+	for address, user := range users {
+		if strings.EqualFold(address, email) {
+			return user, user != ""
+		}
+	}
+
+	if email == "" {
+		return "", false
+	}
+
+	if sch == Github {
+		match := githubNoreply.FindStringSubmatch(email)
+		if match == nil {
+			return "", false
+		}
+		return match[1], true
+	}
+
+	return email, true
+}
+
+// OwnerAssignee returns the user that an issue can be assigned to for an owner of a CODEOWNERS rule,
+// which is a @user, an @org/team or an email. Emails are resolved with [Assignee]. Teams can not be
+// assigned on GitHub and neither can usernames on Azure DevOps, whose work items are assigned by
+// email. The local and external backends receive the owner as it was written.
+func OwnerAssignee(sch sourceCodeHost, owner string, users map[string]string) (string, bool) {
+	name, ok := strings.CutPrefix(owner, "@")
+	if !ok {
+		return Assignee(sch, owner, users)
+	}
+
+	switch sch {
+	case Github:
+		if name == "" || strings.Contains(name, "/") {
+			return "", false
+		}
+		return name, true
+	case Azure:
+		return "", false
+	default:
+		return owner, true
+	}
 }
 
 type ReportResponse struct {
@@ -40,26 +173,48 @@ type StatusResponse struct {
 	Index    int // index location in [IssueManager.Issues] slice in the issue package
 }
 
-func NewGitManager(sch sourceCodeHost, repo *Repository) (GitManager, error) {
+func NewGitManager(sch sourceCodeHost, repo *Repository, opts ManagerOptions) (GitManager, error) {
 	conf, err := common.ReadConfig()
 	if err != nil {
 		return nil, err
 	}
 
+	profile := SelectProfile(conf, sch, repo, opts.Profile)
+	sch = profile.Backend
+
 	switch sch {
 	case Bitbucket:
 		return nil, errors.New("bitbucket is not supported yet. Check back soon")
 	case Gitlab:
 		return nil, errors.New("gitlab is not supported yet. Check back soon")
 	case Github:
-		return newGithubManager(conf, repo)
-	default:
-		return nil, fmt.Errorf(
-			"unsupported source code host. expected one of the following: %s %s %s but got %s",
-			Github,
-			Gitlab,
-			Bitbucket,
-			sch,
-		)
+		return newGithubManager(conf, repo, profile, opts)
+	case Azure:
+		return newAzureManager(conf, repo, profile, opts)
+	case Local:
+		return newLocalManager(repo, profile, opts)
 	}
+
+	// hosts that are not built in can be provided by an external backend executable
+	if name, ok := externalBackendName(sch); ok {
+		ext, err := newExternalManager(name, repo, profile, opts)
+		if err == nil {
+			return ext, nil
+		}
+
+		if !errors.Is(err, exec.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf(
+		"unsupported source code host. expected one of the following: %s %s %s %s %s or an %s<name> executable on your PATH but got %s",
+		Github,
+		Gitlab,
+		Bitbucket,
+		Azure,
+		Local,
+		ExternalBackendPrefix,
+		sch,
+	)
 }
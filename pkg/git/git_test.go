@@ -0,0 +1,101 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicate(t *testing.T) {
+	fp := common.NewFingerprint("refactor the lexer", "Lex", "pkg/lexer/lexer.go", 1)
+	renamed := common.NewFingerprint("refactor the lexer", "Lex", "pkg/scanner/lexer.go", 1)
+	other := common.NewFingerprint("split the lexer", "Lex", "pkg/lexer/lexer.go", 1)
+
+	open := []git.RemoteIssue{
+		{Number: 1, Title: "Refactor the lexer"},
+		{Number: 2, Title: "Handle  nested   comments"},
+		{Number: 3, Title: "Refactor the lexer", Body: "fix me\n\n" + other.Marker()},
+		{Number: 4, Title: "Refactored lexer", Body: "fix me\n\n" + fp.Marker()},
+	}
+
+	testCases := []struct {
+		name     string
+		title    string
+		fp       common.Fingerprint
+		expected int
+		found    bool
+	}{
+		{name: "Should match fingerprints before titles", title: "Refactor the lexer", fp: fp, expected: 4, found: true},
+		{name: "Should match the content of fingerprints in renamed files", title: "Refactor the lexer", fp: renamed, expected: 4, found: true},
+		{name: "Should match identical titles", title: "Refactor the lexer", expected: 1, found: true},
+		{name: "Should ignore case", title: "refactor THE lexer", expected: 1, found: true},
+		{name: "Should ignore repeated whitespace", title: " Handle nested comments ", expected: 2, found: true},
+		{name: "Should not match different titles", title: "Refactor the parser"},
+		{name: "Should not match empty titles", title: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remote, found := git.FindDuplicate(open, tc.title, tc.fp)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, remote.Number)
+		})
+	}
+}
+
+func TestAssignee(t *testing.T) {
+	users := map[string]string{"Jane@Example.com": "jdoe", "bot@example.com": ""}
+
+	testCases := []struct {
+		name     string
+		sch      string
+		email    string
+		expected string
+		found    bool
+	}{
+		{name: "Should map emails to users without regard to case", sch: git.Github, email: "jane@example.com", expected: "jdoe", found: true},
+		{name: "Should skip users that are mapped to an empty username", sch: git.Azure, email: "bot@example.com"},
+		{name: "Should derive github users from noreply emails", sch: git.Github, email: "1234+octocat@users.noreply.github.com", expected: "octocat", found: true},
+		{name: "Should derive github users from legacy noreply emails", sch: git.Github, email: "octocat@users.noreply.github.com", expected: "octocat", found: true},
+		{name: "Should not assign github issues to emails", sch: git.Github, email: "john@example.com"},
+		{name: "Should assign azure work items to emails", sch: git.Azure, email: "john@example.com", expected: "john@example.com", found: true},
+		{name: "Should not assign empty emails", sch: git.Local, email: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assignee, found := git.Assignee(tc.sch, tc.email, users)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, assignee)
+		})
+	}
+}
+
+func TestOwnerAssignee(t *testing.T) {
+	users := map[string]string{"jane@example.com": "jdoe"}
+
+	testCases := []struct {
+		name     string
+		sch      string
+		owner    string
+		expected string
+		found    bool
+	}{
+		{name: "Should strip the @ of github users", sch: git.Github, owner: "@octocat", expected: "octocat", found: true},
+		{name: "Should not assign github teams", sch: git.Github, owner: "@acme/payments"},
+		{name: "Should map email owners to users", sch: git.Github, owner: "jane@example.com", expected: "jdoe", found: true},
+		{name: "Should not assign azure work items to usernames", sch: git.Azure, owner: "@octocat"},
+		{name: "Should assign azure work items to email owners", sch: git.Azure, owner: "john@example.com", expected: "john@example.com", found: true},
+		{name: "Should keep the owners of other hosts as written", sch: git.Gitlab, owner: "@acme/payments", expected: "@acme/payments", found: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assignee, found := git.OwnerAssignee(tc.sch, tc.owner, users)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.expected, assignee)
+		})
+	}
+}
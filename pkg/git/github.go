@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 )
 
 const (
+	githubHost       = "github.com"
 	githubBaseUrl    = "https://github.com"
 	githubApiBaseUrl = "https://api.github.com"
 	githubApiVersion = "2022-11-28"
@@ -23,13 +27,34 @@ const (
 type githubManager struct {
 	conf      common.Config
 	repo      *Repository
+	profile   Profile
+	endpoints githubEndpoints
+	opts      ManagerOptions
+	token     string      // resolved access token, see [resolveToken]
+	source    tokenSource // where the access token was located
 	device    requestDeviceResponse
 	headers   http.Header
 	reportURL string
 }
 
-func newGithubManager(conf common.Config, repo *Repository) (*githubManager, error) {
-	ghub := &githubManager{conf: conf, repo: repo}
+// githubEndpoints are the resolved urls and OAuth client id for the host of the
+// repository. github.com uses the public endpoints while any other host is treated
+// as a GitHub Enterprise Server instance.
+type githubEndpoints struct {
+	host           string
+	baseUrl        string
+	apiBaseUrl     string
+	clientId       string
+	deviceCodeUrl  string
+	accessTokenUrl string
+}
+
+func newGithubManager(conf common.Config, repo *Repository, profile Profile, opts ManagerOptions) (*githubManager, error) {
+	ghub := &githubManager{conf: conf, repo: repo, profile: profile, opts: opts}
+
+	if err := ghub.resolveEndpoints(); err != nil {
+		return nil, err
+	}
 
 	if err := ghub.prepareHeaders(); err != nil {
 		return nil, err
@@ -42,25 +67,135 @@ func newGithubManager(conf common.Config, repo *Repository) (*githubManager, err
 	return ghub, nil
 }
 
+// resolveEndpoints derives the endpoints for the host of the repository remote url, or the host
+// of the profile. Base urls of the manager options take precedence, followed by endpoints that
+// are set in the hosts section of the profile and the base urls of the profile itself.
+func (ghub *githubManager) resolveEndpoints() error {
+	host := ghub.repo.Host
+	if ghub.profile.Config.Host != "" {
+		host = ghub.profile.Config.Host
+	}
+
+	if host == "" {
+		host = githubHost
+	}
+
+	endpoints := githubEndpoints{
+		host:       host,
+		baseUrl:    githubBaseUrl,
+		apiBaseUrl: githubApiBaseUrl,
+		clientId:   githubClientId,
+	}
+
+	if host != githubHost {
+		endpoints.baseUrl = "https://" + host
+		endpoints.apiBaseUrl = "https://" + host + "/api/v3"
+		endpoints.clientId = ""
+	}
+
+	if ghub.profile.Config.BaseUrl != "" {
+		endpoints.baseUrl = strings.TrimSuffix(ghub.profile.Config.BaseUrl, "/")
+	}
+
+	if ghub.profile.Config.ApiBaseUrl != "" {
+		endpoints.apiBaseUrl = strings.TrimSuffix(ghub.profile.Config.ApiBaseUrl, "/")
+	}
+
+	ghub.endpoints.host = host
+	hostConf := ghub.hostConfig()
+	if hostConf.BaseUrl != "" {
+		endpoints.baseUrl = strings.TrimSuffix(hostConf.BaseUrl, "/")
+	}
+
+	if hostConf.ApiBaseUrl != "" {
+		endpoints.apiBaseUrl = strings.TrimSuffix(hostConf.ApiBaseUrl, "/")
+	}
+
+	if hostConf.ClientID != "" {
+		endpoints.clientId = hostConf.ClientID
+	}
+
+	if ghub.opts.BaseUrl != "" {
+		endpoints.baseUrl = strings.TrimSuffix(ghub.opts.BaseUrl, "/")
+	}
+
+	if ghub.opts.ApiBaseUrl != "" {
+		endpoints.apiBaseUrl = strings.TrimSuffix(ghub.opts.ApiBaseUrl, "/")
+	}
+
+	var err error
+	endpoints.deviceCodeUrl = hostConf.DeviceCodeUrl
+	if endpoints.deviceCodeUrl == "" {
+		endpoints.deviceCodeUrl, err = common.ConstructURL(endpoints.baseUrl, nil, verificationUris...)
+		if err != nil {
+			return err
+		}
+	}
+
+	endpoints.accessTokenUrl = hostConf.AccessTokenUrl
+	if endpoints.accessTokenUrl == "" {
+		endpoints.accessTokenUrl, err = common.ConstructURL(endpoints.baseUrl, nil, createTokenUris...)
+		if err != nil {
+			return err
+		}
+	}
+
+	ghub.endpoints = endpoints
+	return nil
+}
+
+// hostConfig returns the config for the host of the repository. Access tokens for
+// github.com were stored in the top level auth entry before hosts were supported,
+// which is used as a fallback.
+func (ghub *githubManager) hostConfig() common.HostConfig {
+	host := ghub.endpoints.host
+	entry := ghub.conf[ghub.profile.Name]
+	hostConf := entry.Hosts[host]
+	if !hostConf.Auth.Stored() && host == githubHost {
+		hostConf.Auth = entry.Auth
+	}
+
+	return hostConf
+}
+
 func (ghub *githubManager) prepareHeaders() error {
-	var accessToken string
-	if mp, ok := ghub.conf[Github]; ok {
-		accessToken = mp.Auth.AccessToken
-	} else {
-		return errors.New("failed to retrieve access token. You may need to run <issue-summoner authorize>")
+	// GITHUB_TOKEN is usually a github.com token, which must not be sent to an enterprise server
+	var envVars []string
+	if ghub.endpoints.host == githubHost {
+		envVars = []string{"GITHUB_TOKEN"}
+	}
+
+	hostConf := ghub.hostConfig()
+	accessToken, source, err := resolveToken(tokenRequest{
+		host:      ghub.endpoints.host,
+		tokenFile: ghub.opts.TokenFile,
+		source:    ghub.profile.Config.TokenSource,
+		envVars:   envVars,
+		helper:    hostConf.CredentialHelper,
+		stored:    hostConf.Auth,
+	})
+	if err != nil {
+		return err
 	}
 
+	ghub.source = source
+	ghub.setToken(accessToken)
+	return nil
+}
+
+func (ghub *githubManager) setToken(token string) {
+	ghub.token = token
+
 	header := make(http.Header)
 	header.Add("Accept", "application/vnd.github+json")
-	header.Add("Authorization", "Bearer "+accessToken)
+	header.Add("Authorization", "Bearer "+token)
 	header.Add("X-GitHub-Api-Version", githubApiVersion)
 	ghub.headers = header
-	return nil
 }
 
 func (ghub *githubManager) constructReportURL() error {
 	paths := []string{"repos", ghub.repo.UserName, ghub.repo.RepoName, "issues"}
-	u, err := common.ConstructURL(githubApiBaseUrl, nil, paths...)
+	u, err := common.ConstructURL(ghub.endpoints.apiBaseUrl, nil, paths...)
 	if err != nil {
 		return err
 	}
@@ -80,7 +215,16 @@ func (ghub *githubManager) constructReportURL() error {
 func (ghub *githubManager) Authorize() error {
 	var err error
 
-	if ghub.device, err = requestDevice(); err != nil {
+	if ghub.endpoints.clientId == "" {
+		return fmt.Errorf(
+			"an OAuth app client id is required to authorize %s. Set hosts.%s.clientId for the %s entry in your config.json file",
+			ghub.endpoints.host,
+			ghub.endpoints.host,
+			ghub.profile.Name,
+		)
+	}
+
+	if ghub.device, err = ghub.requestDevice(); err != nil {
 		return err
 	}
 
@@ -93,20 +237,67 @@ func (ghub *githubManager) Authorize() error {
 
 	select {
 	case token := <-tokenChan:
-		if entry, ok := ghub.conf[Github]; ok {
-			entry.Auth = common.AuthConfig{
-				AccessToken: token.AccessToken,
-				CreatedAt:   time.Now(),
-			}
-			ghub.conf[Github] = entry
-		} else {
-			return fmt.Errorf("No entry for %s in config map", Github)
+		return ghub.storeToken(token)
+	case err = <-errChan:
+		return err
+	}
+}
+
+// StoreToken writes an access token, for the host of the repository, to the credential store
+// and references it in config.json
+func (ghub *githubManager) StoreToken(token string) error {
+	return ghub.storeToken(createTokenResponse{AccessToken: token})
+}
+
+// storeToken writes the access token, and the refresh token for apps that issue
+// expiring user tokens, to the credential store
+func (ghub *githubManager) storeToken(token createTokenResponse) error {
+	if token.AccessToken == "" {
+		return errors.New("expected an access token but got an empty string")
+	}
+
+	auth, err := common.NewAuthConfig(common.CredentialKey(ghub.profile.Name, ghub.endpoints.host), token.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	if token.ExpiresIn > 0 {
+		auth.ExpiresAt = auth.CreatedAt.Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	if token.RefreshToken != "" {
+		if err := auth.SetRefreshToken(token.RefreshToken); err != nil {
+			return err
 		}
+	}
 
-		return common.WriteToConfig(ghub.conf)
-	case err = <-errChan:
+	if err := ghub.writeAuth(auth); err != nil {
 		return err
 	}
+
+	ghub.source = TokenSourceConfig
+	ghub.setToken(token.AccessToken)
+	return nil
+}
+
+// writeAuth stores the auth config in the hosts section of the profile. Named profiles
+// that do not exist yet are created.
+func (ghub *githubManager) writeAuth(auth common.AuthConfig) error {
+	entry, ok := ghub.conf[ghub.profile.Name]
+	if !ok {
+		entry = ghub.profile.Config
+	}
+
+	if entry.Hosts == nil {
+		entry.Hosts = make(map[string]common.HostConfig)
+	}
+
+	hostConf := entry.Hosts[ghub.endpoints.host]
+	hostConf.Auth = auth
+	entry.Hosts[ghub.endpoints.host] = hostConf
+	ghub.conf[ghub.profile.Name] = entry
+
+	return common.WriteToConfig(ghub.conf)
 }
 
 type requestDeviceResponse struct {
@@ -118,24 +309,24 @@ type requestDeviceResponse struct {
 }
 
 var (
-	verificationUris   = []string{"login", "device", "code"}
-	verificationParams = map[string]string{"client_id": githubClientId, "scope": "repo"}
+	verificationUris = []string{"login", "device", "code"}
 )
 
 // requestDevice sends a POST request to Githubs device and user verification code
 // service. It returns a struct containing information that is needed to create an access token.
 // https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps#device-flow
-func requestDevice() (requestDeviceResponse, error) {
+func (ghub *githubManager) requestDevice() (requestDeviceResponse, error) {
 	var res requestDeviceResponse
 	headers := http.Header{}
 	headers.Add("Accept", "application/json")
 
-	url, err := common.ConstructURL(githubBaseUrl, verificationParams, verificationUris...)
+	params := map[string]string{"client_id": ghub.endpoints.clientId, "scope": "repo"}
+	url, err := common.ConstructURL(ghub.endpoints.deviceCodeUrl, params)
 	if err != nil {
 		return res, err
 	}
 
-	_, data, err := common.Request("POST", url, nil, headers)
+	_, data, err := ghub.opts.request("POST", url, nil, headers)
 	if err != nil {
 		return res, err
 	}
@@ -163,7 +354,7 @@ func (ghub *githubManager) startDeviceFlow() error {
 
 	fmt.Printf("Enter User Code: %s at %s\n", ghub.device.UserCode, ghub.device.VerificationUri)
 
-	if err := common.OpenBrowser(ghub.device.VerificationUri); err != nil {
+	if err := ghub.opts.openBrowser(ghub.device.VerificationUri); err != nil {
 		fmt.Printf(
 			"Failed to open default browser. Please open a browser, visit %s, and enter your User Code\n",
 			ghub.device.VerificationUri,
@@ -194,36 +385,39 @@ func (ghub *githubManager) pollAuthService(res chan createTokenResponse, err cha
 			<-ticker.C
 		} else {
 			res <- data
+			return
 		}
 	}
 }
 
 type createTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	Scope       string `json:"scope"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int    `json:"expires_in"`    // only set for apps that issue expiring user tokens
+	RefreshToken string `json:"refresh_token"` // only set for apps that issue expiring user tokens
 }
 
 var (
-	createTokenUris   = []string{"login", "oauth", "access_token"}
-	createTokenParams = map[string]string{
-		"client_id":  githubClientId,
-		"grant_type": githubGrantType,
-	}
+	createTokenUris = []string{"login", "oauth", "access_token"}
 )
 
 func (ghub *githubManager) createToken() (createTokenResponse, error) {
 	var res createTokenResponse
 	headers := http.Header{}
 	headers.Add("Accept", "application/json")
-	createTokenParams["device_code"] = ghub.device.DeviceCode
+	params := map[string]string{
+		"client_id":   ghub.endpoints.clientId,
+		"grant_type":  githubGrantType,
+		"device_code": ghub.device.DeviceCode,
+	}
 
-	url, err := common.ConstructURL(githubBaseUrl, createTokenParams, createTokenUris...)
+	url, err := common.ConstructURL(ghub.endpoints.accessTokenUrl, params)
 	if err != nil {
 		return res, err
 	}
 
-	_, data, err := common.Request("POST", url, nil, headers)
+	_, data, err := ghub.opts.request("POST", url, nil, headers)
 	if err != nil {
 		return res, err
 	}
@@ -254,12 +448,201 @@ func onCreateTokenError(data []byte) createTokenErr {
 	return res
 }
 
-// @TODO Authenticated func should have a more intelligent way to validate an access token
+const (
+	githubScopesHeader     = "X-OAuth-Scopes"
+	githubExpirationHeader = "Github-Authentication-Token-Expiration"
+	// message of the 403 response to installation tokens, which are not allowed to read the user
+	githubIntegrationMessage = "Resource not accessible by integration"
+)
+
+// layouts used by the github-authentication-token-expiration header
+var githubExpirationLayouts = []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"}
+
+type githubUserResponse struct {
+	Login string `json:"login"`
+}
+
+// Validate requests the authenticated user, which verifies that the access token has not been
+// revoked. Classic and OAuth app tokens must be granted the repo or public_repo scope. Fine-grained
+// tokens do not report scopes. The installation tokens of GitHub apps, such as the GITHUB_TOKEN of
+// Actions, are valid but can not read the user, so their owner is empty. Tokens that expire are refreshed when a refresh token is available
+// and the expiration date, of tokens stored by <issue-summoner authorize>, is recorded in config.json
+func (ghub *githubManager) Validate() (TokenInfo, error) {
+	info := TokenInfo{}
+	if ghub.token == "" {
+		return info, ghub.authError("for " + ghub.endpoints.host + " was not found")
+	}
+
+	if ghub.source == TokenSourceConfig && ghub.hostConfig().Auth.Expired() {
+		if err := ghub.refresh(); err != nil {
+			return info, err
+		}
+	}
+
+	resp, data, err := ghub.requestUser()
+	if err != nil {
+		return info, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && ghub.refreshable() {
+		if err := ghub.refresh(); err != nil {
+			return info, err
+		}
+
+		if resp, data, err = ghub.requestUser(); err != nil {
+			return info, err
+		}
+	}
+
+	errRes := onGetIssueError(data)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		break
+	case resp.StatusCode == http.StatusUnauthorized:
+		return info, ghub.authError("for " + ghub.endpoints.host + " is invalid or has been revoked")
+	case resp.StatusCode == http.StatusForbidden && errRes.Message == githubIntegrationMessage:
+		return info, nil
+	default:
+		return info, fmt.Errorf("failed to validate access token: %s with status code: %d", errRes.Message, resp.StatusCode)
+	}
+
+	user := githubUserResponse{}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return info, err
+	}
+	info.Owner = user.Login
+
+	if _, ok := resp.Header[http.CanonicalHeaderKey(githubScopesHeader)]; ok {
+		info.Scopes = parseScopes(resp.Header.Get(githubScopesHeader))
+		if !slices.Contains(info.Scopes, "repo") && !slices.Contains(info.Scopes, "public_repo") {
+			return info, ghub.authError("for " + ghub.endpoints.host + " is missing the repo scope")
+		}
+	}
+
+	if expiration := resp.Header.Get(githubExpirationHeader); expiration != "" {
+		info.ExpiresAt = parseExpiration(expiration)
+	}
+
+	if info.ExpiresAt.IsZero() {
+		return info, nil
+	}
+
+	if time.Now().After(info.ExpiresAt) {
+		return info, ghub.expiredError(info.ExpiresAt)
+	}
+
+	return info, ghub.recordExpiration(info.ExpiresAt)
+}
+
+func (ghub *githubManager) authError(reason string) *AuthError {
+	return &AuthError{Sch: Github, Profile: ghub.profile.Name, Reason: reason}
+}
+
+// expiredError reports that the access token expired, at a date that is unknown when it is zero
+func (ghub *githubManager) expiredError(expiresAt time.Time) *AuthError {
+	if expiresAt.IsZero() {
+		return ghub.authError("for " + ghub.endpoints.host + " has expired")
+	}
+	return ghub.authError(fmt.Sprintf("for %s expired on %s", ghub.endpoints.host, expiresAt.Format(time.DateTime)))
+}
+
 func (ghub *githubManager) Authenticated() bool {
-	if entry, ok := ghub.conf[Github]; ok {
-		return entry.Auth.AccessToken != ""
+	_, err := ghub.Validate()
+	return err == nil
+}
+
+func (ghub *githubManager) requestUser() (*http.Response, []byte, error) {
+	u, err := common.ConstructURL(ghub.endpoints.apiBaseUrl, nil, "user")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ghub.opts.request("GET", u, nil, ghub.headers)
+}
+
+// refreshable reports whether the access token was stored by <issue-summoner authorize>
+// along with a refresh token
+func (ghub *githubManager) refreshable() bool {
+	return ghub.source == TokenSourceConfig && ghub.hostConfig().Auth.RefreshKey != ""
+}
+
+// refresh exchanges the stored refresh token for a new access token. Refresh tokens are
+// issued to GitHub apps that have expiring user tokens enabled.
+func (ghub *githubManager) refresh() error {
+	auth := ghub.hostConfig().Auth
+	expired := ghub.expiredError(auth.ExpiresAt)
+
+	refreshToken, err := auth.RefreshToken()
+	if err != nil || refreshToken == "" {
+		return expired
+	}
+
+	headers := http.Header{}
+	headers.Add("Accept", "application/json")
+	params := map[string]string{
+		"client_id":     ghub.endpoints.clientId,
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}
+
+	url, err := common.ConstructURL(ghub.endpoints.accessTokenUrl, params)
+	if err != nil {
+		return err
+	}
+
+	_, data, err := ghub.opts.request("POST", url, nil, headers)
+	if err != nil {
+		return err
+	}
+
+	if tokenErr := onCreateTokenError(data); tokenErr.Error != "" {
+		expired.Reason += " and could not be refreshed: " + tokenErr.ErrorDesc
+		return expired
+	}
+
+	var res createTokenResponse
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	return ghub.storeToken(res)
+}
+
+// recordExpiration writes the expiration date of a token that was stored by
+// <issue-summoner authorize> to config.json. Tokens from other sources are not recorded.
+func (ghub *githubManager) recordExpiration(expiresAt time.Time) error {
+	if ghub.source != TokenSourceConfig {
+		return nil
+	}
+
+	auth := ghub.hostConfig().Auth
+	if auth.ExpiresAt.Equal(expiresAt) {
+		return nil
+	}
+
+	auth.ExpiresAt = expiresAt
+	return ghub.writeAuth(auth)
+}
+
+// parseScopes splits the comma separated value of the X-OAuth-Scopes header
+func parseScopes(header string) []string {
+	scopes := make([]string, 0)
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
 	}
-	return false
+	return scopes
+}
+
+// parseExpiration returns the zero time when the expiration header cannot be parsed
+func parseExpiration(header string) time.Time {
+	for _, layout := range githubExpirationLayouts {
+		if expiresAt, err := time.Parse(layout, header); err == nil {
+			return expiresAt
+		}
+	}
+	return time.Time{}
 }
 
 type githubReportResponse struct {
@@ -274,6 +657,7 @@ var (
 
 func (ghub *githubManager) Report(issue ReportRequest, res chan ReportResponse) {
 	result := ReportResponse{Index: issue.Index}
+	issue.Labels = mergeLabels(ghub.profile.Config.Labels, issue.Labels)
 
 	data, err := json.Marshal(issue)
 	if err != nil {
@@ -282,8 +666,16 @@ func (ghub *githubManager) Report(issue ReportRequest, res chan ReportResponse)
 		return
 	}
 
+	if skip, err := ghub.opts.dryRun("POST", ghub.reportURL, data); skip || err != nil {
+		if err != nil {
+			result.Err = fmt.Errorf(errReport, issue.Title, err)
+		}
+		res <- result
+		return
+	}
+
 	body := bytes.NewBuffer(data)
-	resp, data, err := common.Request("POST", ghub.reportURL, body, ghub.headers)
+	resp, data, err := ghub.opts.request("POST", ghub.reportURL, body, ghub.headers)
 	if err != nil {
 		result.Err = fmt.Errorf(errReport, issue.Title, err)
 		res <- result
@@ -346,7 +738,7 @@ func (ghub *githubManager) GetStatus(issueNum, index int, status chan StatusResp
 		return
 	}
 
-	resp, data, err := common.Request("GET", url, nil, ghub.headers)
+	resp, data, err := ghub.opts.request("GET", url, nil, ghub.headers)
 	if err != nil {
 		res.Err = err
 		status <- res
@@ -373,10 +765,93 @@ func (ghub *githubManager) GetStatus(issueNum, index int, status chan StatusResp
 	status <- res
 }
 
+type githubCloseRequest struct {
+	State       string `json:"state"`
+	StateReason string `json:"state_reason"`
+}
+
+// Close closes the issue as not planned. Github does not allow OAuth apps to delete issues.
+func (ghub *githubManager) Close(issueNum int) error {
+	url, err := ghub.constructStatusURL(issueNum)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(githubCloseRequest{State: "closed", StateReason: "not_planned"})
+	if err != nil {
+		return err
+	}
+
+	resp, data, err := ghub.opts.request("PATCH", url, bytes.NewBuffer(data), ghub.headers)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errRes := onGetIssueError(data)
+		return fmt.Errorf("failed to close issue #%d: %s with status code: %d", issueNum, errRes.Message, resp.StatusCode)
+	}
+
+	return nil
+}
+
+const githubPageSize = 100
+
+type githubListIssue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	PullRequest any    `json:"pull_request"` // set when the issue is a pull request
+}
+
+// ListOpen requests every open issue of the repository, one page at a time. The issues
+// endpoint of the github api also returns pull requests, which are skipped.
+func (ghub *githubManager) ListOpen() ([]RemoteIssue, error) {
+	open := make([]RemoteIssue, 0)
+
+	for page := 1; ; page++ {
+		params := map[string]string{
+			"state":    "open",
+			"per_page": strconv.Itoa(githubPageSize),
+			"page":     strconv.Itoa(page),
+		}
+
+		u, err := common.ConstructURL(ghub.reportURL, params)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, data, err := ghub.opts.request("GET", u, nil, ghub.headers)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			errRes := onGetIssueError(data)
+			return nil, fmt.Errorf("failed to list open issues: %s with status code: %d", errRes.Message, resp.StatusCode)
+		}
+
+		issues := make([]githubListIssue, 0)
+		if err := json.Unmarshal(data, &issues); err != nil {
+			return nil, err
+		}
+
+		for _, issue := range issues {
+			if issue.PullRequest == nil {
+				open = append(open, RemoteIssue{Number: issue.Number, Title: issue.Title, Body: issue.Body})
+			}
+		}
+
+		if len(issues) < githubPageSize {
+			return open, nil
+		}
+	}
+}
+
 func (ghub *githubManager) constructStatusURL(issueNum int) (string, error) {
 	path := fmt.Sprintf("/repos/%s/%s/issues/%d", ghub.repo.UserName, ghub.repo.RepoName, issueNum)
 
-	u, err := common.ConstructURL(githubApiBaseUrl, nil, path)
+	u, err := common.ConstructURL(ghub.endpoints.apiBaseUrl, nil, path)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,417 @@
+package git_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git/gittest"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeGithubHost = "git.test"
+
+// newFakeGithub starts a fake github server that accepts the tokens used by the tests below
+func newFakeGithub(t *testing.T) *gittest.Github {
+	srv := gittest.NewGithub(t)
+	srv.Login = "AntoninoAdornetto"
+
+	srv.AddToken("classic-token", gittest.Token{
+		Scopes:    []string{"repo", "read:org"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	srv.AddToken("fine-grained-token", gittest.Token{})
+	srv.AddToken("no-scope-token", gittest.Token{Scopes: []string{"read:org"}})
+	srv.AddToken("installation-token", gittest.Token{Installation: true})
+	srv.AddToken("expired-token", gittest.Token{
+		Scopes:    []string{"repo"},
+		ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	srv.AddRefreshToken("refresh-token", "refreshed-token")
+	return srv
+}
+
+// writeFakeGithubConfig points the fake github host at the server
+func writeFakeGithubConfig(t *testing.T, srv *gittest.Github, auth common.AuthConfig) {
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+
+	conf[git.Github] = common.IssueSummonerConfig{
+		Hosts: map[string]common.HostConfig{
+			fakeGithubHost: {
+				BaseUrl:    srv.URL,
+				ApiBaseUrl: srv.URL,
+				ClientID:   "client-id",
+				Auth:       auth,
+			},
+		},
+	}
+	require.NoError(t, common.WriteToConfig(conf))
+}
+
+func TestGithubEnterpriseAuthorizeRequiresClientID(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	repo, err := git.NewRepository(newTestRepository(t, "git@git.corp.example:AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{})
+	require.NoError(t, err)
+	require.False(t, manager.Authenticated())
+	require.ErrorContains(t, manager.Authorize(), "hosts.git.corp.example.clientId")
+}
+
+func TestGithubValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		token     string
+		owner     string
+		scopes    []string
+		expires   bool
+		authErr   bool
+		errSubstr string
+	}{
+		{
+			name:    "Should validate classic tokens with the repo scope",
+			token:   "classic-token",
+			owner:   "AntoninoAdornetto",
+			scopes:  []string{"repo", "read:org"},
+			expires: true,
+		},
+		{
+			name:  "Should validate fine-grained tokens that do not report scopes",
+			token: "fine-grained-token",
+			owner: "AntoninoAdornetto",
+		},
+		{
+			name:  "Should validate installation tokens that can not read the user",
+			token: "installation-token",
+		},
+		{
+			name:      "Should reject tokens without the repo scope",
+			token:     "no-scope-token",
+			authErr:   true,
+			errSubstr: "missing the repo scope",
+		},
+		{
+			name:      "Should reject expired tokens",
+			token:     "expired-token",
+			authErr:   true,
+			errSubstr: "expired on",
+		},
+		{
+			name:      "Should reject revoked tokens",
+			token:     "revoked-token",
+			authErr:   true,
+			errSubstr: "revoked",
+		},
+		{
+			name:      "Should reject missing tokens",
+			authErr:   true,
+			errSubstr: "was not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setTestConfigDir(t)
+			t.Setenv("GITHUB_TOKEN", "")
+			t.Setenv(git.HostTokenEnv(fakeGithubHost), tc.token)
+
+			srv := newFakeGithub(t)
+			writeFakeGithubConfig(t, srv, common.AuthConfig{})
+
+			repo, err := git.NewRepository(newTestRepository(t, "git@git.test:AntoninoAdornetto/issue-summoner.git"))
+			require.NoError(t, err)
+
+			manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{})
+			require.NoError(t, err)
+
+			info, err := manager.Validate()
+			if tc.authErr {
+				var authErr *git.AuthError
+				require.True(t, errors.As(err, &authErr))
+				require.ErrorContains(t, err, tc.errSubstr)
+				require.ErrorContains(t, err, "re-run <issue-summoner authorize -s github>")
+				require.False(t, manager.Authenticated())
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, manager.Authenticated())
+			require.Equal(t, tc.owner, info.Owner)
+			require.Equal(t, tc.expires, !info.ExpiresAt.IsZero())
+			if tc.scopes != nil {
+				require.Equal(t, tc.scopes, info.Scopes)
+			}
+		})
+	}
+}
+
+func TestGithubValidateRecordsExpiration(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	srv := newFakeGithub(t)
+	auth, err := common.NewAuthConfig(common.CredentialKey(git.Github, fakeGithubHost), "classic-token")
+	require.NoError(t, err)
+	writeFakeGithubConfig(t, srv, auth)
+
+	repo, err := git.NewRepository(newTestRepository(t, "git@git.test:AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{})
+	require.NoError(t, err)
+
+	info, err := manager.Validate()
+	require.NoError(t, err)
+
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+	require.True(t, info.ExpiresAt.Equal(conf[git.Github].Hosts[fakeGithubHost].Auth.ExpiresAt))
+}
+
+func TestGithubValidateRefreshesExpiredTokens(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	srv := newFakeGithub(t)
+	auth, err := common.NewAuthConfig(common.CredentialKey(git.Github, fakeGithubHost), "stale-token")
+	require.NoError(t, err)
+	auth.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, auth.SetRefreshToken("refresh-token"))
+
+	writeFakeGithubConfig(t, srv, auth)
+
+	repo, err := git.NewRepository(newTestRepository(t, "git@git.test:AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{})
+	require.NoError(t, err)
+
+	info, err := manager.Validate()
+	require.NoError(t, err)
+	require.Equal(t, "AntoninoAdornetto", info.Owner)
+
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+
+	refreshed := conf[git.Github].Hosts[fakeGithubHost].Auth
+	require.False(t, refreshed.Expired())
+
+	token, err := refreshed.Token()
+	require.NoError(t, err)
+	require.Equal(t, "refreshed-token", token)
+}
+
+func TestGithubAuthorizeDeviceFlow(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	srv := gittest.NewGithub(t)
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	opts := git.ManagerOptions{BaseUrl: srv.URL, ApiBaseUrl: srv.URL, OpenBrowser: srv.OpenBrowser}
+	manager, err := git.NewGitManager(git.Github, repo, opts)
+	require.NoError(t, err)
+	require.False(t, manager.Authenticated())
+	require.NoError(t, manager.Authorize())
+
+	info, err := manager.Validate()
+	require.NoError(t, err)
+	require.Equal(t, srv.Login, info.Owner)
+	require.Equal(t, []string{"repo"}, info.Scopes)
+
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+	require.True(t, conf[git.Github].Hosts["github.com"].Auth.Stored())
+}
+
+func TestGithubReportAndPurge(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "classic-token")
+
+	srv := newFakeGithub(t)
+	workTree := newTestRepository(t, "git@github.com:acme/api.git")
+	src := "package main\n\n// @E2E_FIXME first issue\nfunc main() {}\n\n// @E2E_FIXME second issue\nfunc run() {}\n"
+	path := filepath.Join(workTree, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+
+	repo, err := git.NewRepository(workTree)
+	require.NoError(t, err)
+
+	client := common.NewClient()
+	client.BaseDelay = time.Millisecond
+	client.MaxDelay = time.Millisecond
+	opts := git.ManagerOptions{ApiBaseUrl: srv.URL, Client: client}
+
+	manager, err := git.NewGitManager(git.Github, repo, opts)
+	require.NoError(t, err)
+
+	reporter, err := issue.NewIssueManager([]byte("@E2E_FIXME"), issue.IssueModeReport)
+	require.NoError(t, err)
+	require.NoError(t, reporter.Walk(workTree))
+	require.Len(t, reporter.Issues, 2)
+
+	// issues are created again after rate limits, but not after server errors, see TestGithubReportServerError
+	srv.RateLimitNext(1, 0)
+
+	reported := make(chan git.ReportResponse, len(reporter.Issues))
+	for _, toReport := range reporter.Issues {
+		manager.Report(git.ReportRequest{Title: toReport.Title, Body: toReport.Body, Index: toReport.Index}, reported)
+	}
+	close(reported)
+
+	for r := range reported {
+		require.NoError(t, r.Err)
+		require.NoError(t, reporter.Group(r.Index, r.ID))
+	}
+
+	for pathKey := range reporter.IssueMap {
+		require.NoError(t, reporter.WriteIssues(pathKey))
+	}
+
+	issues := srv.Issues("acme", "api")
+	require.Len(t, issues, 2)
+	require.Equal(t, "first issue", issues[0].Title)
+
+	fingerprint, ok := common.ParseFingerprint(issues[0].Body)
+	require.True(t, ok)
+	require.Equal(t, reporter.Issues[0].Fingerprint, fingerprint)
+	require.Equal(t, 3, srv.Requests())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "@E2E_FIXME(#1) first issue")
+	require.Contains(t, string(data), "@E2E_FIXME(#2) second issue")
+
+	srv.CloseIssue("acme", "api", 1)
+
+	purger, err := issue.NewIssueManager([]byte("@E2E_FIXME"), issue.IssueModePurge)
+	require.NoError(t, err)
+	require.NoError(t, purger.Walk(workTree))
+	require.Len(t, purger.Issues, 2)
+
+	// reading the status of an issue is retried after server errors
+	srv.FailNext(1, http.StatusBadGateway, "Server Error")
+
+	statuses := make(chan git.StatusResponse, len(purger.Issues))
+	for _, toCheck := range purger.Issues {
+		manager.GetStatus(toCheck.Comment.IssueNumber, toCheck.Index, statuses)
+	}
+	close(statuses)
+
+	for s := range statuses {
+		require.NoError(t, s.Err)
+		if s.Resolved {
+			require.NoError(t, purger.Group(s.Index, purger.Issues[s.Index].Comment.IssueNumber))
+		}
+	}
+
+	for pathKey := range purger.IssueMap {
+		require.NoError(t, purger.Purge(pathKey))
+	}
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "first issue")
+	require.Contains(t, string(data), "@E2E_FIXME(#2) second issue")
+
+	require.NoError(t, manager.Close(2))
+	require.Equal(t, "closed", srv.Issues("acme", "api")[1].State)
+	require.ErrorContains(t, manager.Close(3), "status code: 404")
+}
+
+func TestGithubReportServerError(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "classic-token")
+
+	srv := newFakeGithub(t)
+	repo, err := git.NewRepository(newTestRepository(t, "git@github.com:acme/api.git"))
+	require.NoError(t, err)
+
+	client := common.NewClient()
+	client.BaseDelay = time.Millisecond
+	client.MaxDelay = time.Millisecond
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{ApiBaseUrl: srv.URL, Client: client})
+	require.NoError(t, err)
+
+	// the issue may have been created before the server failed, a retry could create it twice
+	srv.FailNext(1, http.StatusBadGateway, "Server Error")
+
+	reported := make(chan git.ReportResponse, 1)
+	manager.Report(git.ReportRequest{Title: "first issue"}, reported)
+	require.ErrorContains(t, (<-reported).Err, "status code: 502")
+	require.Equal(t, 1, srv.Requests())
+}
+
+func TestGithubListOpen(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "classic-token")
+
+	srv := newFakeGithub(t)
+	repo, err := git.NewRepository(newTestRepository(t, "git@github.com:acme/api.git"))
+	require.NoError(t, err)
+
+	// more issues than fit on a single page
+	for i := 0; i < 105; i++ {
+		srv.AddIssue("acme", "api", gittest.Issue{Title: fmt.Sprintf("issue %d", i+1)})
+	}
+	srv.CloseIssue("acme", "api", 3)
+
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{ApiBaseUrl: srv.URL})
+	require.NoError(t, err)
+
+	open, err := manager.ListOpen()
+	require.NoError(t, err)
+	require.Len(t, open, 104)
+	require.Equal(t, "issue 105", open[103].Title)
+
+	duplicate, found := git.FindDuplicate(open, "Issue 42", common.Fingerprint{})
+	require.True(t, found)
+	require.Equal(t, 42, duplicate.Number)
+
+	_, found = git.FindDuplicate(open, "issue 3", common.Fingerprint{})
+	require.False(t, found)
+}
+
+func TestGithubReportDryRun(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "classic-token")
+
+	srv := newFakeGithub(t)
+	repo, err := git.NewRepository(newTestRepository(t, "git@github.com:acme/api.git"))
+	require.NoError(t, err)
+
+	out := bytes.Buffer{}
+	opts := git.ManagerOptions{ApiBaseUrl: srv.URL, DryRun: &out}
+	manager, err := git.NewGitManager(git.Github, repo, opts)
+	require.NoError(t, err)
+
+	res := make(chan git.ReportResponse, 1)
+	manager.Report(git.ReportRequest{Title: "dry run", Body: "body", Labels: []string{"ci"}, Assignees: []string{"octocat"}, Index: 3}, res)
+
+	reported := <-res
+	require.NoError(t, reported.Err)
+	require.Equal(t, 0, reported.ID)
+	require.Equal(t, 3, reported.Index)
+	require.Empty(t, srv.Issues("acme", "api"))
+	require.Equal(t, 0, srv.Requests())
+
+	require.True(t, strings.HasPrefix(out.String(), "POST "+srv.URL+"/repos/acme/api/issues\n"))
+	require.Contains(t, out.String(), `"title": "dry run"`)
+	require.Contains(t, out.String(), `"ci"`)
+	require.Contains(t, out.String(), `"assignees": [`)
+	require.Contains(t, out.String(), `"octocat"`)
+}
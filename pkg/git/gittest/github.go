@@ -0,0 +1,438 @@
+// Package gittest provides in-process fake source code hosts for testing code that
+// depends on a [git.GitManager] without sending requests over the internet.
+package gittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Token describes an access token that is accepted by the fake server
+type Token struct {
+	Login     string    // defaults to [Github.Login]
+	Scopes    []string  // sent in the X-OAuth-Scopes header. nil for fine-grained tokens
+	ExpiresAt time.Time // sent in the GitHub-Authentication-Token-Expiration header when set
+	// Installation tokens of GitHub apps, such as the GITHUB_TOKEN of Actions, are rejected by the
+	// user endpoint with a 403 status code
+	Installation bool
+}
+
+// Issue is an issue that was created on the fake server
+type Issue struct {
+	Number int      `json:"number"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels"`
+	State  string   `json:"state"` // open or closed
+}
+
+// Github is a fake github server that implements the endpoints used by the github
+// [git.GitManager]: the device flow, token refreshes, the authenticated user and issues.
+// Requests to the api must send a token that was added with [Github.AddToken], or one
+// that was issued by the device flow or a refresh.
+type Github struct {
+	*httptest.Server
+	Login string // login of tokens that do not set one, i.e. tokens issued by the device flow
+
+	mu            sync.Mutex
+	tokens        map[string]Token
+	refreshTokens map[string]string   // refresh token -> access token that is issued
+	issues        map[string][]*Issue // owner/name -> issues
+	devices       map[string]bool     // device code -> approved
+	failures      []func(w http.ResponseWriter)
+	codes         int // device codes that were issued
+	issued        int // access tokens that were issued by the device flow
+	requests      int
+}
+
+// NewGithub starts a fake github server that is closed when the test finishes
+func NewGithub(t testing.TB) *Github {
+	g := &Github{
+		Login:         "octocat",
+		tokens:        make(map[string]Token),
+		refreshTokens: make(map[string]string),
+		issues:        make(map[string][]*Issue),
+		devices:       make(map[string]bool),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login/device/code", g.handleDeviceCode)
+	mux.HandleFunc("GET /login/device", g.handleVerification)
+	mux.HandleFunc("POST /login/oauth/access_token", g.handleAccessToken)
+	mux.HandleFunc("GET /user", g.authenticated(g.handleUser))
+	mux.HandleFunc("POST /repos/{owner}/{repo}/issues", g.authenticated(g.handleCreateIssue))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues", g.authenticated(g.handleListIssues))
+	mux.HandleFunc("GET /repos/{owner}/{repo}/issues/{number}", g.authenticated(g.handleGetIssue))
+	mux.HandleFunc("PATCH /repos/{owner}/{repo}/issues/{number}", g.authenticated(g.handleUpdateIssue))
+
+	g.Server = httptest.NewServer(g.intercept(mux))
+	t.Cleanup(g.Close)
+	return g
+}
+
+// AddIssue creates an issue, as if it was reported by hand, and returns its number
+func (g *Github) AddIssue(owner, repo string, issue Issue) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := owner + "/" + repo
+	issue.Number = len(g.issues[key]) + 1
+	if issue.State == "" {
+		issue.State = "open"
+	}
+
+	g.issues[key] = append(g.issues[key], &issue)
+	return issue.Number
+}
+
+// AddToken registers an access token that is accepted by the api
+func (g *Github) AddToken(token string, info Token) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tokens[token] = info
+}
+
+// AddRefreshToken registers a refresh token that is exchanged for [accessToken]
+func (g *Github) AddRefreshToken(refreshToken, accessToken string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refreshTokens[refreshToken] = accessToken
+}
+
+// Issues returns a copy of the issues that were created for the repository
+func (g *Github) Issues(owner, repo string) []Issue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	issues := make([]Issue, 0, len(g.issues[owner+"/"+repo]))
+	for _, issue := range g.issues[owner+"/"+repo] {
+		issues = append(issues, *issue)
+	}
+	return issues
+}
+
+// CloseIssue marks the issue as closed, which causes it to be purged by <issue-summoner scan -m purge>
+func (g *Github) CloseIssue(owner, repo string, number int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if issue := g.findIssue(owner, repo, number); issue != nil {
+		issue.State = "closed"
+	}
+}
+
+// Requests returns the number of requests that were received, including failed requests
+func (g *Github) Requests() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.requests
+}
+
+// FailNext responds to the next [n] requests with the status code and message
+func (g *Github) FailNext(n, status int, message string) {
+	g.queueFailures(n, func(w http.ResponseWriter) {
+		writeJSON(w, status, map[string]string{"message": message})
+	})
+}
+
+// RateLimitNext responds to the next [n] requests with a secondary rate limit, which
+// github sends with a 403 status code and a Retry-After header
+func (g *Github) RateLimitNext(n int, retryAfter time.Duration) {
+	g.queueFailures(n, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again.",
+		})
+	})
+}
+
+// OpenBrowser approves every pending device code, as if the user visited the verification
+// page and entered their user code. It can be used as [git.ManagerOptions.OpenBrowser].
+func (g *Github) OpenBrowser(url string) error {
+	resp, err := g.Client().Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (g *Github) queueFailures(n int, fail func(w http.ResponseWriter)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := 0; i < n; i++ {
+		g.failures = append(g.failures, fail)
+	}
+}
+
+// intercept counts requests and responds with queued failures
+func (g *Github) intercept(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		g.requests++
+		var fail func(w http.ResponseWriter)
+		if len(g.failures) > 0 {
+			fail, g.failures = g.failures[0], g.failures[1:]
+		}
+		g.mu.Unlock()
+
+		if fail != nil {
+			fail(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type tokenHandler func(w http.ResponseWriter, r *http.Request, token Token)
+
+// authenticated rejects requests that do not send a known access token. Expired tokens
+// are accepted by the user endpoint, which reports the expiration date, like github does
+// for tokens that expire while a request is in flight.
+func (g *Github) authenticated(next tokenHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g.mu.Lock()
+		token, ok := g.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		g.mu.Unlock()
+
+		expired := !token.ExpiresAt.IsZero() && time.Now().After(token.ExpiresAt)
+		if !ok || (expired && r.URL.Path != "/user") {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+			return
+		}
+
+		next(w, r, token)
+	}
+}
+
+func (g *Github) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.codes++
+	code := fmt.Sprintf("device-code-%d", g.codes)
+	g.devices[code] = false
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":      code,
+		"user_code":        "ABCD-1234",
+		"verification_uri": g.URL + "/login/device",
+		"expires_in":       900,
+		"interval":         0,
+	})
+}
+
+func (g *Github) handleVerification(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for code := range g.devices {
+		g.devices[code] = true
+	}
+}
+
+func (g *Github) handleAccessToken(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch query.Get("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		approved, ok := g.devices[query.Get("device_code")]
+		if !ok {
+			writeJSON(w, http.StatusOK, tokenError("incorrect_device_code", "The device_code provided is not valid"))
+			return
+		}
+
+		if !approved {
+			writeJSON(w, http.StatusOK, tokenError("authorization_pending", "The authorization request is still pending"))
+			return
+		}
+
+		delete(g.devices, query.Get("device_code"))
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token": g.issueToken(),
+			"token_type":   "bearer",
+			"scope":        "repo",
+		})
+	case "refresh_token":
+		accessToken, ok := g.refreshTokens[query.Get("refresh_token")]
+		if !ok {
+			writeJSON(w, http.StatusOK, tokenError("bad_refresh_token", "The refresh token is invalid"))
+			return
+		}
+
+		g.tokens[accessToken] = Token{}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  accessToken,
+			"expires_in":    28800,
+			"refresh_token": query.Get("refresh_token"),
+		})
+	default:
+		writeJSON(w, http.StatusOK, tokenError("unsupported_grant_type", "The grant type is not supported"))
+	}
+}
+
+// issueToken registers a new access token with the repo scope. The caller must hold the lock.
+func (g *Github) issueToken() string {
+	g.issued++
+	token := fmt.Sprintf("device-token-%d", g.issued)
+	g.tokens[token] = Token{Scopes: []string{"repo"}}
+	return token
+}
+
+func (g *Github) handleUser(w http.ResponseWriter, r *http.Request, token Token) {
+	if token.Installation {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Resource not accessible by integration"})
+		return
+	}
+
+	if token.Scopes != nil {
+		w.Header().Set("X-OAuth-Scopes", strings.Join(token.Scopes, ", "))
+	}
+
+	if !token.ExpiresAt.IsZero() {
+		w.Header().Set("GitHub-Authentication-Token-Expiration", token.ExpiresAt.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+
+	login := token.Login
+	if login == "" {
+		login = g.Login
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"login": login})
+}
+
+func (g *Github) handleCreateIssue(w http.ResponseWriter, r *http.Request, _ Token) {
+	issue := &Issue{}
+	if err := json.NewDecoder(r.Body).Decode(issue); err != nil || issue.Title == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+
+	key := r.PathValue("owner") + "/" + r.PathValue("repo")
+
+	g.mu.Lock()
+	issue.Number = len(g.issues[key]) + 1
+	issue.State = "open"
+	g.issues[key] = append(g.issues[key], issue)
+	created := *issue
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":     1000 + created.Number,
+		"number": created.Number,
+		"url":    fmt.Sprintf("%s/repos/%s/issues/%d", g.URL, key, created.Number),
+		"title":  created.Title,
+		"state":  created.State,
+	})
+}
+
+// handleListIssues supports the state, per_page and page query parameters
+func (g *Github) handleListIssues(w http.ResponseWriter, r *http.Request, _ Token) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	if state == "" {
+		state = "open"
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 30
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	g.mu.Lock()
+	matches := make([]Issue, 0)
+	for _, issue := range g.issues[r.PathValue("owner")+"/"+r.PathValue("repo")] {
+		if state == "all" || issue.State == state {
+			matches = append(matches, *issue)
+		}
+	}
+	g.mu.Unlock()
+
+	start := min((page-1)*perPage, len(matches))
+	end := min(start+perPage, len(matches))
+	writeJSON(w, http.StatusOK, matches[start:end])
+}
+
+func (g *Github) handleGetIssue(w http.ResponseWriter, r *http.Request, _ Token) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	g.mu.Lock()
+	issue := g.findIssue(r.PathValue("owner"), r.PathValue("repo"), number)
+	var found Issue
+	if issue != nil {
+		found = *issue
+	}
+	g.mu.Unlock()
+
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, found)
+}
+
+func (g *Github) handleUpdateIssue(w http.ResponseWriter, r *http.Request, _ Token) {
+	var update struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+
+	number, _ := strconv.Atoi(r.PathValue("number"))
+
+	g.mu.Lock()
+	issue := g.findIssue(r.PathValue("owner"), r.PathValue("repo"), number)
+	var updated Issue
+	if issue != nil {
+		if update.State != "" {
+			issue.State = update.State
+		}
+		updated = *issue
+	}
+	g.mu.Unlock()
+
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// findIssue returns nil when the issue does not exist. The caller must hold the lock.
+func (g *Github) findIssue(owner, repo string, number int) *Issue {
+	issues := g.issues[owner+"/"+repo]
+	if number < 1 || number > len(issues) {
+		return nil
+	}
+	return issues[number-1]
+}
+
+func tokenError(code, desc string) map[string]string {
+	return map[string]string{"error": code, "error_description": desc}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
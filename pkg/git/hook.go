@@ -0,0 +1,64 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	HookPreCommit = "pre-commit"
+	HookCommitMsg = "commit-msg"
+)
+
+// HookMarker identifies the hooks that were written by <issue-summoner hook install>. Hooks
+// without the marker are not overwritten unless they are forced.
+const HookMarker = "# installed by issue-summoner"
+
+// ErrHookExists is returned when a hook that was not installed by issue summoner exists
+var ErrHookExists = errors.New("hook already exists")
+
+// InstallHook writes an executable hook script to the hooks directory of the repository.
+// Hooks that were written by a previous install are replaced. The path of the hook is returned.
+func (repo *Repository) InstallHook(name, script string, force bool) (string, error) {
+	dir := filepath.Join(repo.Dir, "hooks")
+	path := filepath.Join(dir, name)
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && !force && !bytes.Contains(existing, []byte(HookMarker)):
+		return path, fmt.Errorf("%w: %s", ErrHookExists, path)
+	case err != nil && !os.IsNotExist(err):
+		return path, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return path, err
+	}
+
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return path, err
+	}
+
+	// the permissions of existing files are not changed by WriteFile
+	return path, os.Chmod(path, 0755)
+}
+
+// AddTrailers appends trailers, such as "Refs: #12", to a commit message file. Trailers that the
+// message already contains are not repeated.
+func (repo *Repository) AddTrailers(ctx context.Context, file string, trailers []string) error {
+	if len(trailers) == 0 {
+		return nil
+	}
+
+	args := []string{"interpret-trailers", "--in-place", "--if-exists", "addIfDifferent"}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+
+	_, err := repo.git(ctx, append(args, file)...)
+	return err
+}
@@ -0,0 +1,101 @@
+package git_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryInstallHook(t *testing.T) {
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/acme/api.git"))
+	require.NoError(t, err)
+
+	script := "#!/bin/sh\n" + git.HookMarker + "\nexec issue-summoner scan --staged\n"
+	path, err := repo.InstallHook(git.HookPreCommit, script, false)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(repo.Dir, "hooks", git.HookPreCommit), path)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&0100, "the hook must be executable")
+
+	// hooks that were installed by issue summoner are replaced
+	_, err = repo.InstallHook(git.HookPreCommit, script+"# updated\n", false)
+	require.NoError(t, err)
+
+	// hooks of other tools are only replaced when forced
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0644))
+	_, err = repo.InstallHook(git.HookPreCommit, script, false)
+	require.ErrorIs(t, err, git.ErrHookExists)
+
+	_, err = repo.InstallHook(git.HookPreCommit, script, true)
+	require.NoError(t, err)
+
+	installed, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, script, string(installed))
+}
+
+func TestRepositoryStagedFiles(t *testing.T) {
+	dir := newGitRepository(t)
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	write("main.go", "package main\n")
+	write("old.go", "package main\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	write("main.go", "package main\n\n// staged\n")
+	write("new.go", "package main\n")
+	runGit(t, dir, "add", "main.go", "new.go")
+	runGit(t, dir, "rm", "-q", "old.go")
+	write("main.go", "package main\n\n// not staged\n")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	files, err := repo.StagedFiles(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []git.StagedFile{
+		{Path: "main.go"},
+		{Path: "new.go"},
+		{Path: "old.go", Deleted: true},
+	}, files)
+
+	entries, err := repo.StagedEntries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	staged, err := repo.ReadStaged(entries, "main.go")
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\n// staged\n", string(staged))
+
+	committed, err := repo.ReadCommitted("HEAD", "old.go")
+	require.NoError(t, err)
+	require.Equal(t, "package main\n", string(committed))
+
+	_, err = repo.ReadStaged(entries, "old.go")
+	require.Error(t, err)
+
+	// only the staged lines are added, the unstaged edit of main.go is not. new.go has the contents
+	// of old.go, so it was renamed rather than added
+	added, err := repo.AddedStaged(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, git.Changes{"main.go": {{Start: 2, End: 3}}}, added)
+
+	// submodules are staged as commits that are not in the object database
+	runGit(t, dir, "update-index", "--add", "--cacheinfo", "160000,8582c19b3a3f1f1a4a5c5d2e0d6b1f2a3c4d5e6f,vendor/lib")
+	files, err = repo.StagedFiles(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, files, git.StagedFile{Path: "vendor/lib"})
+
+	entries, err = repo.StagedEntries()
+	require.NoError(t, err)
+	require.NotContains(t, entries, "vendor/lib", "submodules are not source files")
+}
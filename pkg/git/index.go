@@ -0,0 +1,196 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	indexSignature = "DIRC"
+	indexHeaderLen = 12
+	// ctime, mtime, dev, ino, mode, uid, gid and size are 4 bytes each, followed by the hash and flags
+	indexEntryLen = 40 + sha1.Size + 2
+)
+
+const (
+	indexFlagExtended = 0x4000
+	indexFlagStage    = 0x3000
+	indexFlagNameMask = 0x0fff
+)
+
+// file modes of index entries, directories are only present in sparse indexes
+const (
+	ModeFile       = 0o100644
+	ModeExecutable = 0o100755
+	ModeSymlink    = 0o120000
+	ModeSubmodule  = 0o160000
+	ModeDir        = 0o040000
+)
+
+// Hash is the SHA-1 name of an object
+type Hash [sha1.Size]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// IndexEntry is a path that is tracked by git, along with the blob that is staged for it
+type IndexEntry struct {
+	Path  string // relative to the work tree, separated by forward slashes
+	Mode  uint32
+	Hash  Hash
+	Size  uint32 // size of the file in the work tree when it was staged, truncated to 32 bits
+	Stage int    // 0 unless the path has merge conflicts, see <git ls-files --stage>
+}
+
+// Regular reports whether the entry is a file, rather than a symlink, submodule or sparse directory
+func (entry IndexEntry) Regular() bool {
+	return entry.Mode == ModeFile || entry.Mode == ModeExecutable
+}
+
+// ReadIndex parses the index file (.git/index), which contains every path that is tracked by git in
+// the order of their paths. Versions 2, 3 and 4 are supported. An empty index is returned for
+// repositories without any staged files.
+func (repo *Repository) ReadIndex() ([]IndexEntry, error) {
+	data, err := os.ReadFile(filepath.Join(repo.Dir, "index"))
+	if errors.Is(err, os.ErrNotExist) {
+		return []IndexEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return parseIndex(data)
+}
+
+func parseIndex(data []byte) ([]IndexEntry, error) {
+	if len(data) < indexHeaderLen+sha1.Size || string(data[:4]) != indexSignature {
+		return nil, errors.New("invalid index file: missing signature")
+	}
+
+	// the index ends with the checksum of its contents, which is zero when index.skipHash is set
+	body, checksum := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if sum := sha1.Sum(body); !bytes.Equal(sum[:], checksum) && Hash(checksum) != (Hash{}) {
+		return nil, errors.New("invalid index file: checksum mismatch")
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("index version %d is unsupported. Expected version 2, 3 or 4", version)
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+	entries := make([]IndexEntry, 0, count)
+	offset, prev := indexHeaderLen, ""
+
+	for i := uint32(0); i < count; i++ {
+		entry, n, err := parseIndexEntry(body[offset:], version, prev)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index file: entry %d: %w", i, err)
+		}
+
+		entries = append(entries, entry)
+		offset += n
+		prev = entry.Path
+	}
+
+	// extensions follow the entries, each with a signature and the size of its data
+	for offset+8 <= len(body) {
+		signature := string(body[offset : offset+4])
+		if signature == "link" {
+			return nil, errors.New("split index files are unsupported. Run git update-index --no-split-index")
+		}
+		offset += 8 + int(binary.BigEndian.Uint32(body[offset+4:offset+8]))
+	}
+
+	return entries, nil
+}
+
+// parseIndexEntry parses a single entry and returns the number of bytes that it occupies
+func parseIndexEntry(data []byte, version uint32, prev string) (IndexEntry, int, error) {
+	if len(data) < indexEntryLen {
+		return IndexEntry{}, 0, io.ErrUnexpectedEOF
+	}
+
+	entry := IndexEntry{
+		Mode: binary.BigEndian.Uint32(data[24:28]),
+		Size: binary.BigEndian.Uint32(data[36:40]),
+	}
+	copy(entry.Hash[:], data[40:40+sha1.Size])
+
+	flags := binary.BigEndian.Uint16(data[40+sha1.Size:])
+	entry.Stage = int(flags&indexFlagStage) >> 12
+	offset := indexEntryLen
+
+	if flags&indexFlagExtended != 0 {
+		if version < 3 {
+			return entry, 0, errors.New("extended flags require index version 3")
+		}
+		offset += 2
+	}
+
+	// paths of version 4 are compressed by removing the bytes that are shared with the previous path
+	prefix := ""
+	if version == 4 {
+		strip, n, err := parseOffset(data[min(offset, len(data)):])
+		if err != nil {
+			return entry, 0, err
+		}
+		if strip > len(prev) {
+			return entry, 0, fmt.Errorf("path prefix of %d bytes exceeds the previous path", strip)
+		}
+		prefix = prev[:len(prev)-strip]
+		offset += n
+	}
+
+	if offset > len(data) {
+		return entry, 0, io.ErrUnexpectedEOF
+	}
+
+	end := bytes.IndexByte(data[offset:], 0)
+	if end < 0 {
+		return entry, 0, io.ErrUnexpectedEOF
+	}
+
+	name := data[offset : offset+end]
+	entry.Path = prefix + string(name)
+	if version < 4 && flags&indexFlagNameMask < indexFlagNameMask && len(name) != int(flags&indexFlagNameMask) {
+		return entry, 0, fmt.Errorf("path %q does not match its length", entry.Path)
+	}
+
+	// entries of versions 2 and 3 are padded with 1-8 null bytes, including the null byte that
+	// terminates the path, to a multiple of 8 bytes
+	offset += end
+	if version < 4 {
+		offset += 8 - offset%8
+	} else {
+		offset++
+	}
+
+	if offset > len(data) {
+		return entry, 0, io.ErrUnexpectedEOF
+	}
+	return entry, offset, nil
+}
+
+// parseOffset parses the variable length integer of index version 4, which adds one to each
+// continuation byte so that every value has a single encoding
+func parseOffset(data []byte) (int, int, error) {
+	value := 0
+	for i, c := range data {
+		if i > 0 {
+			value++
+		}
+		value = value<<7 | int(c&0x7f)
+		if c&0x80 == 0 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, io.ErrUnexpectedEOF
+}
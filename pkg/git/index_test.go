@@ -0,0 +1,132 @@
+package git_test
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+// lsFiles returns the entries of the index according to <git ls-files --stage>
+func lsFiles(t *testing.T, dir string) []git.IndexEntry {
+	out := runGit(t, dir, "ls-files", "--stage", "-z")
+
+	entries := make([]git.IndexEntry, 0)
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\x00"), "\x00") {
+		meta, path, _ := strings.Cut(line, "\t")
+		fields := strings.Fields(meta)
+		require.Len(t, fields, 3)
+
+		mode, err := strconv.ParseUint(fields[0], 8, 32)
+		require.NoError(t, err)
+		stage, err := strconv.Atoi(fields[2])
+		require.NoError(t, err)
+
+		entry := git.IndexEntry{Path: path, Mode: uint32(mode), Stage: stage}
+		hash, err := hex.DecodeString(fields[1])
+		require.NoError(t, err)
+		copy(entry.Hash[:], hash)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRepositoryReadIndex(t *testing.T) {
+	dir := newGitRepository(t)
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("main.go", "package main\n")
+	write("pkg/api/client.go", "package api\n")
+	write("pkg/api/client_test.go", "package api\n")
+	write("pkg/a-very-long-directory-name/with/nested/directories/file.go", "package nested\n")
+	write("intent.go", "package main\n")
+	runGit(t, dir, "add", "main.go", "pkg")
+	// intent to add entries have an extended flag, which requires version 3
+	runGit(t, dir, "add", "-N", "intent.go")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	for _, version := range []string{"2", "3", "4"} {
+		t.Run("version "+version, func(t *testing.T) {
+			if version == "2" {
+				runGit(t, dir, "rm", "-q", "--cached", "intent.go")
+			}
+			runGit(t, dir, "update-index", "--index-version", version)
+
+			entries, err := repo.ReadIndex()
+			require.NoError(t, err)
+
+			expected := lsFiles(t, dir)
+			require.Len(t, entries, len(expected))
+			for i := range expected {
+				require.Equal(t, expected[i].Path, entries[i].Path)
+				require.Equal(t, expected[i].Mode, entries[i].Mode)
+				require.Equal(t, expected[i].Hash, entries[i].Hash)
+				require.Equal(t, expected[i].Stage, entries[i].Stage)
+				require.True(t, entries[i].Regular())
+			}
+		})
+	}
+}
+
+func TestRepositoryReadIndexErrors(t *testing.T) {
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/acme/api.git"))
+	require.NoError(t, err)
+
+	entries, err := repo.ReadIndex()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	index := filepath.Join(repo.Dir, "index")
+	require.NoError(t, os.WriteFile(index, []byte("not an index file"), 0644))
+	_, err = repo.ReadIndex()
+	require.Error(t, err)
+
+	corrupt := append([]byte("DIRC\x00\x00\x00\x02\x00\x00\x00\x00"), make([]byte, 20)...)
+	corrupt[len(corrupt)-1] = 1
+	require.NoError(t, os.WriteFile(index, corrupt, 0644))
+	_, err = repo.ReadIndex()
+	require.ErrorContains(t, err, "checksum")
+
+	// the padding of the last entry is missing and the checksum is skipped, see index.skipHash
+	truncated := []byte("DIRC\x00\x00\x00\x02\x00\x00\x00\x02")
+	entry := make([]byte, 62)
+	entry[61] = 2 // length of the path
+	truncated = append(truncated, entry...)
+	truncated = append(truncated, "ab\x00"...)
+	truncated = append(truncated, make([]byte, 20)...)
+	require.NoError(t, os.WriteFile(index, truncated, 0644))
+	_, err = repo.ReadIndex()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestRepositoryReadBlob(t *testing.T) {
+	dir := newGitRepository(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644))
+	runGit(t, dir, "add", "main.go")
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	entries, err := repo.ReadIndex()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	data, err := repo.ReadBlob(entries[0].Hash)
+	require.NoError(t, err)
+	require.Equal(t, "package main\n", string(data))
+
+	_, err = repo.ReadBlob(git.Hash{1})
+	require.ErrorIs(t, err, git.ErrObjectNotFound)
+}
@@ -0,0 +1,154 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+)
+
+const (
+	journalDir     = "issue-summoner"
+	journalFile    = "journal.json"
+	journalVersion = 1
+)
+
+// JournalEntry is an issue that was created on a source code host but has not been
+// written back to its annotation yet
+type JournalEntry struct {
+	Sch         string             `json:"sch"`
+	Profile     string             `json:"profile"`
+	Target      string             `json:"target"` // repository the issue was reported to, see [Repository.Target]
+	IssueNumber int                `json:"issueNumber"`
+	Title       string             `json:"title"`
+	FilePath    string             `json:"filePath"` // relative to the work tree
+	LineNumber  int                `json:"lineNumber"`
+	Fingerprint common.Fingerprint `json:"fingerprint"`
+	CreatedAt   time.Time          `json:"createdAt"`
+}
+
+// Journal records every issue that is created by <issue-summoner report> before the issue
+// number is written back to the source file. Entries are removed once the write-back
+// succeeds, which leaves the entries of a report that failed to write back, or was
+// interrupted, for the next run to recover. The journal is stored inside of the .git
+// directory since it describes the state of a single clone.
+type Journal struct {
+	path    string
+	mu      sync.Mutex     // [Record] is invoked from multiple go routines
+	Version int            `json:"version"`
+	Entries []JournalEntry `json:"entries"`
+}
+
+// JournalPath returns the location of the journal for the repository
+func JournalPath(repo *Repository) string {
+	return filepath.Join(repo.Dir, journalDir, journalFile)
+}
+
+// OpenJournal reads the journal of the repository. An empty journal is returned
+// when the file does not exist.
+func OpenJournal(repo *Repository) (*Journal, error) {
+	journal := &Journal{path: JournalPath(repo), Version: journalVersion, Entries: make([]JournalEntry, 0)}
+
+	data, err := os.ReadFile(journal.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return journal, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", journal.path, err)
+	}
+
+	if journal.Version != journalVersion {
+		return nil, fmt.Errorf(
+			"journal version of %d is currently unsupported. Expected version %d",
+			journal.Version,
+			journalVersion,
+		)
+	}
+
+	return journal, nil
+}
+
+// Record appends the entry and writes the journal to disk before returning, so that the
+// entry survives the process being interrupted
+func (j *Journal) Record(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	j.Entries = append(j.Entries, entry)
+	return j.write()
+}
+
+// Pending returns the entries that were reported with the source code host, profile and
+// target repository
+func (j *Journal) Pending(sch, profile, target string) []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	pending := make([]JournalEntry, 0)
+	for _, entry := range j.Entries {
+		if entry.Sch == sch && entry.Profile == profile && entry.Target == target {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+// Remove deletes the entries for the issue numbers, which were reported with the source
+// code host, profile and target repository, and writes the journal to disk
+func (j *Journal) Remove(sch, profile, target string, issueNumbers ...int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	remove := make(map[int]bool, len(issueNumbers))
+	for _, num := range issueNumbers {
+		remove[num] = true
+	}
+
+	entries := make([]JournalEntry, 0, len(j.Entries))
+	for _, entry := range j.Entries {
+		matches := entry.Sch == sch && entry.Profile == profile && entry.Target == target
+		if !matches || !remove[entry.IssueNumber] {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) == len(j.Entries) {
+		return nil
+	}
+
+	j.Entries = entries
+	return j.write()
+}
+
+// write writes to a temporary file and renames it so that the journal is never left
+// partially written. The caller must hold the lock.
+func (j *Journal) write() error {
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, j.path)
+}
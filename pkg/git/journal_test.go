@@ -0,0 +1,47 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+	require.Equal(t, "AntoninoAdornetto/issue-summoner", repo.Target())
+
+	journal, err := git.OpenJournal(repo)
+	require.NoError(t, err)
+	require.Empty(t, journal.Pending(git.Github, git.Github, repo.Target()))
+
+	entries := []git.JournalEntry{
+		{Sch: git.Github, Profile: git.Github, Target: repo.Target(), IssueNumber: 1, Title: "first", FilePath: "main.go"},
+		{Sch: git.Github, Profile: git.Github, Target: repo.Target(), IssueNumber: 2, Title: "second", FilePath: "main.go"},
+		{Sch: git.Github, Profile: "work", Target: repo.Target(), IssueNumber: 1, Title: "other profile", FilePath: "main.go"},
+		{Sch: git.Local, Profile: git.Local, Target: repo.Target(), IssueNumber: 1, Title: "other host", FilePath: "main.go"},
+	}
+
+	for _, entry := range entries {
+		require.NoError(t, journal.Record(entry))
+	}
+
+	// entries are written to disk as soon as they are recorded
+	reopened, err := git.OpenJournal(repo)
+	require.NoError(t, err)
+
+	pending := reopened.Pending(git.Github, git.Github, repo.Target())
+	require.Len(t, pending, 2)
+	require.Equal(t, "second", pending[1].Title)
+	require.False(t, pending[0].CreatedAt.IsZero())
+
+	require.NoError(t, reopened.Remove(git.Github, git.Github, repo.Target(), 1))
+
+	reopened, err = git.OpenJournal(repo)
+	require.NoError(t, err)
+	require.Len(t, reopened.Entries, 3)
+	require.Len(t, reopened.Pending(git.Github, git.Github, repo.Target()), 1)
+	require.Len(t, reopened.Pending(git.Github, "work", repo.Target()), 1)
+	require.Len(t, reopened.Pending(git.Local, git.Local, repo.Target()), 1)
+}
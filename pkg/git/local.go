@@ -0,0 +1,262 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	localDir          = ".issue-summoner"
+	localFile         = "issues.json"
+	localStoreVersion = 1
+	LocalStateOpen    = "open"
+	LocalStateClosed  = "closed"
+)
+
+// LocalIssue is an issue that is tracked inside of the repository rather than on
+// a source code hosting platform. Local issues are stored in a json file that is
+// meant to be committed alongside the source code, which allows the report and purge
+// flows to work in offline or air-gapped environments.
+type LocalIssue struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Labels    []string   `json:"labels,omitempty"`
+	Assignees []string   `json:"assignees,omitempty"`
+	State     string     `json:"state"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+}
+
+type localStore struct {
+	Version int          `json:"version"`
+	NextID  int          `json:"nextId"`
+	Issues  []LocalIssue `json:"issues"`
+}
+
+type localManager struct {
+	repo   *Repository
+	labels []string // default labels of the profile
+	opts   ManagerOptions
+	mu     sync.Mutex // [Report] is invoked from multiple go routines
+}
+
+func newLocalManager(repo *Repository, profile Profile, opts ManagerOptions) (*localManager, error) {
+	return &localManager{repo: repo, labels: profile.Config.Labels, opts: opts}, nil
+}
+
+// LocalIssuesPath returns the location of the file that local issues are stored in
+func LocalIssuesPath(repo *Repository) string {
+	return filepath.Join(repo.WorkTree, localDir, localFile)
+}
+
+// the local source code host does not require authorization
+func (local *localManager) Authorize() error {
+	return nil
+}
+
+func (local *localManager) StoreToken(token string) error {
+	return errors.New("the local source code host does not use access tokens")
+}
+
+func (local *localManager) Validate() (TokenInfo, error) {
+	return TokenInfo{}, nil
+}
+
+func (local *localManager) Authenticated() bool {
+	return true
+}
+
+func (local *localManager) Report(issue ReportRequest, res chan ReportResponse) {
+	result := ReportResponse{Index: issue.Index}
+	created := LocalIssue{
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Labels:    mergeLabels(local.labels, issue.Labels),
+		Assignees: issue.Assignees,
+		State:     LocalStateOpen,
+		CreatedAt: time.Now(),
+	}
+
+	// the id is assigned when the issue is appended to the store
+	if local.opts.DryRun != nil {
+		data, err := json.Marshal(created)
+		if err == nil {
+			_, err = local.opts.dryRun("WRITE", LocalIssuesPath(local.repo), data)
+		}
+		if err != nil {
+			result.Err = fmt.Errorf(errReport, issue.Title, err)
+		}
+		res <- result
+		return
+	}
+
+	local.mu.Lock()
+	defer local.mu.Unlock()
+
+	store, err := readLocalStore(local.repo)
+	if err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	id := store.NextID
+	store.NextID++
+	created.ID = id
+	store.Issues = append(store.Issues, created)
+
+	if err := writeLocalStore(local.repo, store); err != nil {
+		result.Err = fmt.Errorf(errReport, issue.Title, err)
+		res <- result
+		return
+	}
+
+	result.ID = id
+	res <- result
+}
+
+func (local *localManager) GetStatus(issueNum, index int, status chan StatusResponse) {
+	res := StatusResponse{Index: index, Resolved: false}
+
+	local.mu.Lock()
+	store, err := readLocalStore(local.repo)
+	local.mu.Unlock()
+
+	if err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	issue, err := store.find(issueNum)
+	if err != nil {
+		res.Err = err
+		status <- res
+		return
+	}
+
+	res.Resolved = issue.State == LocalStateClosed
+	status <- res
+}
+
+func (local *localManager) Close(issueNum int) error {
+	local.mu.Lock()
+	defer local.mu.Unlock()
+	return SetLocalIssueState(local.repo, issueNum, LocalStateClosed)
+}
+
+func (local *localManager) ListOpen() ([]RemoteIssue, error) {
+	local.mu.Lock()
+	store, err := readLocalStore(local.repo)
+	local.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	open := make([]RemoteIssue, 0)
+	for _, issue := range store.Issues {
+		if issue.State == LocalStateOpen {
+			open = append(open, RemoteIssue{Number: issue.ID, Title: issue.Title, Body: issue.Body})
+		}
+	}
+	return open, nil
+}
+
+// ReadLocalIssues returns every issue that has been reported to the local source code host
+func ReadLocalIssues(repo *Repository) ([]LocalIssue, error) {
+	store, err := readLocalStore(repo)
+	if err != nil {
+		return nil, err
+	}
+	return store.Issues, nil
+}
+
+// SetLocalIssueState opens or closes a local issue. Closed issues are treated as
+// resolved when running <issue-summoner scan -m purge -s local>
+func SetLocalIssueState(repo *Repository, id int, state string) error {
+	if state != LocalStateOpen && state != LocalStateClosed {
+		return fmt.Errorf("expected state of %q or %q but got %q", LocalStateOpen, LocalStateClosed, state)
+	}
+
+	store, err := readLocalStore(repo)
+	if err != nil {
+		return err
+	}
+
+	issue, err := store.find(id)
+	if err != nil {
+		return err
+	}
+
+	issue.State = state
+	issue.ClosedAt = nil
+	if state == LocalStateClosed {
+		now := time.Now()
+		issue.ClosedAt = &now
+	}
+
+	return writeLocalStore(repo, store)
+}
+
+func (store *localStore) find(id int) (*LocalIssue, error) {
+	for i := range store.Issues {
+		if store.Issues[i].ID == id {
+			return &store.Issues[i], nil
+		}
+	}
+	return nil, fmt.Errorf("local issue #%d does not exist", id)
+}
+
+func readLocalStore(repo *Repository) (*localStore, error) {
+	store := &localStore{Version: localStoreVersion, NextID: 1, Issues: make([]LocalIssue, 0)}
+
+	data, err := os.ReadFile(LocalIssuesPath(repo))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+
+	if store.Version != localStoreVersion {
+		return nil, fmt.Errorf(
+			"local issue file version of %d is currently unsupported. Expected version %d",
+			store.Version,
+			localStoreVersion,
+		)
+	}
+
+	return store, nil
+}
+
+// writeLocalStore writes to a temporary file and renames it so that the issue
+// file is never left partially written
+func writeLocalStore(repo *Repository, store *localStore) error {
+	path := LocalIssuesPath(repo)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,93 @@
+package git_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalManager(t *testing.T) {
+	setTestConfigDir(t)
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Local, repo, git.ManagerOptions{})
+	require.NoError(t, err)
+	require.True(t, manager.Authenticated())
+
+	titles := []string{"first local issue", "second local issue"}
+	reported := make(chan git.ReportResponse, len(titles))
+	for i, title := range titles {
+		manager.Report(git.ReportRequest{Title: title, Body: "body", Index: i}, reported)
+	}
+	close(reported)
+
+	ids := make(map[int]int)
+	for r := range reported {
+		require.NoError(t, r.Err)
+		ids[r.Index] = r.ID
+	}
+
+	// ids are assigned sequentially, starting at 1
+	require.Equal(t, map[int]int{0: 1, 1: 2}, ids)
+
+	issues, err := git.ReadLocalIssues(repo)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	require.Equal(t, titles[1], issues[1].Title)
+	require.Equal(t, git.LocalStateOpen, issues[1].State)
+
+	require.NoError(t, git.SetLocalIssueState(repo, 2, git.LocalStateClosed))
+	require.Error(t, git.SetLocalIssueState(repo, 3, git.LocalStateClosed))
+
+	status := make(chan git.StatusResponse, 3)
+	manager.GetStatus(1, 0, status)
+	manager.GetStatus(2, 1, status)
+	manager.GetStatus(3, 2, status)
+	close(status)
+
+	resolved := make(map[int]bool)
+	for s := range status {
+		if s.Index == 2 {
+			require.Error(t, s.Err)
+			continue
+		}
+		require.NoError(t, s.Err)
+		resolved[s.Index] = s.Resolved
+	}
+
+	require.Equal(t, map[int]bool{0: false, 1: true}, resolved)
+
+	require.NoError(t, manager.Close(1))
+	issues, err = git.ReadLocalIssues(repo)
+	require.NoError(t, err)
+	require.Equal(t, git.LocalStateClosed, issues[0].State)
+
+	open, err := manager.ListOpen()
+	require.NoError(t, err)
+	require.Empty(t, open)
+}
+
+func TestLocalManagerDryRun(t *testing.T) {
+	setTestConfigDir(t)
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	out := bytes.Buffer{}
+	manager, err := git.NewGitManager(git.Local, repo, git.ManagerOptions{DryRun: &out})
+	require.NoError(t, err)
+
+	reported := make(chan git.ReportResponse, 1)
+	manager.Report(git.ReportRequest{Title: "dry run", Body: "body"}, reported)
+	require.NoError(t, (<-reported).Err)
+
+	_, err = os.Stat(git.LocalIssuesPath(repo))
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Contains(t, out.String(), "WRITE "+git.LocalIssuesPath(repo))
+	require.Contains(t, out.String(), `"title": "dry run"`)
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	ObjectBlob   = "blob"
+	ObjectTree   = "tree"
+	ObjectCommit = "commit"
+	ObjectTag    = "tag"
+)
+
+// ErrObjectNotFound is returned when an object does not exist in the object database
+var ErrObjectNotFound = errors.New("object not found")
+
+// ReadObject returns the type and contents of an object from the object database (.git/objects).
+// Objects are read from their loose file, or from the packs that contain the objects which were
+// fetched or compressed by <git gc>, in which case deltas are resolved against their base objects.
+func (repo *Repository) ReadObject(hash Hash) (string, []byte, error) {
+	name := hash.String()
+	file, err := os.Open(filepath.Join(repo.Dir, "objects", name[:2], name[2:]))
+	if errors.Is(err, os.ErrNotExist) {
+		kind, data, err := repo.readPacked(hash)
+		if err != nil {
+			return "", nil, err
+		}
+		if sum := objectHash(kind, data); sum != hash {
+			return "", nil, fmt.Errorf("invalid object %s: hash mismatch", name)
+		}
+		return kind, data, nil
+	} else if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	kind, data, err := readLooseObject(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid object %s: %w", name, err)
+	}
+
+	if sum := objectHash(kind, data); sum != hash {
+		return "", nil, fmt.Errorf("invalid object %s: hash mismatch", name)
+	}
+
+	return kind, data, nil
+}
+
+// ReadBlob returns the contents of a file from the object database
+func (repo *Repository) ReadBlob(hash Hash) ([]byte, error) {
+	kind, data, err := repo.ReadObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != ObjectBlob {
+		return nil, fmt.Errorf("object %s is a %s, not a %s", hash, kind, ObjectBlob)
+	}
+	return data, nil
+}
+
+// readLooseObject decompresses a loose object, which begins with a header of <type> <size>\0
+func readLooseObject(r io.Reader) (string, []byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return "", nil, err
+	}
+	defer z.Close()
+
+	raw, err := io.ReadAll(z)
+	if err != nil {
+		return "", nil, err
+	}
+
+	header, data, ok := bytes.Cut(raw, []byte{0})
+	if !ok {
+		return "", nil, errors.New("missing header")
+	}
+
+	kind, size, ok := bytes.Cut(header, []byte{' '})
+	if !ok {
+		return "", nil, errors.New("invalid header")
+	}
+
+	if n, err := strconv.Atoi(string(size)); err != nil || n != len(data) {
+		return "", nil, fmt.Errorf("size %s does not match the %d bytes of data", size, len(data))
+	}
+
+	return string(kind), data, nil
+}
+
+// objectHash computes the name of an object, which is the hash of its header and contents
+func objectHash(kind string, data []byte) Hash {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s %d\x00", kind, len(data))
+	h.Write(data)
+
+	var hash Hash
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
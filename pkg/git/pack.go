@@ -0,0 +1,539 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"container/list"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	packSignature     = "PACK"
+	packIndexMagic    = "\377tOc"
+	packFanoutLen     = 256 * 4
+	packLargeOffset   = 0x80000000
+	packMaxObjectSize = 1 << 32
+	// git limits the length of delta chains with pack.depth, which is at most 4095
+	packMaxDepth = 4096
+	// delta bases that are kept in memory, see [packCache]
+	packCacheEntries = 256
+	packCacheBytes   = 32 << 20
+)
+
+// types of the objects in a pack, deltas are stored relative to another object
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+var packTypes = map[int]string{
+	packCommit: ObjectCommit,
+	packTree:   ObjectTree,
+	packBlob:   ObjectBlob,
+	packTag:    ObjectTag,
+}
+
+// packIndex is the index (.idx) of a pack, which maps the names of its objects to their offsets
+type packIndex struct {
+	pack    string // path of the .pack file
+	hashes  []Hash // sorted names of the objects
+	offsets []int64
+	fanout  [256]uint32 // number of objects whose first byte is less than or equal to the index
+}
+
+// find returns the offset of an object in the pack
+func (idx *packIndex) find(hash Hash) (int64, bool) {
+	lo := uint32(0)
+	if hash[0] > 0 {
+		lo = idx.fanout[hash[0]-1]
+	}
+	hi := idx.fanout[hash[0]]
+
+	i := lo + uint32(sort.Search(int(hi-lo), func(i int) bool {
+		return bytes.Compare(idx.hashes[lo+uint32(i)][:], hash[:]) >= 0
+	}))
+	if i < hi && idx.hashes[i] == hash {
+		return idx.offsets[i], true
+	}
+	return 0, false
+}
+
+// readPackIndexes reads the index of each pack in the object database (.git/objects/pack)
+func (repo *Repository) readPackIndexes() ([]*packIndex, error) {
+	paths, err := filepath.Glob(filepath.Join(repo.Dir, "objects", "pack", "pack-*.idx"))
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]*packIndex, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := parsePackIndex(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack index %s: %w", filepath.Base(path), err)
+		}
+
+		idx.pack = strings.TrimSuffix(path, ".idx") + ".pack"
+		indexes = append(indexes, idx)
+	}
+
+	return indexes, nil
+}
+
+// parsePackIndex parses version 1 and 2 pack indexes. Both begin with a fanout table, version 2
+// is preceded by a header and stores the names, checksums and offsets of the objects in separate
+// tables, with an additional table for the offsets of packs that are larger than 2GB.
+func parsePackIndex(data []byte) (*packIndex, error) {
+	if len(data) < 2*sha1.Size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	body, checksum := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if sum := sha1.Sum(body); !bytes.Equal(sum[:], checksum) {
+		return nil, errors.New("checksum mismatch")
+	}
+
+	version, offset := 1, 0
+	if string(data[:4]) == packIndexMagic {
+		version = int(binary.BigEndian.Uint32(data[4:8]))
+		if version != 2 {
+			return nil, fmt.Errorf("version %d is unsupported. Expected version 1 or 2", version)
+		}
+		offset = 8
+	}
+
+	if len(body) < offset+packFanoutLen {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	idx := &packIndex{}
+	for i := range idx.fanout {
+		idx.fanout[i] = binary.BigEndian.Uint32(data[offset+i*4:])
+		if i > 0 && idx.fanout[i] < idx.fanout[i-1] {
+			return nil, errors.New("fanout table is not sorted")
+		}
+	}
+	offset += packFanoutLen
+
+	count := int(idx.fanout[255])
+	idx.hashes = make([]Hash, count)
+	idx.offsets = make([]int64, count)
+
+	if version == 1 {
+		// each entry is the offset of the object followed by its name
+		if len(body) < offset+count*(4+sha1.Size) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		for i := 0; i < count; i++ {
+			entry := data[offset+i*(4+sha1.Size):]
+			idx.offsets[i] = int64(binary.BigEndian.Uint32(entry))
+			copy(idx.hashes[i][:], entry[4:])
+		}
+		return idx, nil
+	}
+
+	names, offsets, large := offset, offset+count*(sha1.Size+4), offset+count*(sha1.Size+8)
+	if len(body) < large {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	for i := 0; i < count; i++ {
+		copy(idx.hashes[i][:], data[names+i*sha1.Size:])
+
+		// the most significant bit marks an index into the table of large offsets
+		value := binary.BigEndian.Uint32(data[offsets+i*4:])
+		if value&packLargeOffset == 0 {
+			idx.offsets[i] = int64(value)
+			continue
+		}
+
+		at := large + int(value&^packLargeOffset)*8
+		if len(body) < at+8 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		idx.offsets[i] = int64(binary.BigEndian.Uint64(data[at:]))
+	}
+
+	return idx, nil
+}
+
+// packReader reads the objects of a single pack, resolving deltas whose base is stored in the same
+// pack by their offset and deltas whose base is stored elsewhere by their name
+type packReader struct {
+	repo  *Repository
+	index *packIndex
+	file  *os.File
+}
+
+// readPacked returns the type and contents of an object that is stored in a pack
+func (repo *Repository) readPacked(hash Hash) (string, []byte, error) {
+	indexes, err := repo.packIndexes()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, idx := range indexes {
+		offset, ok := idx.find(hash)
+		if !ok {
+			continue
+		}
+
+		file, err := os.Open(idx.pack)
+		if err != nil {
+			return "", nil, err
+		}
+		defer file.Close()
+
+		if err := verifyPackHeader(file); err != nil {
+			return "", nil, fmt.Errorf("invalid pack %s: %w", filepath.Base(idx.pack), err)
+		}
+
+		pr := packReader{repo: repo, index: idx, file: file}
+		kind, data, err := pr.read(offset, 0)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid pack %s: object %s: %w", filepath.Base(idx.pack), hash, err)
+		}
+		return kind, data, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: %s", ErrObjectNotFound, hash)
+}
+
+// packIndexes reads the indexes of the packs once, since they are read for every packed object
+func (repo *Repository) packIndexes() ([]*packIndex, error) {
+	repo.packsOnce.Do(func() {
+		repo.packs, repo.packsErr = repo.readPackIndexes()
+	})
+	return repo.packs, repo.packsErr
+}
+
+func verifyPackHeader(file *os.File) error {
+	header := make([]byte, 12)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return err
+	}
+
+	if string(header[:4]) != packSignature {
+		return errors.New("missing signature")
+	}
+
+	if version := binary.BigEndian.Uint32(header[4:8]); version != 2 && version != 3 {
+		return fmt.Errorf("version %d is unsupported. Expected version 2 or 3", version)
+	}
+	return nil
+}
+
+// read returns the type and contents of the object at an offset of the pack. Each object begins
+// with its type and size, followed by the location of its base when it is a delta, and its zlib
+// compressed contents.
+func (pr packReader) read(offset int64, depth int) (string, []byte, error) {
+	if depth > packMaxDepth {
+		return "", nil, errors.New("delta chain is too long")
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(pr.file, offset, 1<<63-1-offset))
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	kind, size, shift := int(c>>4)&7, int64(c&0x0f), 4
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return "", nil, err
+		}
+		if shift > 56 {
+			return "", nil, errors.New("object size overflows")
+		}
+		size |= int64(c&0x7f) << shift
+		shift += 7
+	}
+
+	if size > packMaxObjectSize {
+		return "", nil, fmt.Errorf("object of %d bytes is too large", size)
+	}
+
+	switch kind {
+	case packOfsDelta:
+		distance, err := readOfsDistance(r)
+		if err != nil {
+			return "", nil, err
+		}
+		if distance <= 0 || distance > offset {
+			return "", nil, fmt.Errorf("delta base offset %d is out of range", offset-distance)
+		}
+
+		delta, err := inflate(r, size)
+		if err != nil {
+			return "", nil, err
+		}
+
+		baseKind, base, err := pr.base(offset-distance, depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+
+		data, err := applyDelta(base, delta)
+		return baseKind, data, err
+	case packRefDelta:
+		var name Hash
+		if _, err := io.ReadFull(r, name[:]); err != nil {
+			return "", nil, err
+		}
+
+		delta, err := inflate(r, size)
+		if err != nil {
+			return "", nil, err
+		}
+
+		var baseKind string
+		var base []byte
+		if at, ok := pr.index.find(name); ok {
+			baseKind, base, err = pr.base(at, depth+1)
+		} else {
+			baseKind, base, err = pr.repo.ReadObject(name)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		data, err := applyDelta(base, delta)
+		return baseKind, data, err
+	default:
+		name, ok := packTypes[kind]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown object type %d", kind)
+		}
+
+		data, err := inflate(r, size)
+		return name, data, err
+	}
+}
+
+// base reads the base of a delta. Bases are cached, since consecutive versions of a file, such as
+// the versions that are read by <issue-summoner history> and blame, are deltas of the same chain.
+func (pr packReader) base(offset int64, depth int) (string, []byte, error) {
+	key := packCacheKey{pack: pr.index.pack, offset: offset}
+	if kind, data, ok := pr.repo.deltaBases.get(key); ok {
+		return kind, data, nil
+	}
+
+	kind, data, err := pr.read(offset, depth)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pr.repo.deltaBases.add(key, kind, data)
+	return kind, data, nil
+}
+
+type packCacheKey struct {
+	pack   string
+	offset int64
+}
+
+type packCacheEntry struct {
+	key  packCacheKey
+	kind string
+	data []byte
+}
+
+// packCache keeps the most recently used delta bases, up to [packCacheEntries] objects and
+// [packCacheBytes] bytes. The cached objects are only read by [applyDelta], never returned to
+// the callers of [Repository.ReadObject], so they can not be modified. The zero value is empty.
+type packCache struct {
+	mu      sync.Mutex
+	entries map[packCacheKey]*list.Element
+	order   list.List // most recently used first
+	size    int
+}
+
+func (c *packCache) get(key packCacheKey) (string, []byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*packCacheEntry)
+	return entry.kind, entry.data, true
+}
+
+func (c *packCache) add(key packCacheKey, kind string, data []byte) {
+	if len(data) > packCacheBytes/4 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[packCacheKey]*list.Element)
+	}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&packCacheEntry{key: key, kind: kind, data: data})
+	c.size += len(data)
+
+	for c.order.Len() > packCacheEntries || c.size > packCacheBytes {
+		oldest := c.order.Remove(c.order.Back()).(*packCacheEntry)
+		delete(c.entries, oldest.key)
+		c.size -= len(oldest.data)
+	}
+}
+
+// readOfsDistance reads the distance to the base of a delta, which adds one to each continuation
+// byte so that every distance has a single encoding
+func readOfsDistance(r io.ByteReader) (int64, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	distance := int64(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		if distance > (1<<55)-1 {
+			return 0, errors.New("delta base offset overflows")
+		}
+		distance = (distance+1)<<7 | int64(c&0x7f)
+	}
+
+	return distance, nil
+}
+
+// inflate decompresses the zlib stream of an object and verifies its size. The buffer grows while
+// the stream is read, rather than being allocated up front, so that the size of a corrupt header
+// can not allocate more memory than the stream contains.
+func inflate(r io.Reader, size int64) ([]byte, error) {
+	z, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	buf := bytes.Buffer{}
+	if _, err := io.Copy(&buf, io.LimitReader(z, size+1)); err != nil {
+		return nil, err
+	}
+
+	switch n := int64(buf.Len()); {
+	case n > size:
+		return nil, fmt.Errorf("object is larger than %d bytes", size)
+	case n < size:
+		return nil, io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), nil
+}
+
+// applyDelta reconstructs an object from its base and a delta. A delta begins with the sizes of
+// the base and the result, followed by instructions that copy a range of the base, or insert the
+// bytes that follow the instruction.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+
+	baseSize, err := readDeltaSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if baseSize != int64(len(base)) {
+		return nil, fmt.Errorf("delta expects a base of %d bytes, got %d bytes", baseSize, len(base))
+	}
+
+	size, err := readDeltaSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > packMaxObjectSize {
+		return nil, fmt.Errorf("object of %d bytes is too large", size)
+	}
+
+	// the size is only trusted once the delta has been applied
+	data := make([]byte, 0, min(size, int64(len(base)+len(delta))))
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+
+		switch {
+		case op&0x80 != 0:
+			// the low 4 bits select the bytes of the offset, the next 3 bits the bytes of the size
+			var offset, n int64
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+				c, err := r.ReadByte()
+				if err != nil {
+					return nil, err
+				}
+				if i < 4 {
+					offset |= int64(c) << (8 * i)
+				} else {
+					n |= int64(c) << (8 * (i - 4))
+				}
+			}
+			if n == 0 {
+				n = 0x10000
+			}
+			if offset+n > int64(len(base)) {
+				return nil, errors.New("delta copies beyond the end of its base")
+			}
+			data = append(data, base[offset:offset+n]...)
+		case op != 0:
+			start := len(data)
+			data = append(data, make([]byte, op)...)
+			if _, err := io.ReadFull(r, data[start:]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("delta contains a reserved instruction")
+		}
+	}
+
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("delta result has %d bytes, expected %d bytes", len(data), size)
+	}
+	return data, nil
+}
+
+// readDeltaSize reads the little endian variable length sizes at the start of a delta
+func readDeltaSize(r io.ByteReader) (int64, error) {
+	var size int64
+	for shift := 0; ; shift += 7 {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if shift > 56 {
+			return 0, errors.New("delta size overflows")
+		}
+		size |= int64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return size, nil
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package git_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+// newPackedRepository commits several revisions of similar files, so that packing the repository
+// stores most of the blobs and trees as deltas
+func newPackedRepository(t *testing.T) string {
+	dir := newGitRepository(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "api"), 0755))
+
+	var src strings.Builder
+	src.WriteString("package api\n\n")
+	for rev := 0; rev < 8; rev++ {
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(&src, "func handler%d_%d() {} // revision %d\n", rev, i, rev)
+		}
+
+		content := []byte(src.String())
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "api", "client.go"), content, 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("copy%d.go", rev%3)), content, 0644))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", fmt.Sprintf("revision %d", rev))
+	}
+
+	return dir
+}
+
+func TestRepositoryReadObjectPacked(t *testing.T) {
+	testCases := []struct {
+		name   string
+		repack []string
+	}{
+		{name: "Should resolve deltas against the offset of their base", repack: []string{"repack", "-a", "-d", "-f", "-q"}},
+		{
+			name:   "Should resolve deltas against the name of their base",
+			repack: []string{"-c", "repack.useDeltaBaseOffset=false", "repack", "-a", "-d", "-f", "-q"},
+		},
+		{
+			name:   "Should read version 1 pack indexes",
+			repack: []string{"-c", "pack.indexVersion=1", "repack", "-a", "-d", "-f", "-q"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newPackedRepository(t)
+			runGit(t, dir, tc.repack...)
+
+			packs, err := filepath.Glob(filepath.Join(dir, ".git", "objects", "pack", "*.pack"))
+			require.NoError(t, err)
+			require.Len(t, packs, 1)
+			require.Contains(t, runGit(t, dir, "verify-pack", "-v", packs[0]), "chain length", "expected deltas")
+
+			repo, err := git.NewRepository(dir)
+			require.NoError(t, err)
+
+			// the second pass reads the delta bases that were cached by the first pass
+			objects := runGit(t, dir, "cat-file", "--batch-all-objects", "--batch-check=%(objectname) %(objecttype)")
+			for pass := 0; pass < 2; pass++ {
+				for _, line := range strings.Split(strings.TrimSpace(objects), "\n") {
+					name, kind, _ := strings.Cut(line, " ")
+
+					var hash git.Hash
+					decoded, err := hex.DecodeString(name)
+					require.NoError(t, err)
+					copy(hash[:], decoded)
+
+					actualKind, data, err := repo.ReadObject(hash)
+					require.NoError(t, err, name)
+					require.Equal(t, kind, actualKind, name)
+					require.Equal(t, runGit(t, dir, "cat-file", kind, name), string(data), name)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryReadObjectPackedSizeMismatch(t *testing.T) {
+	dir := newGitRepository(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(strings.Repeat("package main\n", 10)), 0644))
+	runGit(t, dir, "add", "main.go")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	runGit(t, dir, "repack", "-a", "-d", "-q")
+	blob := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD:main.go"))
+
+	packs, err := filepath.Glob(filepath.Join(dir, ".git", "objects", "pack", "*.pack"))
+	require.NoError(t, err)
+	require.Len(t, packs, 1)
+
+	// the columns of verify-pack are the name, type, size, size in the pack and offset of each object
+	offset := -1
+	for _, line := range strings.Split(runGit(t, dir, "verify-pack", "-v", packs[0]), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 5 && fields[0] == blob {
+			offset, err = strconv.Atoi(fields[4])
+			require.NoError(t, err)
+		}
+	}
+	require.Positive(t, offset)
+
+	// the blob of 130 bytes has a header of 2 bytes, which can claim up to 2047 bytes
+	data, err := os.ReadFile(packs[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Chmod(packs[0], 0644))
+	data[offset] |= 0x0f
+	data[offset+1] = 0x7f
+	require.NoError(t, os.WriteFile(packs[0], data, 0644))
+
+	var hash git.Hash
+	decoded, err := hex.DecodeString(blob)
+	require.NoError(t, err)
+	copy(hash[:], decoded)
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	_, _, err = repo.ReadObject(hash)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
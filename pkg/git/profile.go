@@ -0,0 +1,87 @@
+package git
+
+import (
+	"path"
+	"slices"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+)
+
+// Profile is the config.json entry that a [GitManager] reads settings and
+// credentials from, see [common.IssueSummonerConfig]
+type Profile struct {
+	Name    string
+	Backend sourceCodeHost
+	Config  common.IssueSummonerConfig
+}
+
+// SelectProfile locates the profile for the repository using the following precedence:
+//
+//  1. the profile named by the --profile flag. The source code host of the profile overrides [sch]
+//  2. the first profile, in alphabetical order, for [sch] that lists the repository in its repositories
+//  3. the profile named after [sch], i.e. github
+//
+// A profile that is named by the --profile flag but does not exist yet is created for [sch],
+// which allows <issue-summoner authorize --profile work> to write into a new profile.
+func SelectProfile(conf common.Config, sch sourceCodeHost, repo *Repository, name string) Profile {
+	if name != "" {
+		entry, ok := conf[name]
+		if !ok && name != sch {
+			entry.Sch = sch
+		}
+		return Profile{Name: name, Backend: profileBackend(name, entry), Config: entry}
+	}
+
+	names := make([]string, 0, len(conf))
+	for name := range conf {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		entry := conf[name]
+		if profileBackend(name, entry) == sch && matchRepository(entry.Repositories, repo) {
+			return Profile{Name: name, Backend: sch, Config: entry}
+		}
+	}
+
+	return Profile{Name: sch, Backend: sch, Config: conf[sch]}
+}
+
+func profileBackend(name string, entry common.IssueSummonerConfig) sourceCodeHost {
+	if entry.Sch != "" {
+		return entry.Sch
+	}
+	return name
+}
+
+// matchRepository reports whether any of the patterns match owner/name or host/owner/name
+func matchRepository(patterns []string, repo *Repository) bool {
+	if repo == nil {
+		return false
+	}
+
+	slug := repo.UserName + "/" + repo.RepoName
+	candidates := []string{slug, repo.Host + "/" + slug}
+
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// mergeLabels appends labels to the default labels of a profile without duplicates
+func mergeLabels(defaults, labels []string) []string {
+	merged := make([]string, 0, len(defaults)+len(labels))
+	for _, label := range append(slices.Clone(defaults), labels...) {
+		if label != "" && !slices.Contains(merged, label) {
+			merged = append(merged, label)
+		}
+	}
+	return merged
+}
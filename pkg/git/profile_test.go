@@ -0,0 +1,146 @@
+package git_test
+
+import (
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectProfile(t *testing.T) {
+	conf := common.Config{
+		"github": {},
+		"azure":  {},
+		"work": {
+			Sch:          git.Github,
+			Repositories: []string{"acme/*"},
+		},
+		"oss": {
+			Sch:          git.Github,
+			Repositories: []string{"github.com/AntoninoAdornetto/*"},
+		},
+	}
+
+	testCases := []struct {
+		name      string
+		sch       string
+		remoteUrl string
+		profile   string
+		expected  string
+		backend   string
+	}{
+		{
+			name:      "Should select the profile named by the profile flag",
+			sch:       git.Azure,
+			remoteUrl: "https://github.com/acme/api.git",
+			profile:   "work",
+			expected:  "work",
+			backend:   git.Github,
+		},
+		{
+			name:      "Should select the profile that lists the owner and repository name",
+			sch:       git.Github,
+			remoteUrl: "git@git.corp.example:acme/api.git",
+			expected:  "work",
+			backend:   git.Github,
+		},
+		{
+			name:      "Should select the profile that lists the host, owner and repository name",
+			sch:       git.Github,
+			remoteUrl: "https://github.com/AntoninoAdornetto/issue-summoner.git",
+			expected:  "oss",
+			backend:   git.Github,
+		},
+		{
+			name:      "Should select the profile named after the source code host when no repositories match",
+			sch:       git.Github,
+			remoteUrl: "https://github.com/octocat/hello-world.git",
+			expected:  git.Github,
+			backend:   git.Github,
+		},
+		{
+			name:      "Should ignore profiles for other source code hosts",
+			sch:       git.Local,
+			remoteUrl: "https://github.com/acme/api.git",
+			expected:  git.Local,
+			backend:   git.Local,
+		},
+		{
+			name:      "Should create profiles that do not exist for the source code host",
+			sch:       git.Azure,
+			remoteUrl: "https://github.com/acme/api.git",
+			profile:   "personal",
+			expected:  "personal",
+			backend:   git.Azure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, err := git.NewRepository(newTestRepository(t, tc.remoteUrl))
+			require.NoError(t, err)
+
+			profile := git.SelectProfile(conf, tc.sch, repo, tc.profile)
+			require.Equal(t, tc.expected, profile.Name)
+			require.Equal(t, tc.backend, profile.Backend)
+		})
+	}
+}
+
+func TestStoreTokenWritesIntoProfile(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "")
+
+	srv := newFakeGithub(t)
+	writeFakeGithubConfig(t, srv, common.AuthConfig{})
+
+	repo, err := git.NewRepository(newTestRepository(t, "git@git.test:AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	opts := git.ManagerOptions{Profile: "bot"}
+	manager, err := git.NewGitManager(git.Github, repo, opts)
+	require.NoError(t, err)
+	require.NoError(t, manager.StoreToken("fine-grained-token"))
+
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+	require.Equal(t, git.Github, conf["bot"].Sch)
+	require.False(t, conf[git.Github].Hosts[fakeGithubHost].Auth.Stored())
+
+	token, err := conf["bot"].Hosts[fakeGithubHost].Auth.Token()
+	require.NoError(t, err)
+	require.Equal(t, "fine-grained-token", token)
+
+	authErr := &git.AuthError{Sch: git.Github, Profile: "bot", Reason: "was not found"}
+	require.ErrorContains(t, authErr, "<issue-summoner authorize -s github --profile bot>")
+}
+
+func TestProfileTokenSource(t *testing.T) {
+	setTestConfigDir(t)
+	t.Setenv("GITHUB_TOKEN", "personal-token")
+	t.Setenv("GITHUB_WORK_TOKEN", "fine-grained-token")
+
+	srv := newFakeGithub(t)
+	conf, err := common.ReadConfig()
+	require.NoError(t, err)
+
+	conf["work"] = common.IssueSummonerConfig{
+		Sch:         git.Github,
+		Host:        fakeGithubHost,
+		BaseUrl:     srv.URL,
+		ApiBaseUrl:  srv.URL,
+		TokenSource: "env:GITHUB_WORK_TOKEN",
+	}
+	require.NoError(t, common.WriteToConfig(conf))
+
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/acme/api.git"))
+	require.NoError(t, err)
+
+	manager, err := git.NewGitManager(git.Github, repo, git.ManagerOptions{Profile: "work"})
+	require.NoError(t, err)
+
+	info, err := manager.Validate()
+	require.NoError(t, err)
+	require.Equal(t, "AntoninoAdornetto", info.Owner)
+}
@@ -0,0 +1,241 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// shortest abbreviation of an object name that is accepted, matching git
+	minAbbrevLen = 4
+	// symbolic refs, such as HEAD, may point to other symbolic refs
+	maxSymrefDepth = 5
+)
+
+// refs that are tried for a short name, in the order of <git rev-parse>
+var refPatterns = []string{"refs/%s", "refs/tags/%s", "refs/heads/%s", "refs/remotes/%s", "refs/remotes/%s/HEAD"}
+
+// ResolveRevision returns the commit of a revision without invoking git. A revision is the name of a
+// commit, an abbreviation of at least 4 characters, a ref such as HEAD, refs/heads/main or the short
+// name of a branch, tag or remote branch, i.e. release/1.4 or origin/main. Annotated tags are peeled
+// to the commit that they tag.
+func (repo *Repository) ResolveRevision(rev string) (Hash, error) {
+	if err := validRevision(rev); err != nil {
+		return Hash{}, err
+	}
+
+	hash, err := repo.lookupRevision(rev)
+	if err != nil {
+		return Hash{}, err
+	}
+
+	return repo.peelCommit(rev, hash)
+}
+
+func (repo *Repository) lookupRevision(rev string) (Hash, error) {
+	if len(rev) == 2*len(Hash{}) {
+		if name, err := hex.DecodeString(rev); err == nil {
+			return Hash(name), nil
+		}
+	}
+
+	candidates := make([]string, 0, len(refPatterns)+1)
+	if strings.HasPrefix(rev, "refs/") || isPseudoRef(rev) {
+		candidates = append(candidates, rev)
+	}
+	for _, pattern := range refPatterns {
+		candidates = append(candidates, fmt.Sprintf(pattern, rev))
+	}
+
+	packed, err := repo.readPackedRefs()
+	if err != nil {
+		return Hash{}, err
+	}
+
+	for _, ref := range candidates {
+		hash, ok, err := repo.readRef(ref, packed, 0)
+		if err != nil {
+			return Hash{}, err
+		} else if ok {
+			return hash, nil
+		}
+	}
+
+	if len(rev) >= minAbbrevLen && isHex(rev) {
+		return repo.expandAbbrev(strings.ToLower(rev))
+	}
+
+	return Hash{}, fmt.Errorf("unknown revision %q", rev)
+}
+
+// readRef reads a loose ref from its file, or a ref from the packed-refs file. The second value is
+// false when the ref does not exist.
+func (repo *Repository) readRef(ref string, packed map[string]Hash, depth int) (Hash, bool, error) {
+	// directories, such as refs/heads/release for the branch release/1.4, are not refs
+	path := filepath.Join(repo.Dir, filepath.FromSlash(ref))
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		hash, ok := packed[ref]
+		return hash, ok, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Hash{}, false, err
+	}
+
+	content := strings.TrimSpace(string(data))
+	if target, ok := strings.CutPrefix(content, "ref: "); ok {
+		if depth >= maxSymrefDepth {
+			return Hash{}, false, fmt.Errorf("symbolic ref %s is nested too deeply", ref)
+		}
+		if err := validRevision(target); err != nil {
+			return Hash{}, false, err
+		}
+		return repo.readRef(target, packed, depth+1)
+	}
+
+	name, err := hex.DecodeString(content)
+	if err != nil || len(name) != len(Hash{}) {
+		return Hash{}, false, fmt.Errorf("invalid ref %s", ref)
+	}
+	return Hash(name), true, nil
+}
+
+// readPackedRefs reads the refs that <git pack-refs> moved to the packed-refs file. Lines that
+// begin with ^ contain the commit of the preceding annotated tag and are skipped, since tags are
+// peeled by reading the tag object.
+func (repo *Repository) readPackedRefs() (map[string]Hash, error) {
+	file, err := os.Open(filepath.Join(repo.Dir, "packed-refs"))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Hash{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	refs := make(map[string]Hash)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		value, ref, ok := strings.Cut(line, " ")
+		name, err := hex.DecodeString(value)
+		if !ok || err != nil || len(name) != len(Hash{}) {
+			return nil, fmt.Errorf("invalid packed-refs line %q", line)
+		}
+		refs[ref] = Hash(name)
+	}
+
+	return refs, scanner.Err()
+}
+
+// expandAbbrev returns the object whose name begins with an abbreviation, searching the loose
+// objects and the indexes of the packs
+func (repo *Repository) expandAbbrev(abbrev string) (Hash, error) {
+	matches := make(map[Hash]struct{})
+
+	names, err := os.ReadDir(filepath.Join(repo.Dir, "objects", abbrev[:2]))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Hash{}, err
+	}
+	for _, entry := range names {
+		if name := abbrev[:2] + entry.Name(); strings.HasPrefix(name, abbrev) {
+			if hash, err := hex.DecodeString(name); err == nil && len(hash) == len(Hash{}) {
+				matches[Hash(hash)] = struct{}{}
+			}
+		}
+	}
+
+	indexes, err := repo.packIndexes()
+	if err != nil {
+		return Hash{}, err
+	}
+
+	first, _ := hex.DecodeString(abbrev[:2])
+	for _, idx := range indexes {
+		lo := uint32(0)
+		if first[0] > 0 {
+			lo = idx.fanout[first[0]-1]
+		}
+		for _, hash := range idx.hashes[lo:idx.fanout[first[0]]] {
+			if strings.HasPrefix(hash.String(), abbrev) {
+				matches[hash] = struct{}{}
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Hash{}, fmt.Errorf("unknown revision %q", abbrev)
+	case 1:
+		for hash := range matches {
+			return hash, nil
+		}
+	}
+	return Hash{}, fmt.Errorf("abbreviated revision %q is ambiguous", abbrev)
+}
+
+// peelCommit follows annotated tags until it reaches a commit
+func (repo *Repository) peelCommit(rev string, hash Hash) (Hash, error) {
+	for {
+		kind, data, err := repo.ReadObject(hash)
+		if err != nil {
+			return Hash{}, err
+		}
+
+		switch kind {
+		case ObjectCommit:
+			return hash, nil
+		case ObjectTag:
+			target, err := headerHash(data, "object")
+			if err != nil {
+				return Hash{}, fmt.Errorf("invalid tag %s: %w", hash, err)
+			}
+			hash = target
+		default:
+			return Hash{}, fmt.Errorf("revision %q is a %s, not a %s", rev, kind, ObjectCommit)
+		}
+	}
+}
+
+// headerHash returns the object name of a header of a tag or commit, such as the object of a tag
+func headerHash(data []byte, header string) (Hash, error) {
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		// headers are separated from the message by an empty line
+		if len(line) == 0 {
+			break
+		}
+
+		if value, ok := bytes.CutPrefix(line, []byte(header+" ")); ok {
+			return parseHash(value)
+		}
+	}
+	return Hash{}, fmt.Errorf("missing %s header", header)
+}
+
+// validRevision rejects revisions that would be read from outside of the refs, or parsed as flags
+func validRevision(rev string) error {
+	if rev == "" || strings.HasPrefix(rev, "-") || strings.HasPrefix(rev, "/") ||
+		strings.Contains(rev, "..") || strings.ContainsAny(rev, "\\\x00 ~^:?*[") {
+		return fmt.Errorf("invalid revision %q", rev)
+	}
+	return nil
+}
+
+// isPseudoRef reports whether a ref is stored at the root of the git directory, i.e. HEAD or ORIG_HEAD
+func isPseudoRef(ref string) bool {
+	return strings.HasSuffix(ref, "HEAD") && strings.Trim(ref, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") == ""
+}
+
+func isHex(s string) bool {
+	return strings.Trim(strings.ToLower(s), "0123456789abcdef") == ""
+}
@@ -0,0 +1,131 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryResolveRevision(t *testing.T) {
+	dir := newGitRepository(t)
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	runGit(t, dir, "checkout", "-q", "-b", "main")
+	write("main.go", "package main\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	initial := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+
+	runGit(t, dir, "checkout", "-q", "-b", "release/1.4")
+	write("main.go", "package main\n\n// @TODO release\n")
+	runGit(t, dir, "commit", "-q", "-a", "-m", "release")
+	release := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	runGit(t, dir, "tag", "-a", "v1.4", "-m", "annotated")
+	runGit(t, dir, "tag", "light", initial)
+	runGit(t, dir, "update-ref", "refs/remotes/origin/main", initial)
+	runGit(t, dir, "checkout", "-q", "main")
+	tree := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD^{tree}"))
+
+	testCases := []struct {
+		name     string
+		rev      string
+		expected string
+		err      string
+	}{
+		{name: "Should resolve HEAD", rev: "HEAD", expected: initial},
+		{name: "Should resolve the name of a commit", rev: release, expected: release},
+		{name: "Should resolve an abbreviated commit", rev: release[:7], expected: release},
+		{name: "Should resolve a branch", rev: "release/1.4", expected: release},
+		{name: "Should resolve a full ref", rev: "refs/heads/main", expected: initial},
+		{name: "Should peel an annotated tag", rev: "v1.4", expected: release},
+		{name: "Should resolve a lightweight tag", rev: "light", expected: initial},
+		{name: "Should resolve a remote branch", rev: "origin/main", expected: initial},
+		{name: "Should return an error for unknown revisions", rev: "release/2.0", err: "unknown revision"},
+		{name: "Should return an error for trees", rev: tree, err: "is a tree"},
+		{name: "Should reject revisions outside of the refs", rev: "../config", err: "invalid revision"},
+		{name: "Should reject flags", rev: "--all", err: "invalid revision"},
+	}
+
+	for _, packed := range []bool{false, true} {
+		if packed {
+			runGit(t, dir, "pack-refs", "--all")
+			runGit(t, dir, "gc", "-q")
+		}
+
+		repo, err := git.NewRepository(dir)
+		require.NoError(t, err)
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				actual, err := repo.ResolveRevision(tc.rev)
+				if tc.err != "" {
+					require.ErrorContains(t, err, tc.err)
+					return
+				}
+				require.NoError(t, err)
+				require.Equal(t, tc.expected, actual.String())
+			})
+		}
+	}
+}
+
+func TestRepositoryReadTree(t *testing.T) {
+	dir := newGitRepository(t)
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("main.go", "package main\n")
+	write("pkg/api/client.go", "package api\n")
+	write("pkg/api.go", "package pkg\n")
+	write("scripts/run.sh", "#!/bin/sh\n")
+	require.NoError(t, os.Chmod(filepath.Join(dir, "scripts", "run.sh"), 0755))
+	require.NoError(t, os.Symlink("main.go", filepath.Join(dir, "link.go")))
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+
+	// the work tree is not read
+	write("main.go", "package main\n\n// uncommitted\n")
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "pkg")))
+
+	repo, err := git.NewRepository(dir)
+	require.NoError(t, err)
+
+	commit, err := repo.ResolveRevision("HEAD")
+	require.NoError(t, err)
+
+	entries, err := repo.ReadTree(commit)
+	require.NoError(t, err)
+
+	paths, regular := make([]string, 0), make([]bool, 0)
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+		regular = append(regular, entry.Regular())
+	}
+	require.Equal(t, []string{"link.go", "main.go", "pkg/api.go", "pkg/api/client.go", "scripts/run.sh"}, paths)
+	require.Equal(t, []bool{false, true, true, true, true}, regular)
+
+	data, err := repo.ReadBlob(entries[3].Hash)
+	require.NoError(t, err)
+	require.Equal(t, "package api\n", string(data))
+
+	data, err = repo.ReadCommitted("HEAD", "pkg/api/client.go")
+	require.NoError(t, err)
+	require.Equal(t, "package api\n", string(data))
+
+	_, err = repo.ReadCommitted("HEAD", "pkg/missing.go")
+	require.ErrorIs(t, err, git.ErrObjectNotFound)
+
+	_, err = repo.ReadCommitted("HEAD", "pkg")
+	require.ErrorIs(t, err, git.ErrObjectNotFound)
+}
@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -15,9 +17,15 @@ type Repository struct {
 	WorkTree          string
 	Dir               string
 	RepoName          string
-	UserName          string
+	UserName          string // user, organization or azure devops organization name
+	Host              string // host name of the remote url, i.e. github.com
+	Project           string // azure devops project name. Empty for other hosting platforms
 	repoFormatVersion int
 	remoteUrl         string
+	packsOnce         sync.Once
+	packs             []*packIndex // indexes of the packs in the object database, see [Repository.ReadObject]
+	packsErr          error
+	deltaBases        packCache
 }
 
 func NewRepository(path string) (*Repository, error) {
@@ -39,6 +47,36 @@ func NewRepository(path string) (*Repository, error) {
 	return repo, nil
 }
 
+// SetTarget overrides the repository that issues are reported to, which is derived from the
+// remote url by default. Targets are written as owner/name or, for azure devops, organization/project/name.
+func (repo *Repository) SetTarget(target string) error {
+	parts := strings.Split(strings.Trim(target, "/"), "/")
+	for _, part := range parts {
+		if part == "" {
+			return fmt.Errorf("invalid target repository %q. Expected owner/name", target)
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		repo.UserName, repo.RepoName = parts[0], parts[1]
+	case 3:
+		repo.UserName, repo.Project, repo.RepoName = parts[0], parts[1], parts[2]
+	default:
+		return fmt.Errorf("invalid target repository %q. Expected owner/name", target)
+	}
+
+	return nil
+}
+
+// Target returns the repository that issues are reported to, in the format accepted by [SetTarget]
+func (repo *Repository) Target() string {
+	if repo.Project != "" {
+		return repo.UserName + "/" + repo.Project + "/" + repo.RepoName
+	}
+	return repo.UserName + "/" + repo.RepoName
+}
+
 // recursively move up the file tree till we locate a .git directory
 // or reach the root dir.
 func findRepository(path string) (string, error) {
@@ -137,16 +175,38 @@ func (repo *Repository) extractRemoteUrl(val string) error {
 	return repo.extractRepoDetails()
 }
 
-// extracts the user name and repo name that we will use for reporting
-// issues to different source code hosting platforms
+// extracts the host, user name and repo name that we will use for reporting
+// issues to different source code hosting platforms. The host is not assumed
+// to be github.com so that self hosted instances, such as GitHub Enterprise Server,
+// can be used. Supported formats:
+// https://host/user/repo.git
+// ssh://git@host/user/repo.git
+// git@host:user/repo.git
 func (repo *Repository) extractRepoDetails() error {
-	var buf []byte
+	if isAzureRemote(repo.remoteUrl) {
+		return repo.extractAzureRepoDetails()
+	}
+
+	var host, path string
 
 	switch {
-	case strings.HasPrefix(repo.remoteUrl, "https"):
-		buf = []byte(".com/")
+	case strings.HasPrefix(repo.remoteUrl, "https://"),
+		strings.HasPrefix(repo.remoteUrl, "http://"),
+		strings.HasPrefix(repo.remoteUrl, "ssh://"):
+		u, err := url.Parse(repo.remoteUrl)
+		if err != nil {
+			return err
+		}
+		host, path = u.Host, u.Path
+		if strings.HasPrefix(repo.remoteUrl, "ssh://") {
+			host = u.Hostname()
+		}
 	case strings.HasPrefix(repo.remoteUrl, "git@"):
-		buf = []byte(".com:")
+		hostPath := strings.SplitN(strings.TrimPrefix(repo.remoteUrl, "git@"), ":", 2)
+		if len(hostPath) < 2 {
+			return fmt.Errorf("failed to split url %s by separator :", repo.remoteUrl)
+		}
+		host, path = hostPath[0], hostPath[1]
 	default:
 		return fmt.Errorf(
 			"expected https or ssh protocol but got unexpected url of %s",
@@ -154,15 +214,9 @@ func (repo *Repository) extractRepoDetails() error {
 		)
 	}
 
-	repoDetails := strings.SplitAfter(repo.remoteUrl, string(buf))
-	if len(repoDetails) < 2 {
-		return fmt.Errorf("failed to split url %s by separator %s", repo.remoteUrl, string(buf))
-	}
-
-	rm := repoDetails[1]
-	rm = strings.TrimSuffix(rm, ".git")
-	repoDetails = strings.Split(rm, "/")
-	if len(repoDetails) < 2 {
+	rm := strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	repoDetails := strings.Split(rm, "/")
+	if host == "" || len(repoDetails) < 2 {
 		return fmt.Errorf(
 			"failed to extract username and repo name from remote url %s",
 			repo.remoteUrl,
@@ -170,11 +224,67 @@ func (repo *Repository) extractRepoDetails() error {
 	}
 
 	userName, repoName := repoDetails[0], repoDetails[1]
+	repo.Host = host
 	repo.UserName = userName
 	repo.RepoName = repoName
 	return nil
 }
 
+func isAzureRemote(remoteUrl string) bool {
+	return strings.Contains(remoteUrl, "dev.azure.com")
+}
+
+// azure devops remotes contain an additional project segment and come in the following shapes:
+// https://dev.azure.com/{org}/{project}/_git/{repo}
+// https://{user}@dev.azure.com/{org}/{project}/_git/{repo}
+// git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+func (repo *Repository) extractAzureRepoDetails() error {
+	var segments []string
+
+	switch {
+	case strings.HasPrefix(repo.remoteUrl, "https"):
+		u, err := url.Parse(repo.remoteUrl)
+		if err != nil {
+			return err
+		}
+
+		segments = strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(segments) != 4 || segments[2] != "_git" {
+			return fmt.Errorf(
+				"expected azure devops remote url of https://dev.azure.com/{org}/{project}/_git/{repo} but got %s",
+				repo.remoteUrl,
+			)
+		}
+		segments = []string{segments[0], segments[1], segments[3]}
+	case strings.HasPrefix(repo.remoteUrl, "git@ssh.dev.azure.com:v3/"):
+		rm := strings.TrimPrefix(repo.remoteUrl, "git@ssh.dev.azure.com:v3/")
+		segments = strings.Split(strings.Trim(rm, "/"), "/")
+		if len(segments) != 3 {
+			return fmt.Errorf(
+				"expected azure devops remote url of git@ssh.dev.azure.com:v3/{org}/{project}/{repo} but got %s",
+				repo.remoteUrl,
+			)
+		}
+	default:
+		return fmt.Errorf(
+			"expected https or ssh protocol but got unexpected url of %s",
+			repo.remoteUrl,
+		)
+	}
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return err
+		}
+		segments[i] = strings.TrimSuffix(unescaped, ".git")
+	}
+
+	repo.Host = "dev.azure.com"
+	repo.UserName, repo.Project, repo.RepoName = segments[0], segments[1], segments[2]
+	return nil
+}
+
 // the only two properties we care about in the config file.
 // remote url will be used to extract the user name and repo name
 func (repo *Repository) ok() bool {
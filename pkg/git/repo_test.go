@@ -1,6 +1,9 @@
 package git_test
 
 import (
+	"bytes"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -24,3 +27,150 @@ func TestNewRepositoryWorkDirPath(t *testing.T) {
 	require.Equal(t, "AntoninoAdornetto", actual.UserName)
 	require.Equal(t, "issue-summoner", actual.RepoName)
 }
+
+func TestNewRepositoryRemoteHost(t *testing.T) {
+	testCases := []struct {
+		name      string
+		remoteUrl string
+		host      string
+	}{
+		{
+			name:      "Should extract the host from a github.com https remote",
+			remoteUrl: "https://github.com/AntoninoAdornetto/issue-summoner.git",
+			host:      "github.com",
+		},
+		{
+			name:      "Should extract the host from a GitHub Enterprise Server https remote",
+			remoteUrl: "https://git.corp.example/AntoninoAdornetto/issue-summoner.git",
+			host:      "git.corp.example",
+		},
+		{
+			name:      "Should extract the host from a GitHub Enterprise Server ssh remote",
+			remoteUrl: "git@git.corp.example:AntoninoAdornetto/issue-summoner.git",
+			host:      "git.corp.example",
+		},
+		{
+			name:      "Should extract the host from an ssh remote with a scheme and port",
+			remoteUrl: "ssh://git@git.corp.example:7999/AntoninoAdornetto/issue-summoner.git",
+			host:      "git.corp.example",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := git.NewRepository(newTestRepository(t, tc.remoteUrl))
+			require.NoError(t, err)
+			require.Equal(t, tc.host, actual.Host)
+			require.Equal(t, "AntoninoAdornetto", actual.UserName)
+			require.Equal(t, "issue-summoner", actual.RepoName)
+		})
+	}
+}
+
+func TestNewRepositoryAzureRemote(t *testing.T) {
+	testCases := []struct {
+		name      string
+		remoteUrl string
+		userName  string
+		project   string
+		repoName  string
+	}{
+		{
+			name:      "Should extract the org, project and repo name from an azure devops https remote",
+			remoteUrl: "https://dev.azure.com/contoso/Fabrikam%20Fiber/_git/issue-summoner",
+			userName:  "contoso",
+			project:   "Fabrikam Fiber",
+			repoName:  "issue-summoner",
+		},
+		{
+			name:      "Should extract the org, project and repo name from an azure devops https remote with a user",
+			remoteUrl: "https://contoso@dev.azure.com/contoso/fabrikam/_git/issue-summoner",
+			userName:  "contoso",
+			project:   "fabrikam",
+			repoName:  "issue-summoner",
+		},
+		{
+			name:      "Should extract the org, project and repo name from an azure devops ssh remote",
+			remoteUrl: "git@ssh.dev.azure.com:v3/contoso/fabrikam/issue-summoner",
+			userName:  "contoso",
+			project:   "fabrikam",
+			repoName:  "issue-summoner",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepository(t, tc.remoteUrl)
+
+			actual, err := git.NewRepository(dir)
+			require.NoError(t, err)
+			require.Equal(t, tc.userName, actual.UserName)
+			require.Equal(t, tc.project, actual.Project)
+			require.Equal(t, tc.repoName, actual.RepoName)
+		})
+	}
+}
+
+// newTestRepository creates a temporary work tree containing a .git directory
+// with a minimal config file that points to the provided remote url
+func newTestRepository(t *testing.T, remoteUrl string) string {
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	require.NoError(t, os.MkdirAll(gitDir, 0755))
+
+	config := "[core]\n\trepositoryformatversion = 0\n[remote \"origin\"]\n\turl = " + remoteUrl + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "config"), []byte(config), 0644))
+	return dir
+}
+
+// newGitRepository initializes a repository with the git executable, which is required by the
+// functions that read the history or the index. The test is skipped when git is not installed.
+func newGitRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "remote", "add", "origin", "https://github.com/acme/api.git")
+	return dir
+}
+
+// runGit runs a git command in [dir] with a fixed identity and returns its output
+func runGit(t *testing.T, dir string, args ...string) string {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+	return string(out)
+}
+
+// setTestConfigDir points the user configuration directory at a temporary directory
+// so that tests never read or write the config.json file of the user running them
+func setTestConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", "file")
+}
+
+func TestRepositorySetTarget(t *testing.T) {
+	repo, err := git.NewRepository(newTestRepository(t, "https://github.com/AntoninoAdornetto/issue-summoner.git"))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetTarget("acme/tracker"))
+	require.Equal(t, "acme", repo.UserName)
+	require.Equal(t, "tracker", repo.RepoName)
+
+	require.NoError(t, repo.SetTarget("acme/platform/tracker"))
+	require.Equal(t, "platform", repo.Project)
+
+	require.Error(t, repo.SetTarget("tracker"))
+	require.Error(t, repo.SetTarget("acme//tracker"))
+}
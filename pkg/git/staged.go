@@ -0,0 +1,60 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// StagedFile is a file that differs between the index and HEAD
+type StagedFile struct {
+	Path    string // relative to the work tree
+	Deleted bool   // the file was removed from the index
+}
+
+// StagedFiles returns the files that are staged for the next commit. Renamed files are reported
+// as the deletion of the old path and the addition of the new path.
+func (repo *Repository) StagedFiles(ctx context.Context) ([]StagedFile, error) {
+	out, err := repo.git(ctx, "diff", "--cached", "--name-status", "--no-renames", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	files := make([]StagedFile, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		files = append(files, StagedFile{Path: fields[i+1], Deleted: fields[i] == "D"})
+	}
+
+	return files, nil
+}
+
+// StagedEntries returns the entries of the index that are staged for the next commit, keyed by their
+// path. Paths with merge conflicts are skipped, as are submodules and symlinks, whose objects are not
+// source files. Submodule commits are not even in the object database of the repository. The index is
+// read once, rather than for every file that is passed to [Repository.ReadStaged].
+func (repo *Repository) StagedEntries() (map[string]IndexEntry, error) {
+	entries, err := repo.ReadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	staged := make(map[string]IndexEntry, len(entries))
+	for _, entry := range entries {
+		if entry.Stage == 0 && entry.Regular() {
+			staged[entry.Path] = entry
+		}
+	}
+	return staged, nil
+}
+
+// ReadStaged returns the contents of a file in the index, rather than the work tree, which
+// is what the next commit will contain. [staged] is returned by [Repository.StagedEntries].
+func (repo *Repository) ReadStaged(staged map[string]IndexEntry, path string) ([]byte, error) {
+	entry, ok := staged[filepath.ToSlash(path)]
+	if !ok {
+		return nil, fmt.Errorf("%s is not staged", path)
+	}
+	return repo.ReadBlob(entry.Hash)
+}
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+)
+
+type tokenSource = string
+
+const (
+	TokenSourceFile   tokenSource = "token file"
+	TokenSourceEnv    tokenSource = "environment variable"
+	TokenSourceHelper tokenSource = "credential helper"
+	TokenSourceConfig tokenSource = "config"
+)
+
+// ManagerOptions contains settings, typically provided by command line flags,
+// that change how a [GitManager] is created. Client, the base urls and OpenBrowser
+// allow tests to point a [GitManager] at a fake server, see the gittest package.
+type ManagerOptions struct {
+	TokenFile   string                 // path to a file containing an access token
+	Profile     string                 // name of the profile to use, see [SelectProfile]
+	Context     context.Context        // cancels requests that are in flight, i.e. when the user presses ctrl+c
+	Client      *common.Client         // sends requests to the source code host. Defaults to [common.DefaultClient]
+	BaseUrl     string                 // overrides the base url of the host and profile
+	ApiBaseUrl  string                 // overrides the api base url of the host and profile
+	OpenBrowser func(url string) error // opens verification pages. Defaults to [common.OpenBrowser]
+	DryRun      io.Writer              // when set, [GitManager.Report] writes the payload of each issue to DryRun instead of creating it
+}
+
+func (opts ManagerOptions) context() context.Context {
+	if opts.Context == nil {
+		return context.Background()
+	}
+	return opts.Context
+}
+
+// request sends the request with the client of the options and the context of the options
+func (opts ManagerOptions) request(method, url string, body io.Reader, h http.Header) (*http.Response, []byte, error) {
+	client := opts.Client
+	if client == nil {
+		client = common.DefaultClient
+	}
+	return client.Do(opts.context(), method, url, body, h)
+}
+
+// dryRun writes the payload of a request to [ManagerOptions.DryRun], preceded by the method and
+// destination of the request, and reports whether the request should be skipped. Json payloads are indented.
+func (opts ManagerOptions) dryRun(method, dest string, payload []byte) (bool, error) {
+	if opts.DryRun == nil {
+		return false, nil
+	}
+
+	indented := bytes.Buffer{}
+	if err := json.Indent(&indented, payload, "", "  "); err != nil {
+		indented.Reset()
+		indented.Write(payload)
+	}
+
+	_, err := fmt.Fprintf(opts.DryRun, "%s %s\n%s\n\n", method, dest, indented.String())
+	return true, err
+}
+
+func (opts ManagerOptions) openBrowser(url string) error {
+	if opts.OpenBrowser == nil {
+		return common.OpenBrowser(url)
+	}
+	return opts.OpenBrowser(url)
+}
+
+// tokenRequest describes where an access token for a host may be located
+type tokenRequest struct {
+	host      string            // host name, used to build the ISSUE_SUMMONER_<HOST>_TOKEN env variable
+	tokenFile string            // --token-file flag value
+	source    string            // token source of the profile, i.e. env:GITHUB_WORK_TOKEN
+	envVars   []string          // platform specific env variables, i.e. GITHUB_TOKEN
+	helper    string            // credential helper command, i.e. gh auth token --hostname {host}
+	stored    common.AuthConfig // access token that was stored by <issue-summoner authorize>
+}
+
+// resolveToken locates an access token using the following precedence:
+//
+//  1. the file provided with the --token-file flag
+//  2. the token source of the profile, see [resolveProfileToken]
+//  3. the ISSUE_SUMMONER_<HOST>_TOKEN env variable
+//  4. platform specific env variables, such as GITHUB_TOKEN
+//  5. the credential helper command configured for the host
+//  6. the token that was stored by <issue-summoner authorize>, see [common.AuthConfig.Token]
+//
+// An empty token is returned, without an error, when none of the sources contain a token.
+func resolveToken(req tokenRequest) (string, tokenSource, error) {
+	if req.tokenFile != "" {
+		token, err := readTokenFile(req.tokenFile)
+		return token, TokenSourceFile, err
+	}
+
+	if req.source != "" {
+		return resolveProfileToken(req)
+	}
+
+	envVars := append([]string{HostTokenEnv(req.host)}, req.envVars...)
+	for _, key := range envVars {
+		if token := strings.TrimSpace(os.Getenv(key)); token != "" {
+			return token, TokenSourceEnv, nil
+		}
+	}
+
+	if req.helper != "" {
+		token, err := runCredentialHelper(req.helper, req.host)
+		if err != nil {
+			return "", TokenSourceHelper, err
+		}
+		return token, TokenSourceHelper, nil
+	}
+
+	return storedToken(req)
+}
+
+// storedToken reads the token that was stored by <issue-summoner authorize>. A token that can no
+// longer be found in the credential store, i.e. it was removed from the keyring, is treated as missing
+// so that <issue-summoner authorize> can replace it. Other errors, such as a wrong passphrase for the
+// encrypted store, are returned.
+func storedToken(req tokenRequest) (string, tokenSource, error) {
+	token, err := req.stored.Token()
+	if errors.Is(err, common.ErrCredentialNotFound) {
+		return "", TokenSourceConfig, nil
+	}
+	return token, TokenSourceConfig, err
+}
+
+// resolveProfileToken reads the access token from the token source of a profile. Sources are
+// written as env:<NAME>, file:<PATH>, helper:<COMMAND> or store, which uses the token that
+// was stored by <issue-summoner authorize>.
+func resolveProfileToken(req tokenRequest) (string, tokenSource, error) {
+	kind, value, _ := strings.Cut(req.source, ":")
+	value = strings.TrimSpace(value)
+
+	switch kind {
+	case "env":
+		return strings.TrimSpace(os.Getenv(value)), TokenSourceEnv, nil
+	case "file":
+		token, err := readTokenFile(value)
+		return token, TokenSourceFile, err
+	case "helper":
+		token, err := runCredentialHelper(value, req.host)
+		return token, TokenSourceHelper, err
+	case "store":
+		return storedToken(req)
+	}
+
+	return "", "", fmt.Errorf(
+		"unsupported token source %q. Expected env:<NAME>, file:<PATH>, helper:<COMMAND> or store",
+		req.source,
+	)
+}
+
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+// HostTokenEnv returns the name of the env variable that can be used to provide an access
+// token for a specific host. Characters that are not letters or digits are replaced with
+// underscores, i.e. git.corp.example becomes ISSUE_SUMMONER_GIT_CORP_EXAMPLE_TOKEN
+func HostTokenEnv(host string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, host)
+
+	return "ISSUE_SUMMONER_" + normalized + "_TOKEN"
+}
+
+// runCredentialHelper executes a command, such as "gh auth token --hostname {host}", with the shell,
+// like git runs its credential helpers, and uses the trimmed output as the access token. The {host}
+// placeholder is passed to the shell as an argument, rather than being pasted into the command, so
+// that the host of a remote url can not inject commands.
+func runCredentialHelper(helper, host string) (string, error) {
+	if strings.TrimSpace(helper) == "" {
+		return "", errors.New("credential helper command is empty")
+	}
+
+	script := strings.ReplaceAll(helper, "{host}", `"$1"`)
+	out, err := exec.Command("sh", "-c", script, "sh", host).Output()
+	if err != nil {
+		return "", fmt.Errorf("credential helper <%s> failed: %w", helper, err)
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("credential helper <%s> did not return a token", helper)
+	}
+
+	return token, nil
+}
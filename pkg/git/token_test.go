@@ -0,0 +1,128 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostTokenEnv(t *testing.T) {
+	require.Equal(t, "ISSUE_SUMMONER_GITHUB_COM_TOKEN", git.HostTokenEnv("github.com"))
+	require.Equal(t, "ISSUE_SUMMONER_GIT_CORP_EXAMPLE_8443_TOKEN", git.HostTokenEnv("git.corp.example:8443"))
+}
+
+func TestTokenSources(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("fine-grained-token\n"), 0600))
+
+	testCases := []struct {
+		name          string
+		remoteUrl     string
+		env           map[string]string
+		auth          func(t *testing.T) common.AuthConfig // token stored by <issue-summoner authorize>
+		helper        string                               // credential helper of the host
+		opts          git.ManagerOptions
+		authenticated bool
+		err           bool
+	}{
+		{
+			name:          "Should not be authenticated when there are no token sources",
+			remoteUrl:     "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			authenticated: false,
+		},
+		{
+			name:          "Should not send the GITHUB_TOKEN env variable to enterprise servers",
+			remoteUrl:     "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			env:           map[string]string{"GITHUB_TOKEN": "fine-grained-token"},
+			authenticated: false,
+		},
+		{
+			name:          "Should read the token from the host specific env variable",
+			remoteUrl:     "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			env:           map[string]string{"ISSUE_SUMMONER_GIT_TEST_TOKEN": "fine-grained-token"},
+			authenticated: true,
+		},
+		{
+			name:          "Should run the credential helper with the shell",
+			remoteUrl:     "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			helper:        `test {host} = git.test && printf '%s\n' "fine-grained-token"`,
+			authenticated: true,
+		},
+		{
+			name:          "Should read the token from the token file",
+			remoteUrl:     "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			opts:          git.ManagerOptions{TokenFile: tokenFile},
+			authenticated: true,
+		},
+		{
+			name:      "Should return an error when the token file does not exist",
+			remoteUrl: "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			opts:      git.ManagerOptions{TokenFile: filepath.Join(t.TempDir(), "missing")},
+			err:       true,
+		},
+		{
+			name:      "Should not be authenticated when the stored token was removed from the credential store",
+			remoteUrl: "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			auth: func(t *testing.T) common.AuthConfig {
+				t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", common.CredentialStoreFile)
+				return common.AuthConfig{CredentialStore: common.CredentialStoreFile, CredentialKey: "github/git.test"}
+			},
+			authenticated: false,
+		},
+		{
+			name:      "Should return an error when the stored token can not be decrypted",
+			remoteUrl: "git@git.test:AntoninoAdornetto/issue-summoner.git",
+			auth: func(t *testing.T) common.AuthConfig {
+				t.Setenv("ISSUE_SUMMONER_CREDENTIAL_STORE", common.CredentialStoreEncrypted)
+				t.Setenv("ISSUE_SUMMONER_PASSPHRASE", "correct horse")
+
+				auth, err := common.NewAuthConfig("github/git.test", "fine-grained-token")
+				require.NoError(t, err)
+
+				t.Setenv("ISSUE_SUMMONER_PASSPHRASE", "wrong passphrase")
+				return auth
+			},
+			err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setTestConfigDir(t)
+			t.Setenv("GITHUB_TOKEN", "")
+			auth := common.AuthConfig{}
+			if tc.auth != nil {
+				auth = tc.auth(t)
+			}
+			writeFakeGithubConfig(t, newFakeGithub(t), auth)
+			if tc.helper != "" {
+				conf, err := common.ReadConfig()
+				require.NoError(t, err)
+
+				hostConf := conf[git.Github].Hosts[fakeGithubHost]
+				hostConf.CredentialHelper = tc.helper
+				conf[git.Github].Hosts[fakeGithubHost] = hostConf
+				require.NoError(t, common.WriteToConfig(conf))
+			}
+			for key, val := range tc.env {
+				t.Setenv(key, val)
+			}
+
+			repo, err := git.NewRepository(newTestRepository(t, tc.remoteUrl))
+			require.NoError(t, err)
+
+			manager, err := git.NewGitManager(git.Github, repo, tc.opts)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.authenticated, manager.Authenticated())
+		})
+	}
+}
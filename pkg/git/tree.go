@@ -0,0 +1,156 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TreeEntry is a file of a commit, along with the blob that contains its contents
+type TreeEntry struct {
+	Path string // relative to the root of the tree, separated by forward slashes
+	Mode uint32
+	Hash Hash
+}
+
+// Regular reports whether the entry is a file, rather than a symlink or submodule
+func (entry TreeEntry) Regular() bool {
+	return entry.Mode == ModeFile || entry.Mode == ModeExecutable
+}
+
+// ReadTree returns every file of a commit, including the files of its sub directories, in the
+// order of their paths. Submodules are returned as entries, their contents are not read.
+func (repo *Repository) ReadTree(commit Hash) ([]TreeEntry, error) {
+	tree, err := repo.commitTree(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TreeEntry, 0)
+	return entries, repo.walkTree(tree, "", &entries)
+}
+
+// ReadCommitted returns the contents of a file in a commit, such as HEAD
+func (repo *Repository) ReadCommitted(rev, name string) ([]byte, error) {
+	commit, err := repo.ResolveRevision(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := repo.commitTree(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(path.Clean(strings.TrimPrefix(name, "/")), "/")
+	for i, part := range parts {
+		entries, err := repo.readTreeObject(tree)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, entry := range entries {
+			if entry.Path != part {
+				continue
+			}
+
+			if i == len(parts)-1 && entry.Regular() {
+				return repo.ReadBlob(entry.Hash)
+			} else if i < len(parts)-1 && entry.Mode == ModeDir {
+				tree, found = entry.Hash, true
+			}
+			break
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s does not exist in %s", ErrObjectNotFound, name, rev)
+}
+
+func (repo *Repository) commitTree(hash Hash) (Hash, error) {
+	commit, err := repo.ReadCommit(hash)
+	if err != nil {
+		return Hash{}, err
+	}
+	return commit.Tree, nil
+}
+
+func (repo *Repository) walkTree(tree Hash, dir string, entries *[]TreeEntry) error {
+	children, err := repo.readTreeObject(tree)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		child.Path = path.Join(dir, child.Path)
+		if child.Mode != ModeDir {
+			*entries = append(*entries, child)
+			continue
+		}
+
+		if err := repo.walkTree(child.Hash, child.Path, entries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readTreeObject returns the entries of a single tree, whose paths are the names of the entries
+func (repo *Repository) readTreeObject(tree Hash) ([]TreeEntry, error) {
+	kind, data, err := repo.ReadObject(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != ObjectTree {
+		return nil, fmt.Errorf("object %s is a %s, not a %s", tree, kind, ObjectTree)
+	}
+
+	entries, err := parseTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tree %s: %w", tree, err)
+	}
+	return entries, nil
+}
+
+// parseTree parses the entries of a tree, each of which is an octal mode, a name and the binary
+// object name of a blob, tree or submodule commit: <mode> <name>\0<hash>
+func parseTree(data []byte) ([]TreeEntry, error) {
+	entries := make([]TreeEntry, 0)
+	for len(data) > 0 {
+		mode, rest, ok := bytes.Cut(data, []byte{' '})
+		if !ok {
+			return nil, errors.New("missing mode")
+		}
+
+		name, rest, ok := bytes.Cut(rest, []byte{0})
+		if !ok || len(rest) < len(Hash{}) {
+			return nil, errors.New("truncated entry")
+		}
+
+		value, err := strconv.ParseUint(string(mode), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mode %q", mode)
+		}
+
+		// names are a single path component, which prevents entries from escaping their tree
+		if len(name) == 0 || bytes.ContainsRune(name, '/') || string(name) == "." || string(name) == ".." {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+
+		entry := TreeEntry{Path: string(name), Mode: uint32(value)}
+		copy(entry.Hash[:], rest)
+		entries = append(entries, entry)
+		data = rest[len(Hash{}):]
+	}
+
+	return entries, nil
+}
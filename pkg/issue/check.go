@@ -0,0 +1,110 @@
+package issue
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	RuleRequireIssue  = "require-issue"  // annotations must reference a reported issue, i.e. @TODO(#12)
+	RuleMaxCount      = "max-count"      // the number of annotations may not exceed a budget
+	RuleForbiddenPath = "forbidden-path" // annotations may not reside in certain files
+	RuleMaxAge        = "max-age"        // annotations may not be older than a duration
+)
+
+// Rules are the policy of a single annotation that is enforced by [Check]. The zero value
+// of each rule disables it.
+type Rules struct {
+	RequireIssue   bool
+	MaxCount       int           // maximum number of reported and unreported annotations
+	ForbiddenPaths []string      // doublestar globs, relative to the working tree
+	MaxAge         time.Duration // age of the line that contains the annotation, see [AgeFunc]
+}
+
+// AgeFunc returns how long ago the line of an issue was last changed. False is returned
+// when the age is not known, such as when the file has not been committed.
+type AgeFunc func(issue Issue) (time.Duration, bool)
+
+// Violation is an annotation that does not comply with the [Rules]. Path and Line are
+// empty for violations of the whole repository, such as [RuleMaxCount].
+type Violation struct {
+	Rule       string
+	Annotation string
+	Path       string
+	Line       int
+	Message    string
+}
+
+// Check evaluates the rules against the issues that were located by [Walk] in scan mode
+// [unreported] and purge mode [reported]. Violations are sorted by path and line number.
+func Check(annotation string, unreported, reported []Issue, rules Rules, age AgeFunc) ([]Violation, error) {
+	for _, pattern := range rules.ForbiddenPaths {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+
+	violations := make([]Violation, 0)
+	newViolation := func(rule string, issue Issue, msg string) Violation {
+		return Violation{
+			Rule:       rule,
+			Annotation: annotation,
+			Path:       filepath.ToSlash(issue.FilePath),
+			Line:       issue.LineNumber,
+			Message:    msg,
+		}
+	}
+
+	if count := len(unreported) + len(reported); rules.MaxCount > 0 && count > rules.MaxCount {
+		violations = append(violations, Violation{
+			Rule:       RuleMaxCount,
+			Annotation: annotation,
+			Message:    fmt.Sprintf("found %d %s annotations, the maximum is %d", count, annotation, rules.MaxCount),
+		})
+	}
+
+	if rules.RequireIssue {
+		for _, issue := range unreported {
+			violations = append(violations, newViolation(RuleRequireIssue, issue,
+				fmt.Sprintf("%s %s does not reference an issue", annotation, issue.Title)))
+		}
+	}
+
+	for _, issue := range append(unreported[:len(unreported):len(unreported)], reported...) {
+		path := filepath.ToSlash(issue.FilePath)
+		for _, pattern := range rules.ForbiddenPaths {
+			if match, _ := doublestar.Match(pattern, path); match {
+				violations = append(violations, newViolation(RuleForbiddenPath, issue,
+					fmt.Sprintf("%s annotations are not allowed in %s", annotation, pattern)))
+				break
+			}
+		}
+
+		if rules.MaxAge <= 0 || age == nil {
+			continue
+		}
+
+		if issueAge, ok := age(issue); ok && issueAge > rules.MaxAge {
+			violations = append(violations, newViolation(RuleMaxAge, issue,
+				fmt.Sprintf("%s %s is %d days old, the maximum is %d days",
+					annotation, issue.Title, days(issueAge), days(rules.MaxAge))))
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].Path != violations[j].Path {
+			return violations[i].Path < violations[j].Path
+		}
+		return violations[i].Line < violations[j].Line
+	})
+
+	return violations, nil
+}
+
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
@@ -0,0 +1,93 @@
+package issue_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	unreported := []issue.Issue{
+		{Title: "cache responses", FilePath: "src/api/client.go", LineNumber: 12},
+		{Title: "remove debug flag", FilePath: "cmd/root.go", LineNumber: 4},
+	}
+	reported := []issue.Issue{
+		{Title: "retry uploads", FilePath: "src/api/upload.go", LineNumber: 30},
+	}
+
+	ages := map[string]time.Duration{
+		"src/api/client.go": 100 * 24 * time.Hour,
+		"src/api/upload.go": 10 * 24 * time.Hour,
+	}
+	age := func(iss issue.Issue) (time.Duration, bool) {
+		d, ok := ages[iss.FilePath]
+		return d, ok
+	}
+
+	testCases := []struct {
+		name     string
+		rules    issue.Rules
+		expected []issue.Violation
+	}{
+		{
+			name:     "no rules",
+			expected: []issue.Violation{},
+		},
+		{
+			name:  "require issue",
+			rules: issue.Rules{RequireIssue: true},
+			expected: []issue.Violation{
+				{Rule: issue.RuleRequireIssue, Path: "cmd/root.go", Line: 4},
+				{Rule: issue.RuleRequireIssue, Path: "src/api/client.go", Line: 12},
+			},
+		},
+		{
+			name:     "max count within budget",
+			rules:    issue.Rules{MaxCount: 3},
+			expected: []issue.Violation{},
+		},
+		{
+			name:     "max count exceeded",
+			rules:    issue.Rules{MaxCount: 2},
+			expected: []issue.Violation{{Rule: issue.RuleMaxCount}},
+		},
+		{
+			name:  "forbidden paths",
+			rules: issue.Rules{ForbiddenPaths: []string{"cmd/**", "**/upload.go"}},
+			expected: []issue.Violation{
+				{Rule: issue.RuleForbiddenPath, Path: "cmd/root.go", Line: 4},
+				{Rule: issue.RuleForbiddenPath, Path: "src/api/upload.go", Line: 30},
+			},
+		},
+		{
+			name:  "max age skips unknown ages",
+			rules: issue.Rules{MaxAge: 90 * 24 * time.Hour},
+			expected: []issue.Violation{
+				{Rule: issue.RuleMaxAge, Path: "src/api/client.go", Line: 12},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations, err := issue.Check("@FIX", unreported, reported, tc.rules, age)
+			require.NoError(t, err)
+			require.Len(t, violations, len(tc.expected))
+
+			for i, expected := range tc.expected {
+				require.Equal(t, expected.Rule, violations[i].Rule)
+				require.Equal(t, expected.Path, violations[i].Path)
+				require.Equal(t, expected.Line, violations[i].Line)
+				require.Equal(t, "@FIX", violations[i].Annotation)
+				require.NotEmpty(t, violations[i].Message)
+			}
+		})
+	}
+}
+
+func TestCheckInvalidPattern(t *testing.T) {
+	_, err := issue.Check("@FIX", nil, nil, issue.Rules{ForbiddenPaths: []string{"src/[a"}}, nil)
+	require.Error(t, err)
+}
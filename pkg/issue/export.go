@@ -0,0 +1,207 @@
+package issue
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportVersion is the version of the schema of exported issues. Fields may be added without
+// changing the version, it is incremented when fields are removed or change meaning.
+const ExportVersion = 1
+
+const (
+	FormatJSON   = "json"   // a single document, see [Export]
+	FormatNDJSON = "ndjson" // one issue per line, each containing the version of the schema
+	FormatCSV    = "csv"    // a header row followed by one row per issue
+	FormatYAML   = "yaml"   // a single document, see [Export]
+	FormatSARIF  = "sarif"  // unreported and overdue issues as code scanning alerts, see [Export.writeSARIF]
+)
+
+// Formats are the supported export formats
+var Formats = []string{FormatJSON, FormatNDJSON, FormatCSV, FormatYAML, FormatSARIF}
+
+type Status = string
+
+const (
+	StatusUnreported Status = "unreported" // the annotation was located in scan mode
+	StatusOpen       Status = "open"       // the reported issue has not been resolved
+	StatusResolved   Status = "resolved"   // the reported issue was resolved and its comment purged
+	StatusUnknown    Status = "unknown"    // the status could not be retrieved from the source code host
+)
+
+// Export is the document that is written by the json and yaml formats
+type Export struct {
+	Version    int             `json:"version" yaml:"version"`
+	Mode       IssueMode       `json:"mode" yaml:"mode"`
+	Annotation string          `json:"annotation" yaml:"annotation"`
+	Issues     []ExportedIssue `json:"issues" yaml:"issues"`
+}
+
+// ExportedIssue is the schema of a single issue
+type ExportedIssue struct {
+	ID          string `json:"id" yaml:"id"` // location of the fingerprint, see [common.Fingerprint]
+	Path        string `json:"path" yaml:"path"`
+	Line        int    `json:"line" yaml:"line"`
+	Column      int    `json:"column" yaml:"column"`
+	EndLine     int    `json:"endLine" yaml:"endLine"`
+	EndColumn   int    `json:"endColumn" yaml:"endColumn"` // column after the last character of the comment
+	Annotation  string `json:"annotation" yaml:"annotation"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description" yaml:"description"`
+	IssueNumber int    `json:"issueNumber" yaml:"issueNumber"` // 0 when the issue has not been reported
+	Status      Status `json:"status" yaml:"status"`
+	Priority    string `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Due         string `json:"due,omitempty" yaml:"due,omitempty"` // formatted with [DueDateLayout]
+	Overdue     bool   `json:"overdue" yaml:"overdue"`
+	Commit      string `json:"commit,omitempty" yaml:"commit,omitempty"` // empty when the working tree was scanned
+	Author      string `json:"author,omitempty" yaml:"author,omitempty"` // empty when the issue was not attributed, see [IssueManager.Attribute]
+	AuthorEmail string `json:"authorEmail,omitempty" yaml:"authorEmail,omitempty"`
+	CommitSHA   string `json:"commitSha,omitempty" yaml:"commitSha,omitempty"` // commit that last changed the line of the annotation
+	CreatedAt   string `json:"createdAt,omitempty" yaml:"createdAt,omitempty"` // formatted with [time.RFC3339]
+}
+
+// ndjsonIssue embeds the version in each line since there is no enclosing document
+type ndjsonIssue struct {
+	Version int `json:"version"`
+	ExportedIssue
+}
+
+var csvHeader = []string{
+	"version", "id", "path", "line", "column", "annotation", "title", "description", "issueNumber", "status",
+	"endLine", "endColumn", "priority", "due", "overdue", "commit",
+	"author", "authorEmail", "commitSha", "createdAt",
+}
+
+// Export converts the issues that were located by [Walk] into the export schema. [statuses] maps the index
+// of an issue to the status that was retrieved from the source code host, issues without a status are
+// unreported in scan mode and unknown in purge mode. Issues are overdue when their due date is before today.
+func (mngr *IssueManager) Export(statuses map[int]Status) Export {
+	now := time.Now()
+	export := Export{
+		Version:    ExportVersion,
+		Mode:       mngr.mode,
+		Annotation: string(mngr.annotation),
+		Issues:     make([]ExportedIssue, 0, len(mngr.Issues)),
+	}
+
+	for i, issue := range mngr.Issues {
+		status, ok := statuses[i]
+		if !ok {
+			status = StatusUnknown
+			if mngr.mode != IssueModePurge {
+				status = StatusUnreported
+			}
+		}
+
+		exported := ExportedIssue{
+			ID:          issue.ID,
+			Path:        issue.FilePath,
+			Line:        issue.LineNumber,
+			Column:      issue.Column,
+			EndLine:     issue.EndLine,
+			EndColumn:   issue.EndColumn,
+			Annotation:  export.Annotation,
+			Title:       issue.Title,
+			Description: issue.Description,
+			Status:      status,
+			Priority:    issue.Priority,
+			Overdue:     issue.Overdue(now),
+			Commit:      issue.Commit,
+			Author:      issue.Author,
+			AuthorEmail: issue.AuthorEmail,
+			CommitSHA:   issue.CommitSHA,
+		}
+
+		if !issue.Due.IsZero() {
+			exported.Due = issue.Due.Format(DueDateLayout)
+		}
+
+		if !issue.CreatedAt.IsZero() {
+			exported.CreatedAt = issue.CreatedAt.Format(time.RFC3339)
+		}
+
+		if issue.Comment != nil {
+			exported.IssueNumber = issue.Comment.IssueNumber
+		}
+
+		export.Issues = append(export.Issues, exported)
+	}
+
+	return export
+}
+
+// Write encodes the export in one of the [Formats]
+func (export Export) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(export)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, issue := range export.Issues {
+			if err := enc.Encode(ndjsonIssue{Version: export.Version, ExportedIssue: issue}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatCSV:
+		return export.writeCSV(w)
+	case FormatSARIF:
+		return export.writeSARIF(w)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(export); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported format %q. Expected one of %v", format, Formats)
+	}
+}
+
+func (export Export) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(export.Version)
+	for _, issue := range export.Issues {
+		err := writer.Write([]string{
+			version,
+			issue.ID,
+			issue.Path,
+			strconv.Itoa(issue.Line),
+			strconv.Itoa(issue.Column),
+			issue.Annotation,
+			issue.Title,
+			issue.Description,
+			strconv.Itoa(issue.IssueNumber),
+			issue.Status,
+			strconv.Itoa(issue.EndLine),
+			strconv.Itoa(issue.EndColumn),
+			issue.Priority,
+			issue.Due,
+			strconv.FormatBool(issue.Overdue),
+			issue.Commit,
+			issue.Author,
+			issue.AuthorEmail,
+			issue.CommitSHA,
+			issue.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
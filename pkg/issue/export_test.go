@@ -0,0 +1,170 @@
+package issue_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func newExportManager(t *testing.T, mode issue.IssueMode) *issue.IssueManager {
+	root := t.TempDir()
+	src := "package main\n\nfunc main() {\n\t/* @TEST_ANNOTATION(#12) first, with a comma\n\t details */\n}\n\n" +
+		"func run() {\n    /* @TEST_ANNOTATION(#13) second [P1] due:2000-01-31 */\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0644))
+
+	manager, err := issue.NewIssueManager(testAnnotation, mode)
+	require.NoError(t, err)
+	require.NoError(t, manager.Walk(root))
+	require.Len(t, manager.Issues, 2)
+	return manager
+}
+
+func TestExport(t *testing.T) {
+	manager := newExportManager(t, issue.IssueModePurge)
+	export := manager.Export(map[int]issue.Status{0: issue.StatusResolved})
+
+	require.Equal(t, issue.ExportVersion, export.Version)
+	require.Equal(t, issue.IssueModePurge, export.Mode)
+	require.Equal(t, string(testAnnotation), export.Annotation)
+	require.Equal(t, issue.ExportedIssue{
+		ID:          manager.Issues[0].ID,
+		Path:        "main.go",
+		Line:        4,
+		Column:      2,
+		EndLine:     5,
+		EndColumn:   13,
+		Annotation:  string(testAnnotation),
+		Title:       "first, with a comma",
+		Description: "details",
+		IssueNumber: 12,
+		Status:      issue.StatusResolved,
+	}, export.Issues[0])
+
+	// issues without a status could not be checked with the source code host
+	require.Equal(t, 5, export.Issues[1].Column)
+	require.Equal(t, 13, export.Issues[1].IssueNumber)
+	require.Equal(t, issue.StatusUnknown, export.Issues[1].Status)
+	require.Equal(t, issue.PriorityHigh, export.Issues[1].Priority)
+	require.Equal(t, "2000-01-31", export.Issues[1].Due)
+	require.True(t, export.Issues[1].Overdue)
+}
+
+func TestMetadata(t *testing.T) {
+	testCases := []struct {
+		name     string
+		comment  string
+		priority string
+		due      string
+	}{
+		{name: "Should not set metadata by default", comment: "// @TEST_ANNOTATION plain"},
+		{name: "Should parse priority tags", comment: "// @TEST_ANNOTATION [p0] tag", priority: issue.PriorityCritical},
+		{name: "Should parse priority fields", comment: "// @TEST_ANNOTATION priority: low", priority: issue.PriorityLow},
+		{name: "Should ignore unknown priorities", comment: "// @TEST_ANNOTATION priority:someday"},
+		{name: "Should parse due dates", comment: "// @TEST_ANNOTATION due:2031-02-03", due: "2031-02-03"},
+		{name: "Should ignore invalid due dates", comment: "// @TEST_ANNOTATION due:2031-02-30"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			src := "package main\n\n" + tc.comment + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0644))
+
+			manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeScan)
+			require.NoError(t, err)
+			require.NoError(t, manager.Walk(root))
+			require.Len(t, manager.Issues, 1)
+
+			exported := manager.Export(nil).Issues[0]
+			require.Equal(t, tc.priority, exported.Priority)
+			require.Equal(t, tc.due, exported.Due)
+			require.False(t, exported.Overdue)
+		})
+	}
+}
+
+func TestExportWrite(t *testing.T) {
+	export := newExportManager(t, issue.IssueModePurge).Export(nil)
+	// issues that were located in a commit, rather than the working tree, are attributed to it
+	export.Issues[0].Commit = "48f05570af61d02b8a8b13d3d16bd3ab0eb48867"
+	export.Issues[0].Author = "Jane Doe"
+	export.Issues[0].AuthorEmail = "jane@example.com"
+	export.Issues[0].CommitSHA = "1f0c0d9b7a4b7de2bb1e0f3f4a30e1b7a8c1d2e3"
+	export.Issues[0].CreatedAt = "2024-01-02T10:00:00Z"
+
+	testCases := []struct {
+		name   string
+		format string
+		decode func(t *testing.T, data []byte) issue.Export
+	}{
+		{
+			name:   "Should write a json document",
+			format: issue.FormatJSON,
+			decode: func(t *testing.T, data []byte) issue.Export {
+				decoded := issue.Export{}
+				require.NoError(t, json.Unmarshal(data, &decoded))
+				return decoded
+			},
+		},
+		{
+			name:   "Should write a yaml document",
+			format: issue.FormatYAML,
+			decode: func(t *testing.T, data []byte) issue.Export {
+				decoded := issue.Export{}
+				require.NoError(t, yaml.Unmarshal(data, &decoded))
+				return decoded
+			},
+		},
+		{
+			name:   "Should write one versioned json object per line",
+			format: issue.FormatNDJSON,
+			decode: func(t *testing.T, data []byte) issue.Export {
+				decoded := issue.Export{Mode: export.Mode, Annotation: export.Annotation}
+				for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+					var record struct {
+						Version int `json:"version"`
+						issue.ExportedIssue
+					}
+					require.NoError(t, json.Unmarshal([]byte(line), &record))
+					decoded.Version = record.Version
+					decoded.Issues = append(decoded.Issues, record.ExportedIssue)
+				}
+				return decoded
+			},
+		},
+		{
+			name:   "Should write a header and one csv row per issue",
+			format: issue.FormatCSV,
+			decode: func(t *testing.T, data []byte) issue.Export {
+				rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, 3)
+				require.Equal(t, []string{"version", "id", "path", "line", "column"}, rows[0][:5])
+				require.Equal(t, "first, with a comma", rows[1][6])
+				require.Equal(t, "unknown", rows[2][9])
+				require.Equal(t, []string{"commit", export.Issues[0].Commit, ""}, []string{rows[0][15], rows[1][15], rows[2][15]})
+				require.Equal(t, []string{"author", "authorEmail", "commitSha", "createdAt"}, rows[0][16:])
+				require.Equal(t, []string{"Jane Doe", "jane@example.com", export.Issues[0].CommitSHA, "2024-01-02T10:00:00Z"}, rows[1][16:])
+				return export
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			require.NoError(t, export.Write(&buf, tc.format))
+			require.Equal(t, export, tc.decode(t, buf.Bytes()))
+		})
+	}
+
+	require.Error(t, export.Write(&bytes.Buffer{}, "xml"))
+}
@@ -0,0 +1,192 @@
+package issue
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryVersion is the version of the schema of [History]. Fields may be added without changing
+// the version, it is incremented when fields are removed or change meaning.
+const HistoryVersion = 1
+
+// dimensions that the annotations of a snapshot are counted by
+const (
+	DimensionTotal      = "total"
+	DimensionAnnotation = "annotation"
+	DimensionDirectory  = "directory"
+	DimensionAuthor     = "author"
+)
+
+// HistoryFormats are the supported formats of [History.Write]
+var HistoryFormats = []string{FormatJSON, FormatCSV}
+
+var historyHeader = []string{"version", "commit", "date", "tags", "dimension", "key", "count"}
+
+// History is the number of annotations at a sample of commits, oldest first, see <issue-summoner history>
+type History struct {
+	Version   int        `json:"version"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// Snapshot counts the annotations, reported or not, of a single commit
+type Snapshot struct {
+	Commit      string         `json:"commit"`
+	Date        time.Time      `json:"date"` // committer date
+	Tags        []string       `json:"tags,omitempty"`
+	Total       int            `json:"total"`
+	Annotations map[string]int `json:"annotations"`
+	Directories map[string]int `json:"directories"`
+	Authors     map[string]int `json:"authors"` // the author of the line of each annotation, according to blame
+}
+
+// NewSnapshot creates an empty snapshot of a commit
+func NewSnapshot(commit string, date time.Time, tags []string) Snapshot {
+	return Snapshot{
+		Commit:      commit,
+		Date:        date,
+		Tags:        tags,
+		Annotations: make(map[string]int),
+		Directories: make(map[string]int),
+		Authors:     make(map[string]int),
+	}
+}
+
+// Add counts an annotation in the file at [filePath], whose directory is truncated to [depth]
+// directories, see [Directory]
+func (s *Snapshot) Add(annotation, filePath, author string, depth int) {
+	s.Total++
+	s.Annotations[annotation]++
+	s.Directories[Directory(filePath, depth)]++
+	s.Authors[author]++
+}
+
+// Counts returns the counts of a dimension, the total is counted with an empty key
+func (s Snapshot) Counts(dimension string) map[string]int {
+	switch dimension {
+	case DimensionAnnotation:
+		return s.Annotations
+	case DimensionDirectory:
+		return s.Directories
+	case DimensionAuthor:
+		return s.Authors
+	default:
+		return map[string]int{"": s.Total}
+	}
+}
+
+// Directory returns the directory of a file, truncated to the first [depth] directories. Files at
+// the root of the repository are in the "." directory and a depth of 0 keeps every directory.
+func Directory(filePath string, depth int) string {
+	dir := path.Dir(filePath)
+	if dir == "." || depth <= 0 {
+		return dir
+	}
+
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Series returns the counts of a key of a dimension at each snapshot
+func (h History) Series(dimension, key string) []int {
+	series := make([]int, len(h.Snapshots))
+	for i, s := range h.Snapshots {
+		series[i] = s.Counts(dimension)[key]
+	}
+	return series
+}
+
+// Keys returns the keys of a dimension, ordered by their count in the latest snapshot and then by
+// their highest count, so that keys that no longer have annotations are listed last
+func (h History) Keys(dimension string) []string {
+	peak := make(map[string]int)
+	for _, s := range h.Snapshots {
+		for key, count := range s.Counts(dimension) {
+			peak[key] = max(peak[key], count)
+		}
+	}
+
+	latest := map[string]int{}
+	if len(h.Snapshots) > 0 {
+		latest = h.Snapshots[len(h.Snapshots)-1].Counts(dimension)
+	}
+
+	keys := make([]string, 0, len(peak))
+	for key := range peak {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if latest[a] != latest[b] {
+			return latest[a] > latest[b]
+		}
+		if peak[a] != peak[b] {
+			return peak[a] > peak[b]
+		}
+		return a < b
+	})
+	return keys
+}
+
+// Write encodes the history in one of the [HistoryFormats]. The csv format writes a row per
+// snapshot, dimension and key, which can be pivoted by spreadsheets.
+func (h History) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(h)
+	case FormatCSV:
+		return h.writeCSV(w)
+	default:
+		return fmt.Errorf("unsupported format %q. Expected one of %v", format, HistoryFormats)
+	}
+}
+
+func (h History) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(historyHeader); err != nil {
+		return err
+	}
+
+	version := strconv.Itoa(h.Version)
+	dimensions := []string{DimensionTotal, DimensionAnnotation, DimensionDirectory, DimensionAuthor}
+	for _, s := range h.Snapshots {
+		for _, dimension := range dimensions {
+			counts := s.Counts(dimension)
+			keys := make([]string, 0, len(counts))
+			for key := range counts {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				err := writer.Write([]string{
+					version,
+					s.Commit,
+					s.Date.Format(time.RFC3339),
+					strings.Join(s.Tags, " "),
+					dimension,
+					key,
+					strconv.Itoa(counts[key]),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,86 @@
+package issue_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		depth    int
+		expected string
+	}{
+		{name: "Should group files at the root by the root directory", path: "main.go", depth: 1, expected: "."},
+		{name: "Should truncate directories to the depth", path: "pkg/api/v1/client.go", depth: 1, expected: "pkg"},
+		{name: "Should keep directories that are within the depth", path: "pkg/api/client.go", depth: 3, expected: "pkg/api"},
+		{name: "Should keep every directory with a depth of 0", path: "pkg/api/v1/client.go", depth: 0, expected: "pkg/api/v1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, issue.Directory(tc.path, tc.depth))
+		})
+	}
+}
+
+func newTestHistory() issue.History {
+	date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	first := issue.NewSnapshot("a1", date, []string{"v1.0"})
+	first.Add("@TODO", "pkg/api/client.go", "jane", 1)
+	first.Add("@TODO", "cmd/root.go", "john", 1)
+	first.Add("@FIXME", "cmd/scan.go", "john", 1)
+
+	second := issue.NewSnapshot("b2", date.AddDate(0, 1, 0), nil)
+	second.Add("@TODO", "pkg/api/client.go", "jane", 1)
+	second.Add("@TODO", "pkg/git/repo.go", "jane", 1)
+
+	return issue.History{Version: issue.HistoryVersion, Snapshots: []issue.Snapshot{first, second}}
+}
+
+func TestHistorySeries(t *testing.T) {
+	history := newTestHistory()
+
+	require.Equal(t, []int{3, 2}, history.Series(issue.DimensionTotal, ""))
+	require.Equal(t, []int{2, 2}, history.Series(issue.DimensionAnnotation, "@TODO"))
+	require.Equal(t, []int{1, 0}, history.Series(issue.DimensionAnnotation, "@FIXME"))
+	require.Equal(t, []int{1, 2}, history.Series(issue.DimensionDirectory, "pkg"))
+	require.Equal(t, []int{2, 0}, history.Series(issue.DimensionAuthor, "john"))
+
+	// keys that no longer have annotations are ordered last
+	require.Equal(t, []string{"pkg", "cmd"}, history.Keys(issue.DimensionDirectory))
+	require.Equal(t, []string{"jane", "john"}, history.Keys(issue.DimensionAuthor))
+	require.Equal(t, []string{"@TODO", "@FIXME"}, history.Keys(issue.DimensionAnnotation))
+}
+
+func TestHistoryWrite(t *testing.T) {
+	history := newTestHistory()
+
+	buf := bytes.Buffer{}
+	require.NoError(t, history.Write(&buf, issue.FormatJSON))
+
+	decoded := issue.History{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, history, decoded)
+
+	buf.Reset()
+	require.NoError(t, history.Write(&buf, issue.FormatCSV))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"version", "commit", "date", "tags", "dimension", "key", "count"}, rows[0])
+	require.Equal(t, []string{"1", "a1", "2024-01-02T00:00:00Z", "v1.0", "total", "", "3"}, rows[1])
+	require.Equal(t, []string{"1", "a1", "2024-01-02T00:00:00Z", "v1.0", "annotation", "@FIXME", "1"}, rows[2])
+	// a row per snapshot, dimension and key
+	require.Len(t, rows, 1+(1+2+2+2)+(1+1+1+1))
+
+	require.Error(t, history.Write(&bytes.Buffer{}, issue.FormatSARIF))
+}
@@ -34,6 +34,10 @@ AND BATCHED TOGETHER TO AVOID MULTIPLE WRITES. @SEE [WriteIssues] FUNC.
 TO REMOVE THE COMMENTS. COMMENTS ARE REMOVED IF THE ISSUE WAS REPORTED USING <issue-summoner report>
 COMMAND AND THE ISSUE ID WAS WRITTEN BACK TO THE SOURCE FILE. THE ID IS USED TO CHECK THE STATUS
 AND IF RESOLVED, THE COMMENT IS REMOVED. @SEE [Purge] FUNC.
+
+- `LINT`: LOCATES ANNOTATIONS THAT ARE FOLLOWED BY A REFERENCE WHICH DOES NOT MATCH THE WRITE BACK
+FORMAT, SUCH AS @MY_ISSUE_ANNOTATION(#) OR @MY_ISSUE_ANNOTATION(45323). THESE COMMENTS ARE NEITHER
+REPORTED NOR PURGED, SO <issue-summoner scan --staged> REFUSES TO COMMIT THEM.
 */
 package issue
 
@@ -45,13 +49,20 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	ignore "github.com/AntoninoAdornetto/go-gitignore"
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/common"
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/lexer"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 type IssueMode = string
@@ -60,33 +71,103 @@ const (
 	IssueModePurge  IssueMode = "purge"
 	IssueModeReport IssueMode = "report"
 	IssueModeScan   IssueMode = "scan"
+	IssueModeLint   IssueMode = "lint"
 )
 
+// DefaultWriteBack is the format of the issue number that is appended to the annotation
+// of a reported issue, i.e. @TODO(#45323)
+const DefaultWriteBack = "(#%d)"
+
 type IssueManager struct {
 	Issues      []Issue
 	IssueMap    map[string][]IssueMapEntry
 	Annotation  []byte
+	annotation  []byte // annotation prior to appending the purge pattern
+	writeBack   string
+	include     []string
+	exclude     []string
 	currentBase string
 	currentPath string
 	root        string
 	mode        IssueMode
 	os          string
 	template    *template.Template
+	occurrences map[string]int // number of comments with the same fingerprint, see [common.NewFingerprint]
 }
 
 type Issue struct {
-	ID          string // Used as a key for the multi select tui component for issue selection <issue summoner report> cmd
+	ID          string // Location of the fingerprint, which does not change when code moves within the symbol
 	Title       string // Title of the issue
 	Description string // Description of the issue
 	Body        string // Contains the issue body/description to use for the issue filing. Is a markdown template
 	FileName    string // base
 	FilePath    string // relative to the working tree dir
 	LineNumber  int    // Line number of where the comment resides
+	Column      int    // Column of where the comment starts on [LineNumber], starting at 1
+	EndLine     int    // Line number of where the comment ends
+	EndColumn   int    // Column after the last character of the comment on [EndLine]
+	Priority    string // Priority from the comment, i.e. [P1] or priority:high, see [parseMetadata]. Empty when not set
+	Symbol      string // name of the function, method or type that encloses the comment, see [enclosingSymbol]
+	Commit      string // name of the commit that was scanned. Empty when the working tree was scanned
+	// Due date from the comment, i.e. due:2025-01-31. Zero when not set
+	Due         time.Time
+	Author      string    // author of the commit that last changed the line of the annotation, see [IssueManager.Attribute]
+	AuthorEmail string    // email of [Author]
+	CommitSHA   string    // name of the commit that last changed the line, which consists of zeros when it was not committed
+	CreatedAt   time.Time // author time of [CommitSHA]. Zero when the issue was not attributed
+	Owners      []string  // owners of the file according to CODEOWNERS, see [IssueManager.SetOwners]
+	Fingerprint common.Fingerprint
 	OS          string // Used for env section of the issue markdown template
 	Index       int    // index of the issue in [IssueManager.Issues]
 	Comment     *lexer.Comment
 }
 
+// Options change which files are scanned, the body of reported issues and how issue
+// numbers are written back to annotations. Empty options keep the defaults.
+type Options struct {
+	Template  string   // issue body template, see [template.go]
+	Include   []string // doublestar globs, relative to the working tree. Only matching files are scanned
+	Exclude   []string // doublestar globs, relative to the working tree. Matching files and directories are skipped
+	WriteBack string   // format containing a single %d verb, enclosed in parentheses, i.e. (ENG-%d)
+}
+
+// Filter selects issues without prompting the user, i.e. <issue-summoner report --all>.
+// The zero value matches every issue.
+type Filter struct {
+	Path       string         // file or directory, relative to the working tree
+	TitleMatch *regexp.Regexp // matched against the title of the issue
+	Lines      LineSet        // lines that were changed, i.e. <issue-summoner scan --since main>
+	Before     time.Time      // issues created before, i.e. <issue-summoner scan --older-than 90d>. See [Issue.CreatedAt]
+}
+
+// LineSet reports whether any line between [start] and [end] of a file, relative to
+// the working tree, is a member of the set. See [git.Changes]
+type LineSet interface {
+	Overlaps(path string, start, end int) bool
+}
+
+// Match reports whether the issue resides within [Filter.Path], has a title that matches [Filter.TitleMatch],
+// whether its comment overlaps [Filter.Lines] and was created before [Filter.Before]
+func (f Filter) Match(issue Issue) bool {
+	if dir := filepath.ToSlash(filepath.Clean(f.Path)); dir != "." {
+		path := filepath.ToSlash(issue.FilePath)
+		if path != dir && !strings.HasPrefix(path, dir+"/") {
+			return false
+		}
+	}
+
+	if f.Lines != nil && !f.Lines.Overlaps(issue.FilePath, issue.LineNumber, issue.EndLine) {
+		return false
+	}
+
+	// issues that were not attributed are not known to be old
+	if !f.Before.IsZero() && (issue.CreatedAt.IsZero() || !issue.CreatedAt.Before(f.Before)) {
+		return false
+	}
+
+	return f.TitleMatch == nil || f.TitleMatch.MatchString(issue.Title)
+}
+
 type IssueMapEntry struct {
 	Index      int // index of the issue in [IssueManager.Issues]
 	ReportedID int // issue identifier after calling [git.Report] func
@@ -98,11 +179,14 @@ type IssueMapEntry struct {
 // file for a description on the supported modes and their responsibilities.
 func NewIssueManager(annotation []byte, mode IssueMode) (*IssueManager, error) {
 	manager := &IssueManager{
-		Issues:     make([]Issue, 0),
-		IssueMap:   make(map[string][]IssueMapEntry),
-		Annotation: annotation,
-		mode:       mode,
-		os:         runtime.GOOS,
+		Issues:      make([]Issue, 0),
+		IssueMap:    make(map[string][]IssueMapEntry),
+		Annotation:  annotation,
+		annotation:  annotation,
+		writeBack:   DefaultWriteBack,
+		occurrences: make(map[string]int),
+		mode:        mode,
+		os:          runtime.GOOS,
 	}
 
 	switch mode {
@@ -115,30 +199,108 @@ func NewIssueManager(annotation []byte, mode IssueMode) (*IssueManager, error) {
 		}
 		manager.template = tmpl
 	case IssueModePurge:
-		manager.Annotation = append(manager.Annotation, []byte("\\(#\\d+\\)")...)
+		manager.Annotation = purgePattern(annotation, manager.writeBack)
+	case IssueModeLint:
+		manager.Annotation = append(slices.Clone(annotation), []byte(`\(`)...)
 	default:
-		return nil, errors.New("expected mode of \"report\", \"scan\", or \"purge\"")
+		return nil, errors.New("expected mode of \"report\", \"scan\", \"purge\" or \"lint\"")
 	}
 
 	return manager, nil
 }
 
-func (mngr *IssueManager) appendIssue(comment *lexer.Comment) error {
-	id := fmt.Sprintf("%s-%d:%d", mngr.currentPath, comment.TokenStartIndex, comment.TokenEndIndex)
+// Configure applies options, typically read from the project config file, to the manager.
+// It must be invoked prior to [Walk].
+func (mngr *IssueManager) Configure(opts Options) error {
+	if opts.WriteBack != "" {
+		if err := validateWriteBack(opts.WriteBack); err != nil {
+			return err
+		}
+
+		mngr.writeBack = opts.WriteBack
+		if mngr.mode == IssueModePurge {
+			mngr.Annotation = purgePattern(mngr.annotation, mngr.writeBack)
+		}
+	}
+
+	if opts.Template != "" && mngr.mode == IssueModeReport {
+		tmpl, err := template.New("").Parse(opts.Template)
+		if err != nil {
+			return fmt.Errorf("failed to parse issue template: %w", err)
+		}
+		mngr.template = tmpl
+	}
+
+	for _, pattern := range append(slices.Clone(opts.Include), opts.Exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+	}
+
+	mngr.include = opts.Include
+	mngr.exclude = opts.Exclude
+	return nil
+}
+
+// validateWriteBack ensures the issue number can be located by the lexer when purging issues
+func validateWriteBack(format string) error {
+	if strings.Count(format, "%d") != 1 || strings.Count(format, "%") != 1 {
+		return fmt.Errorf("write back format %q must contain exactly one %%d verb", format)
+	}
+
+	inner := strings.TrimSuffix(strings.TrimPrefix(format, "("), ")")
+	if len(inner) != len(format)-2 || strings.ContainsAny(inner, "()") || strings.ContainsAny(inner, " \t\n") {
+		return fmt.Errorf("write back format %q must be enclosed in parentheses, i.e. (#%%d)", format)
+	}
+
+	if strings.ContainsAny(strings.Replace(inner, "%d", "", 1), "0123456789") {
+		return fmt.Errorf("write back format %q may not contain digits other than the %%d verb", format)
+	}
+
+	return nil
+}
+
+// purgePattern matches annotations that were written back using the write back format
+func purgePattern(annotation []byte, format string) []byte {
+	prefix, suffix, _ := strings.Cut(format, "%d")
+	pattern := regexp.QuoteMeta(prefix) + "\\d+" + regexp.QuoteMeta(suffix)
+	return append(slices.Clone(annotation), []byte(pattern)...)
+}
+
+func (mngr *IssueManager) appendIssue(comment *lexer.Comment, src []byte) error {
+	if mngr.mode == IssueModeLint && mngr.wellFormed(comment, src) {
+		return nil
+	}
 
 	rel, err := filepath.Rel(mngr.root, mngr.currentPath)
 	if err != nil {
 		return err
 	}
 
+	symbol := enclosingSymbol(src, comment.NotationStartIndex, comment.NotationEndIndex)
+	text := comment.Title + " " + comment.Description
+	first := common.NewFingerprint(text, symbol, rel, 1)
+	mngr.occurrences[first.Location]++
+	fingerprint := common.NewFingerprint(text, symbol, rel, mngr.occurrences[first.Location])
+
+	priority, due := parseMetadata(text)
+	endLine, endColumn := commentEnd(src, comment)
+
 	issue := Issue{
 		Description: comment.Description,
 		FileName:    mngr.currentBase,
 		FilePath:    rel,
-		ID:          id,
+		ID:          fingerprint.Location,
 		LineNumber:  comment.LineNumber,
+		Column:      column(src, comment.NotationStartIndex),
+		EndLine:     endLine,
+		EndColumn:   endColumn,
+		Priority:    priority,
+		Due:         due,
 		OS:          mngr.os,
 		Title:       comment.Title,
+		Symbol:      symbol,
+		Fingerprint: fingerprint,
 		Comment:     comment,
 	}
 
@@ -146,18 +308,156 @@ func (mngr *IssueManager) appendIssue(comment *lexer.Comment) error {
 		issue.Index = len(mngr.Issues)
 	}
 
-	if mngr.mode == IssueModeReport && mngr.template != nil {
-		buf := bytes.Buffer{}
-		if err := mngr.template.Execute(&buf, issue); err != nil {
+	if err := mngr.render(&issue); err != nil {
+		return err
+	}
+
+	mngr.Issues = append(mngr.Issues, issue)
+	return nil
+}
+
+// render executes the template against the issue, in report mode, and appends the fingerprint marker
+func (mngr *IssueManager) render(issue *Issue) error {
+	if mngr.mode != IssueModeReport || mngr.template == nil {
+		return nil
+	}
+
+	buf := bytes.Buffer{}
+	if err := mngr.template.Execute(&buf, issue); err != nil {
+		return err
+	}
+
+	issue.Body = buf.String() + "\n\n" + issue.Fingerprint.Marker()
+	return nil
+}
+
+// Authorship is the commit that last changed the line of an annotation, see [BlameFunc]
+type Authorship struct {
+	Author      string
+	AuthorEmail string
+	CommitSHA   string
+	CreatedAt   time.Time
+}
+
+// BlameFunc returns the authorship of [lines] of a file, relative to the working tree, with an
+// element per line. See [git.Repository.BlameLines]
+type BlameFunc func(path string, lines []int) ([]Authorship, error)
+
+// Committed reports whether the line of the annotation was attributed to a commit, rather than to
+// changes of the working tree that have not been committed yet
+func (issue Issue) Committed() bool {
+	return strings.Trim(issue.CommitSHA, "0") != ""
+}
+
+// Attribute sets the author and creation time of the issues at [selected], indices of [IssueManager.Issues],
+// or of every issue when [selected] is nil, to the commit that last changed the line of its annotation.
+// Each file is blamed once. The bodies of issues are rendered again, in report mode, so that the template
+// can refer to the author.
+func (mngr *IssueManager) Attribute(selected []int, blame BlameFunc) error {
+	if selected == nil {
+		selected = make([]int, len(mngr.Issues))
+		for i := range selected {
+			selected[i] = i
+		}
+	}
+
+	paths := make([]string, 0)
+	indices := make(map[string][]int)
+	for _, i := range selected {
+		issue := mngr.Issues[i]
+		if _, ok := indices[issue.FilePath]; !ok {
+			paths = append(paths, issue.FilePath)
+		}
+		indices[issue.FilePath] = append(indices[issue.FilePath], i)
+	}
+
+	for _, path := range paths {
+		lines := make([]int, len(indices[path]))
+		for j, i := range indices[path] {
+			lines[j] = mngr.Issues[i].LineNumber
+		}
+
+		authors, err := blame(path, lines)
+		if err != nil {
 			return err
 		}
-		issue.Body = buf.String()
+
+		if len(authors) != len(lines) {
+			return fmt.Errorf("expected the authorship of %d lines of %s, got %d", len(lines), path, len(authors))
+		}
+
+		for j, i := range indices[path] {
+			issue := &mngr.Issues[i]
+			issue.Author = authors[j].Author
+			issue.AuthorEmail = authors[j].AuthorEmail
+			issue.CommitSHA = authors[j].CommitSHA
+			issue.CreatedAt = authors[j].CreatedAt
+
+			if err := mngr.render(issue); err != nil {
+				return err
+			}
+		}
 	}
 
-	mngr.Issues = append(mngr.Issues, issue)
 	return nil
 }
 
+// SetOwners sets the owners of each issue to the owners of its file, which [owners] returns. The bodies
+// of issues are rendered again, in report mode, so that the template can mention the owners.
+func (mngr *IssueManager) SetOwners(owners func(path string) []string) error {
+	for i := range mngr.Issues {
+		issue := &mngr.Issues[i]
+		issue.Owners = owners(issue.FilePath)
+
+		if err := mngr.render(issue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wellFormed reports whether the reference that follows the annotation of a comment, which was
+// located in lint mode, matches the write back format, i.e. @TODO(#12) rather than @TODO(#)
+func (mngr *IssueManager) wellFormed(comment *lexer.Comment, src []byte) bool {
+	if len(comment.AnnotationPos) != 2 || comment.AnnotationPos[0] < 0 || comment.AnnotationPos[0] > len(src) {
+		return false
+	}
+
+	lexeme := src[comment.AnnotationPos[0]:]
+	if end := bytes.IndexFunc(lexeme, unicode.IsSpace); end >= 0 {
+		lexeme = lexeme[:end]
+	}
+
+	re, err := regexp.Compile("^" + string(purgePattern(mngr.annotation, mngr.writeBack)))
+	return err == nil && re.Match(lexeme)
+}
+
+// column counts the characters between the start of the line and [offset], starting at 1
+func column(src []byte, offset int) int {
+	if offset < 0 || offset > len(src) {
+		return 0
+	}
+
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	return utf8.RuneCount(src[lineStart:offset]) + 1
+}
+
+// commentEnd returns the line and column after the last character of the comment. Single line
+// comments end at the new line, or end of file, while multi line comments end at the last character
+// of the closing notation.
+func commentEnd(src []byte, comment *lexer.Comment) (int, int) {
+	start, end := comment.NotationStartIndex, comment.NotationEndIndex
+	if start < 0 || end < start || end > len(src) {
+		return comment.LineNumber, 0
+	}
+
+	if end < len(src) && src[end] != '\n' {
+		end++
+	}
+
+	return comment.LineNumber + bytes.Count(src[start:end], []byte{'\n'}), column(src, end)
+}
+
 func (mngr *IssueManager) Walk(root string) error {
 	ignorer, err := ignore.NewIgnorer(root)
 	if err != nil && !os.IsNotExist(err) {
@@ -171,13 +471,25 @@ func (mngr *IssueManager) Walk(root string) error {
 		}
 
 		if d.IsDir() {
-			return validateDir(d.Name(), path, root, ignorer)
+			if err := validateDir(d.Name(), path, root, ignorer); err != nil {
+				return err
+			}
+
+			if path != root && mngr.excluded(path, true) {
+				return filepath.SkipDir
+			}
+
+			return nil
 		}
 
 		if strings.HasPrefix(d.Name(), ".") {
 			return nil
 		}
 
+		if mngr.excluded(path, false) {
+			return nil
+		}
+
 		ignored, err := shouldIgnore(path, ignorer)
 		if err != nil {
 			return err
@@ -193,6 +505,48 @@ func (mngr *IssueManager) Walk(root string) error {
 	})
 }
 
+// Retain removes the issues that do not match the filter, i.e. annotations outside of the lines
+// that were changed by a branch. It must be called before issues are grouped, see [Group], since
+// the [Issue.Index] of the remaining issues is updated.
+func (mngr *IssueManager) Retain(filter Filter) {
+	retained := mngr.Issues[:0]
+	for _, issue := range mngr.Issues {
+		if filter.Match(issue) {
+			issue.Index = len(retained)
+			retained = append(retained, issue)
+		}
+	}
+	mngr.Issues = retained
+}
+
+// excluded reports whether a path is skipped by the include and exclude globs. Directories
+// are only matched against the exclude globs since included files may reside in any directory.
+func (mngr *IssueManager) excluded(path string, dir bool) bool {
+	rel, err := filepath.Rel(mngr.root, path)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range mngr.exclude {
+		if match, _ := doublestar.Match(pattern, rel); match {
+			return true
+		}
+	}
+
+	if dir || len(mngr.include) == 0 {
+		return false
+	}
+
+	for _, pattern := range mngr.include {
+		if match, _ := doublestar.Match(pattern, rel); match {
+			return false
+		}
+	}
+
+	return true
+}
+
 func validateDir(dirName, path, root string, ignorer *ignore.Ignorer) error {
 	if strings.Compare(root, path) == 0 {
 		return nil
@@ -228,6 +582,53 @@ func (mngr *IssueManager) Scan(path string) error {
 		return err
 	}
 
+	return mngr.scan(path, src)
+}
+
+// ScanSource scans contents that do not reside in the working tree, such as a file that was staged
+// for the next commit. [path] is relative to [root] and files that are excluded by the include and
+// exclude globs are skipped, see [Options].
+func (mngr *IssueManager) ScanSource(root, path string, src []byte) error {
+	mngr.root = root
+	mngr.currentBase = filepath.Base(path)
+	mngr.currentPath = filepath.Join(root, path)
+
+	// hidden files and directories are skipped, the same as [Walk]
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if strings.HasPrefix(part, ".") {
+			return nil
+		}
+	}
+
+	if mngr.excluded(mngr.currentPath, false) {
+		return nil
+	}
+
+	return mngr.scan(mngr.currentPath, src)
+}
+
+// WalkFiles scans a list of files, such as the files in the git index, rather than walking the working
+// tree and skipping the files that are ignored by the gitignore files. [paths] are relative to [root] and
+// [read] returns the contents of a path, i.e. the file in the working tree or the blob that is staged.
+// Files that do not exist, such as tracked files that were deleted from the working tree, are skipped.
+func (mngr *IssueManager) WalkFiles(root string, paths []string, read func(path string) ([]byte, error)) error {
+	for _, path := range paths {
+		src, err := read(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := mngr.ScanSource(root, path, src); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mngr *IssueManager) scan(path string, src []byte) error {
 	flag, err := mngr.toBitFlag()
 	if err != nil {
 		return err
@@ -251,7 +652,7 @@ func (mngr *IssueManager) Scan(path string) error {
 	}
 
 	for _, comment := range c.Comments {
-		if err := mngr.appendIssue(&comment); err != nil {
+		if err := mngr.appendIssue(&comment, src); err != nil {
 			return err
 		}
 	}
@@ -263,7 +664,7 @@ func (mngr *IssueManager) toBitFlag() (lexer.U8, error) {
 	switch mngr.mode {
 	case IssueModeReport, IssueModeScan:
 		return lexer.FLAG_SCAN, nil
-	case IssueModePurge:
+	case IssueModePurge, IssueModeLint:
 		return lexer.FLAG_PURGE, nil
 	default:
 		return 0, errors.New("unsupported issue mode. expected scan or purge")
@@ -300,9 +701,9 @@ func (mngr *IssueManager) WriteIssues(pathKey string) error {
 		return fmt.Errorf("Expected Issue map to have at least 1 entry")
 	}
 
-	srcFile, err := os.OpenFile(pathKey, os.O_RDWR, 0666)
+	srcFile, err := os.OpenFile(mngr.resolvePath(pathKey), os.O_RDWR, 0666)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	defer srcFile.Close()
@@ -326,7 +727,7 @@ func (mngr *IssueManager) WriteIssues(pathKey string) error {
 			buf.Write(srcCode[start : end+1])
 		}
 
-		buf.WriteString(fmt.Sprintf("(#%d)", entry.ReportedID))
+		buf.WriteString(fmt.Sprintf(mngr.writeBack, entry.ReportedID))
 
 		if i < size-1 {
 			next := entries[i+1]
@@ -353,6 +754,15 @@ func (mngr *IssueManager) WriteIssues(pathKey string) error {
 	return nil
 }
 
+// resolvePath joins path keys, which are relative to the working tree, with the root
+// that was walked so that files can be written when the program runs in another directory
+func (mngr *IssueManager) resolvePath(pathKey string) string {
+	if filepath.IsAbs(pathKey) {
+		return pathKey
+	}
+	return filepath.Join(mngr.root, pathKey)
+}
+
 // sortPathGroup is needed to restore order to our [IssueMap]. This is important
 // because [Issues] are reported to source code hosting platforms using go routines
 // and we can't guarantee when they will finish.
@@ -376,9 +786,9 @@ func (mngr *IssueManager) Purge(pathKey string) error {
 		return fmt.Errorf("Expected Issue map to have at least 1 entry")
 	}
 
-	srcFile, err := os.OpenFile(pathKey, os.O_RDWR, 0666)
+	srcFile, err := os.OpenFile(mngr.resolvePath(pathKey), os.O_RDWR, 0666)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	defer srcFile.Close()
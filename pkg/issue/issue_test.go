@@ -2,10 +2,15 @@ package issue_test
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
 	"github.com/stretchr/testify/require"
@@ -14,7 +19,7 @@ import (
 var (
 	// currentIssueCount is the current number of issues contained in the entire issue-summoner project.
 	// The value will change as issues, contained in this project, are resolved and added
-	currentIssueCount = 3
+	currentIssueCount = 2
 	testAnnotation    = []byte("@TEST_ANNOTATION")
 )
 
@@ -57,6 +62,17 @@ func TestNewIssueManager(t *testing.T) {
 			},
 			err: false,
 		},
+		{
+			name: "Should create a new issue manager when invoked with lint mode",
+			mode: issue.IssueModeLint,
+			expected: &issue.IssueManager{
+				// every reference is located, references that match the write back format are skipped
+				Annotation: []byte("@TEST_ANNOTATION\\("),
+				Issues:     []issue.Issue{},
+				IssueMap:   make(map[string][]issue.IssueMapEntry),
+			},
+			err: false,
+		},
 		{
 			name:     "Should return an error when invoked with a mode that isn't supported",
 			mode:     "unsupported-mode",
@@ -239,3 +255,452 @@ func match(t *testing.T, dstPath, validationPath string) {
 	matched := bytes.Equal(a, b)
 	require.True(t, matched)
 }
+
+func TestConfigureWriteBack(t *testing.T) {
+	testCases := []struct {
+		name      string
+		writeBack string
+		err       bool
+	}{
+		{name: "Should accept the default write back format", writeBack: "(#%d)"},
+		{name: "Should accept a write back format with a custom prefix", writeBack: "(ENG-%d)"},
+		{name: "Should reject write back formats without parentheses", writeBack: "ENG-%d", err: true},
+		{name: "Should reject write back formats with multiple verbs", writeBack: "(#%d-%d)", err: true},
+		{name: "Should reject write back formats with digits", writeBack: "(v2-%d)", err: true},
+		{name: "Should reject write back formats with whitespace", writeBack: "(ENG %d)", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+			require.NoError(t, err)
+
+			err = manager.Configure(issue.Options{WriteBack: tc.writeBack})
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCustomWriteBackPurge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @TEST_ANNOTATION remove me\nfunc main() {}\n"), 0644))
+
+	opts := issue.Options{WriteBack: "(ENG-%d)"}
+	reporter, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+	require.NoError(t, err)
+	require.NoError(t, reporter.Configure(opts))
+	require.NoError(t, reporter.Scan(path))
+	require.Len(t, reporter.Issues, 1)
+
+	reporter.IssueMap[path] = []issue.IssueMapEntry{{Index: 0, ReportedID: 42}}
+	require.NoError(t, reporter.WriteIssues(path))
+
+	src, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(src), "@TEST_ANNOTATION(ENG-42) remove me")
+
+	purger, err := issue.NewIssueManager(testAnnotation, issue.IssueModePurge)
+	require.NoError(t, err)
+	require.NoError(t, purger.Configure(opts))
+	require.NoError(t, purger.Scan(path))
+	require.Len(t, purger.Issues, 1)
+	require.Equal(t, 42, purger.Issues[0].Comment.IssueNumber)
+}
+
+func TestConfigureIncludeExclude(t *testing.T) {
+	root := t.TempDir()
+	files := []string{"src/app.go", "src/app_test.go", "vendor/lib.go", "main.go"}
+	for _, file := range files {
+		path := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package main\n\n// @TEST_ANNOTATION found\n"), 0644))
+	}
+
+	manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeScan)
+	require.NoError(t, err)
+	require.NoError(t, manager.Configure(issue.Options{
+		Include: []string{"src/**", "vendor/**"},
+		Exclude: []string{"**/*_test.go", "vendor"},
+	}))
+	require.NoError(t, manager.Walk(root))
+
+	require.Len(t, manager.Issues, 1)
+	require.Equal(t, filepath.Join("src", "app.go"), manager.Issues[0].FilePath)
+
+	require.Error(t, manager.Configure(issue.Options{Include: []string{"src/[a-"}}))
+}
+
+func TestFingerprintSymbols(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		src      string
+		symbol   string
+	}{
+		{
+			name:     "Should locate go functions",
+			fileName: "main.go",
+			src:      "package main\n\nfunc main() {\n\tif true {\n\t\t// @TEST_ANNOTATION nested\n\t}\n}\n",
+			symbol:   "main",
+		},
+		{
+			name:     "Should locate go methods",
+			fileName: "main.go",
+			src:      "package main\n\nfunc (s *server) Serve() error {\n\t// @TEST_ANNOTATION method\n\treturn nil\n}\n",
+			symbol:   "Serve",
+		},
+		{
+			name:     "Should anchor doc comments to the following declaration",
+			fileName: "main.go",
+			src:      "package main\n\n// @TEST_ANNOTATION doc comment\ntype Server struct{}\n",
+			symbol:   "Server",
+		},
+		{
+			name:     "Should locate java methods",
+			fileName: "App.java",
+			src:      "public class App {\n    public void run() {\n        // @TEST_ANNOTATION java\n    }\n}\n",
+			symbol:   "run",
+		},
+		{
+			name:     "Should locate shell functions",
+			fileName: "build.sh",
+			src:      "#!/bin/sh\n\nbuild() {\n  # @TEST_ANNOTATION shell\n  make\n}\n",
+			symbol:   "build",
+		},
+		{
+			name:     "Should locate c functions",
+			fileName: "main.c",
+			src:      "#include <stdio.h>\n\nint main(void) {\n  // @TEST_ANNOTATION c\n  return 0;\n}\n",
+			symbol:   "main",
+		},
+		{
+			name:     "Should not locate a symbol for top level comments",
+			fileName: "main.go",
+			src:      "package main\n\n// @TEST_ANNOTATION top level\n\nvar x = 1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(root, tc.fileName), []byte(tc.src), 0644))
+
+			manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+			require.NoError(t, err)
+			require.NoError(t, manager.Walk(root))
+			require.Len(t, manager.Issues, 1)
+
+			found := manager.Issues[0]
+			require.Equal(t, tc.symbol, found.Symbol)
+			require.Equal(t, found.Fingerprint.Location, found.ID)
+			require.Contains(t, found.Body, found.Fingerprint.Marker())
+		})
+	}
+}
+
+func TestFingerprintSurvivesLineShifts(t *testing.T) {
+	scan := func(src string) issue.Issue {
+		root := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0644))
+
+		manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeScan)
+		require.NoError(t, err)
+		require.NoError(t, manager.Walk(root))
+		require.NotEmpty(t, manager.Issues)
+		return manager.Issues[0]
+	}
+
+	original := scan("package main\n\nfunc run() {\n\t// @TEST_ANNOTATION handle errors\n}\n")
+	shifted := scan("package main\n\nimport \"fmt\"\n\nfunc run() {\n\tfmt.Println()\n\t// @TEST_ANNOTATION  Handle errors\n}\n")
+	require.Equal(t, original.Fingerprint, shifted.Fingerprint)
+
+	duplicated := scan("package main\n\nfunc run() {\n\t// @TEST_ANNOTATION handle errors\n\t// @TEST_ANNOTATION handle errors\n}\n")
+	require.Equal(t, original.Fingerprint, duplicated.Fingerprint)
+}
+
+// lineSet contains the changed line numbers of each file
+type lineSet map[string][]int
+
+func (set lineSet) Overlaps(path string, start, end int) bool {
+	for _, line := range set[path] {
+		if line >= start && line <= end {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFilter(t *testing.T) {
+	iss := issue.Issue{
+		Title:      "Handle the retry after header",
+		FilePath:   "pkg/common/request.go",
+		LineNumber: 10,
+		EndLine:    12,
+		CreatedAt:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name     string
+		filter   issue.Filter
+		expected bool
+	}{
+		{name: "Should match every issue with the zero value", filter: issue.Filter{}, expected: true},
+		{name: "Should match issues within the directory", filter: issue.Filter{Path: "pkg/common"}, expected: true},
+		{name: "Should match the file itself", filter: issue.Filter{Path: "pkg/common/request.go"}, expected: true},
+		{name: "Should match the work tree", filter: issue.Filter{Path: "."}, expected: true},
+		{name: "Should not match sibling directories with a common prefix", filter: issue.Filter{Path: "pkg/comm"}, expected: false},
+		{name: "Should not match other directories", filter: issue.Filter{Path: "cmd"}, expected: false},
+		{
+			name:     "Should match titles with the regular expression",
+			filter:   issue.Filter{TitleMatch: regexp.MustCompile(`(?i)retry`)},
+			expected: true,
+		},
+		{
+			name:     "Should require both the path and title to match",
+			filter:   issue.Filter{Path: "cmd", TitleMatch: regexp.MustCompile(`retry`)},
+			expected: false,
+		},
+		{
+			name:     "Should match comments that overlap changed lines",
+			filter:   issue.Filter{Lines: lineSet{"pkg/common/request.go": {12, 13}}},
+			expected: true,
+		},
+		{
+			name:     "Should not match comments outside of the changed lines",
+			filter:   issue.Filter{Lines: lineSet{"pkg/common/request.go": {9, 13}, "cmd/scan.go": {10}}},
+			expected: false,
+		},
+		{
+			name:     "Should match issues that were created before the cutoff",
+			filter:   issue.Filter{Before: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+			expected: true,
+		},
+		{
+			name:     "Should not match issues that were created after the cutoff",
+			filter:   issue.Filter{Before: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.filter.Match(iss))
+		})
+	}
+
+	// issues that were not attributed to a commit are not known to be old
+	iss.CreatedAt = time.Time{}
+	require.False(t, issue.Filter{Before: time.Now()}.Match(iss))
+}
+
+func TestAttribute(t *testing.T) {
+	manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, manager.ScanSource(root, "main.go", []byte("package main\n\n// @TEST_ANNOTATION first\n\n// @TEST_ANNOTATION second\n")))
+	require.NoError(t, manager.ScanSource(root, "cmd/root.go", []byte("package cmd\n\n// @TEST_ANNOTATION uncommitted\n")))
+	require.Len(t, manager.Issues, 3)
+
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	blamed := make([]string, 0)
+	err = manager.Attribute(nil, func(path string, lines []int) ([]issue.Authorship, error) {
+		blamed = append(blamed, filepath.ToSlash(path))
+
+		authors := make([]issue.Authorship, len(lines))
+		for i, line := range lines {
+			authors[i] = issue.Authorship{Author: "Jane Doe", CommitSHA: strings.Repeat("a", 40), CreatedAt: created.AddDate(0, 0, line)}
+			if path == filepath.Join("cmd", "root.go") {
+				authors[i] = issue.Authorship{Author: "Not Committed Yet", CommitSHA: strings.Repeat("0", 40), CreatedAt: time.Now()}
+			}
+		}
+		return authors, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"main.go", "cmd/root.go"}, blamed, "each file is blamed once")
+
+	first, second, uncommitted := manager.Issues[0], manager.Issues[1], manager.Issues[2]
+	require.Equal(t, "Jane Doe", first.Author)
+	require.Equal(t, created.AddDate(0, 0, 3), first.CreatedAt)
+	require.Equal(t, created.AddDate(0, 0, 5), second.CreatedAt)
+	require.True(t, first.Committed())
+	require.False(t, uncommitted.Committed())
+
+	// the bodies are rendered again, along with the fingerprint marker
+	require.Contains(t, first.Body, "Jane Doe")
+	require.Contains(t, first.Body, "2024-01-05")
+	require.Contains(t, first.Body, first.Fingerprint.Marker())
+	require.NotContains(t, uncommitted.Body, "Not Committed Yet")
+
+	err = manager.Attribute(nil, func(path string, lines []int) ([]issue.Authorship, error) {
+		return nil, nil
+	})
+	require.Error(t, err, "an authorship is expected for each line")
+
+	// only the selected issues are blamed
+	blamed = blamed[:0]
+	err = manager.Attribute([]int{2}, func(path string, lines []int) ([]issue.Authorship, error) {
+		blamed = append(blamed, filepath.ToSlash(path))
+		return make([]issue.Authorship, len(lines)), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"cmd/root.go"}, blamed)
+}
+
+func TestSetOwners(t *testing.T) {
+	manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, manager.ScanSource(root, "main.go", []byte("package main\n\n// @TEST_ANNOTATION owned\n")))
+	require.NoError(t, manager.ScanSource(root, "docs.go", []byte("package main\n\n// @TEST_ANNOTATION unowned\n")))
+	require.Len(t, manager.Issues, 2)
+
+	err = manager.SetOwners(func(path string) []string {
+		if path == "main.go" {
+			return []string{"@octocat", "@acme/payments"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	owned, unowned := manager.Issues[0], manager.Issues[1]
+	require.Equal(t, []string{"@octocat", "@acme/payments"}, owned.Owners)
+	require.Contains(t, owned.Body, "@octocat, @acme/payments")
+	require.Contains(t, owned.Body, owned.Fingerprint.Marker())
+	require.Empty(t, unowned.Owners)
+	require.NotContains(t, unowned.Body, "Owners")
+}
+
+func TestRetain(t *testing.T) {
+	manager, err := issue.NewIssueManager([]byte(testAnnotation), issue.IssueModeScan)
+	require.NoError(t, err)
+
+	manager.Issues = []issue.Issue{
+		{Title: "first", FilePath: "a.go", LineNumber: 1, EndLine: 1, Index: 0},
+		{Title: "second", FilePath: "a.go", LineNumber: 5, EndLine: 7, Index: 1},
+		{Title: "third", FilePath: "b.go", LineNumber: 3, EndLine: 3, Index: 2},
+	}
+
+	manager.Retain(issue.Filter{Lines: lineSet{"a.go": {6}, "b.go": {3}}})
+	require.Len(t, manager.Issues, 2)
+
+	for i, iss := range manager.Issues {
+		require.Equal(t, i, iss.Index)
+	}
+	require.Equal(t, "second", manager.Issues[0].Title)
+	require.Equal(t, "third", manager.Issues[1].Title)
+}
+
+func TestScanSource(t *testing.T) {
+	src := []byte(`package main
+
+// @TEST_ANNOTATION unreported
+// @TEST_ANNOTATION(#12) reported
+// @TEST_ANNOTATION(#) missing number
+// @TEST_ANNOTATION(12) missing hash
+// @TEST_ANNOTATION(ENG-7) custom prefix
+func main() {}
+`)
+
+	testCases := []struct {
+		name      string
+		mode      issue.IssueMode
+		writeBack string
+		path      string
+		expected  []int // line numbers of the located issues
+	}{
+		{name: "Should locate unreported annotations in scan mode", mode: issue.IssueModeScan, path: "main.go", expected: []int{3}},
+		{name: "Should locate reported annotations in purge mode", mode: issue.IssueModePurge, path: "main.go", expected: []int{4}},
+		{name: "Should locate malformed references in lint mode", mode: issue.IssueModeLint, path: "main.go", expected: []int{5, 6, 7}},
+		{
+			name:      "Should use the write back format in lint mode",
+			mode:      issue.IssueModeLint,
+			writeBack: "(ENG-%d)",
+			path:      "main.go",
+			expected:  []int{4, 5, 6},
+		},
+		{name: "Should skip excluded files", mode: issue.IssueModeScan, path: "vendor/main.go", expected: []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, err := issue.NewIssueManager(testAnnotation, tc.mode)
+			require.NoError(t, err)
+			require.NoError(t, manager.Configure(issue.Options{WriteBack: tc.writeBack, Exclude: []string{"vendor/**"}}))
+
+			root := t.TempDir()
+			require.NoError(t, manager.ScanSource(root, tc.path, src))
+
+			lines := make([]int, 0)
+			for _, iss := range manager.Issues {
+				require.Equal(t, tc.path, filepath.ToSlash(iss.FilePath))
+				lines = append(lines, iss.LineNumber)
+			}
+			require.Equal(t, tc.expected, lines)
+		})
+	}
+}
+
+func TestWalkFiles(t *testing.T) {
+	files := map[string]string{
+		"main.go":           "package main\n\n// @TEST_ANNOTATION tracked\n",
+		"ignored/keep.go":   "package ignored\n\n// @TEST_ANNOTATION force added\n",
+		".github/script.js": "// @TEST_ANNOTATION hidden directory\n",
+	}
+
+	read := func(path string) ([]byte, error) {
+		if src, ok := files[filepath.ToSlash(path)]; ok {
+			return []byte(src), nil
+		}
+		return nil, fs.ErrNotExist
+	}
+
+	manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeScan)
+	require.NoError(t, err)
+
+	// deleted.go is tracked but was removed from the working tree
+	paths := []string{".github/script.js", "deleted.go", "ignored/keep.go", "main.go"}
+	require.NoError(t, manager.WalkFiles(t.TempDir(), paths, read))
+
+	located := make([]string, 0)
+	for _, iss := range manager.Issues {
+		located = append(located, filepath.ToSlash(iss.FilePath))
+	}
+	require.Equal(t, []string{"ignored/keep.go", "main.go"}, located)
+
+	broken := func(string) ([]byte, error) {
+		return nil, errors.New("unreadable")
+	}
+	require.Error(t, manager.WalkFiles(t.TempDir(), paths, broken))
+}
+
+func TestUsesAuthorship(t *testing.T) {
+	testCases := []struct {
+		name     string
+		template string
+		expected bool
+	}{
+		{name: "Should detect the author in the default template", expected: true},
+		{name: "Should detect fields within conditions", template: "{{ if .Committed }}added{{ end }}", expected: true},
+		{name: "Should detect fields of the root variable", template: "{{ range .Owners }}{{ $.Author }}{{ end }}", expected: true},
+		{name: "Should detect fields that are passed to functions", template: `{{ printf "%s" .CreatedAt }}`, expected: true},
+		{name: "Should ignore templates without authors", template: "{{ .Title }} in {{ .FilePath }}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, err := issue.NewIssueManager(testAnnotation, issue.IssueModeReport)
+			require.NoError(t, err)
+
+			if tc.template != "" {
+				require.NoError(t, manager.Configure(issue.Options{Template: tc.template}))
+			}
+			require.Equal(t, tc.expected, manager.UsesAuthorship())
+		})
+	}
+}
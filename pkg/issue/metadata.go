@@ -0,0 +1,67 @@
+package issue
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	PriorityCritical = "critical"
+	PriorityHigh     = "high"
+	PriorityMedium   = "medium"
+	PriorityLow      = "low"
+)
+
+// DueDateLayout is the format of due dates in comments, i.e. due:2025-01-31
+const DueDateLayout = "2006-01-02"
+
+var (
+	priorityTag   = regexp.MustCompile(`(?i)\[(p[0-4])\]`)
+	priorityField = regexp.MustCompile(`(?i)\bpriority[:=]\s*([a-z0-9]+)`)
+	dueField      = regexp.MustCompile(`(?i)\bdue[:=]\s*(\d{4}-\d{2}-\d{2})`)
+)
+
+// priorities normalizes the values that are accepted in comments
+var priorities = map[string]string{
+	"p0":       PriorityCritical,
+	"critical": PriorityCritical,
+	"urgent":   PriorityCritical,
+	"p1":       PriorityHigh,
+	"high":     PriorityHigh,
+	"p2":       PriorityMedium,
+	"medium":   PriorityMedium,
+	"normal":   PriorityMedium,
+	"p3":       PriorityLow,
+	"p4":       PriorityLow,
+	"low":      PriorityLow,
+	"minor":    PriorityLow,
+}
+
+// parseMetadata locates the priority and due date of an annotation within the text of the
+// comment. Priorities are written as a tag, i.e. [P1], or a field, i.e. priority:high, and due
+// dates as due:2025-01-31. Unrecognized values are ignored.
+func parseMetadata(text string) (priority string, due time.Time) {
+	if match := priorityTag.FindStringSubmatch(text); match != nil {
+		priority = priorities[strings.ToLower(match[1])]
+	} else if match := priorityField.FindStringSubmatch(text); match != nil {
+		priority = priorities[strings.ToLower(match[1])]
+	}
+
+	if match := dueField.FindStringSubmatch(text); match != nil {
+		if date, err := time.Parse(DueDateLayout, match[1]); err == nil {
+			due = date
+		}
+	}
+
+	return priority, due
+}
+
+// Overdue reports whether the due date of the issue is before the day of [now]
+func (issue Issue) Overdue(now time.Time) bool {
+	if issue.Due.IsZero() {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return issue.Due.Before(today)
+}
@@ -0,0 +1,208 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	sarifVersion        = "2.1.0"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifInformationUri = "https://github.com/AntoninoAdornetto/issue-summoner"
+	sarifSrcRoot        = "%SRCROOT%"
+	// partial fingerprint of a result, code scanning tracks alerts across runs with it
+	sarifFingerprint = "issueSummonerFingerprint/v1"
+)
+
+const (
+	sarifLevelError   = "error"
+	sarifLevelWarning = "warning"
+	sarifLevelNote    = "note"
+)
+
+// sarif levels of each priority, issues without a priority are warnings
+var sarifLevels = map[string]string{
+	PriorityCritical: sarifLevelError,
+	PriorityHigh:     sarifLevelError,
+	PriorityMedium:   sarifLevelWarning,
+	PriorityLow:      sarifLevelNote,
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// writeSARIF writes the unreported and overdue issues as a SARIF 2.1.0 log, which code scanning
+// platforms display as alerts. Each annotation is a rule, the level of an alert is derived from
+// the priority of the issue and overdue issues are always errors. Alerts are tracked between runs
+// with the fingerprint of the issue, which does not change when lines are added above the comment.
+func (export Export) writeSARIF(w io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{Name: "issue-summoner", InformationUri: sarifInformationUri, Rules: make([]sarifRule, 0)},
+		},
+		ColumnKind: "unicodeCodePoints",
+		Results:    make([]sarifResult, 0),
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, issue := range export.Issues {
+		if issue.IssueNumber != 0 && !issue.Overdue {
+			continue
+		}
+
+		id := sarifRuleID(issue.Annotation)
+		index, ok := ruleIndex[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndex[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSarifRule(id, issue.Annotation))
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     sarifLevel(issue),
+			Message:   sarifMessage{Text: sarifResultMessage(issue)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: issue.Path, URIBaseID: sarifSrcRoot},
+					Region: sarifRegion{
+						StartLine:   issue.Line,
+						StartColumn: issue.Column,
+						EndLine:     issue.EndLine,
+						EndColumn:   issue.EndColumn,
+					},
+				},
+			}},
+			PartialFingerprints: map[string]string{sarifFingerprint: issue.ID},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifRuleID derives the id of a rule from an annotation, i.e. @FIXME: is fixme
+func sarifRuleID(annotation string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return -1
+		}
+	}, annotation)
+
+	if id == "" {
+		return "annotation"
+	}
+	return id
+}
+
+func newSarifRule(id, annotation string) sarifRule {
+	return sarifRule{
+		ID:               id,
+		ShortDescription: sarifMessage{Text: fmt.Sprintf("Unreported or overdue %s annotation", annotation)},
+		FullDescription: sarifMessage{
+			Text: fmt.Sprintf("%s annotations that have not been reported as issues, or whose due date has passed", annotation),
+		},
+		Help: sarifMessage{
+			Text: "Report the annotation with <issue-summoner report>, or resolve it and remove the comment",
+		},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevelWarning},
+	}
+}
+
+func sarifLevel(issue ExportedIssue) string {
+	if issue.Overdue {
+		return sarifLevelError
+	}
+
+	if level, ok := sarifLevels[issue.Priority]; ok {
+		return level
+	}
+	return sarifLevelWarning
+}
+
+func sarifResultMessage(issue ExportedIssue) string {
+	if issue.Overdue && issue.IssueNumber != 0 {
+		return fmt.Sprintf("%s (#%d) %s was due on %s", issue.Annotation, issue.IssueNumber, issue.Title, issue.Due)
+	}
+
+	if issue.Overdue {
+		return fmt.Sprintf("Unreported %s %s was due on %s", issue.Annotation, issue.Title, issue.Due)
+	}
+
+	return fmt.Sprintf("Unreported %s %s", issue.Annotation, issue.Title)
+}
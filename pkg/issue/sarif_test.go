@@ -0,0 +1,106 @@
+package issue_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AntoninoAdornetto/issue-summoner/pkg/issue"
+	"github.com/stretchr/testify/require"
+)
+
+type sarifResult struct {
+	RuleID    string `json:"ruleId"`
+	RuleIndex int    `json:"ruleIndex"`
+	Level     string `json:"level"`
+	Locations []struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+			Region struct {
+				StartLine   int `json:"startLine"`
+				StartColumn int `json:"startColumn"`
+				EndLine     int `json:"endLine"`
+				EndColumn   int `json:"endColumn"`
+			} `json:"region"`
+		} `json:"physicalLocation"`
+	} `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLog struct {
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Rules []struct {
+					ID string `json:"id"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []sarifResult `json:"results"`
+	} `json:"runs"`
+}
+
+func writeSARIF(t *testing.T, src string, mode issue.IssueMode) sarifLog {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte(src), 0644))
+
+	manager, err := issue.NewIssueManager(testAnnotation, mode)
+	require.NoError(t, err)
+	require.NoError(t, manager.Walk(root))
+
+	buf := bytes.Buffer{}
+	require.NoError(t, manager.Export(nil).Write(&buf, issue.FormatSARIF))
+
+	log := sarifLog{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	return log
+}
+
+func TestSARIF(t *testing.T) {
+	src := "package main\n\nfunc main() {\n" +
+		"\t// @TEST_ANNOTATION default\n" +
+		"\t// @TEST_ANNOTATION [P0] critical\n" +
+		"\t// @TEST_ANNOTATION priority:low later\n" +
+		"\t/* @TEST_ANNOTATION medium due:2000-01-01\n\t   overdue */\n" +
+		"}\n"
+
+	log := writeSARIF(t, src, issue.IssueModeScan)
+	run := log.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 1)
+	require.Equal(t, "test_annotation", run.Tool.Driver.Rules[0].ID)
+
+	levels := make([]string, 0, len(run.Results))
+	for _, result := range run.Results {
+		require.Equal(t, "test_annotation", result.RuleID)
+		require.Equal(t, 0, result.RuleIndex)
+		require.NotEmpty(t, result.PartialFingerprints["issueSummonerFingerprint/v1"])
+		levels = append(levels, result.Level)
+	}
+	require.Equal(t, []string{"warning", "error", "note", "error"}, levels)
+
+	region := run.Results[3].Locations[0].PhysicalLocation.Region
+	require.Equal(t, "main.go", run.Results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	require.Equal(t, []int{7, 2, 8, 15}, []int{region.StartLine, region.StartColumn, region.EndLine, region.EndColumn})
+
+	// the fingerprint does not change when lines are added above the comment
+	shifted := writeSARIF(t, "package main\n\nimport \"fmt\"\n\n"+src[len("package main\n\n"):], issue.IssueModeScan)
+	require.Equal(t, run.Results[0].PartialFingerprints, shifted.Runs[0].Results[0].PartialFingerprints)
+	require.Equal(t, 6, shifted.Runs[0].Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func TestSARIFReportedIssues(t *testing.T) {
+	src := "package main\n\n// @TEST_ANNOTATION(#1) tracked\n\n// @TEST_ANNOTATION(#2) late due:2000-01-01\n"
+
+	// reported issues are only alerts once they are overdue
+	results := writeSARIF(t, src, issue.IssueModePurge).Runs[0].Results
+	require.Len(t, results, 1)
+	require.Equal(t, "error", results[0].Level)
+	require.Equal(t, 5, results[0].Locations[0].PhysicalLocation.Region.StartLine)
+}
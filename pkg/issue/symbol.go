@@ -0,0 +1,124 @@
+package issue
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// declarations that name a symbol, across the languages supported by the lexer. The first
+// capture group is the name of the symbol.
+var declarations = []*regexp.Regexp{
+	regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`),                                      // go
+	regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+(\w+)`), // rust
+	regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(
+		`^(?:(?:export|default|public|private|protected|internal|abstract|final|static|sealed|pub)\s+)*` +
+			`(?:class|struct|enum|trait|impl|interface|type|module|namespace|object)\s+([A-Za-z_$][\w$]*)`,
+	),
+	// c-like functions and methods, i.e. int main(void) {, public void run() {, render() {
+	regexp.MustCompile(`^(?:[\w:<>,*&\[\]]+\s+)*\**([A-Za-z_$][\w$]*)\s*\([^;]*\)\s*(?:const\s*)?\{?\s*$`),
+}
+
+// keywords that are followed by parentheses but do not declare a symbol
+var controlKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"else": true, "do": true, "try": true, "match": true, "foreach": true, "elif": true,
+}
+
+// enclosingSymbol returns the name of the function, method or type that contains the comment
+// that starts at [offset]. Symbols are located with the indentation of the lines above the
+// comment rather than parsing the language, which works for conventionally formatted code.
+// Comments that are not indented are anchored to the declaration that follows them, i.e. doc
+// comments. An empty string is returned when no symbol is found.
+func enclosingSymbol(src []byte, offset, end int) string {
+	if offset < 0 || offset > len(src) {
+		return ""
+	}
+
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	indent := indentation(src[lineStart:])
+
+	if indent == 0 {
+		return followingSymbol(src, end)
+	}
+
+	minIndent := indent
+	for pos := lineStart; pos > 0; {
+		prevStart := bytes.LastIndexByte(src[:pos-1], '\n') + 1
+		line := src[prevStart : pos-1]
+		pos = prevStart
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		lineIndent := indentation(line)
+		if lineIndent >= minIndent {
+			continue
+		}
+
+		minIndent = lineIndent
+		if name := declarationName(line); name != "" {
+			return name
+		}
+
+		if lineIndent == 0 {
+			return ""
+		}
+	}
+
+	return ""
+}
+
+// followingSymbol returns the declaration on the first non blank line after [end]
+func followingSymbol(src []byte, end int) string {
+	if end < 0 || end >= len(src) {
+		return ""
+	}
+
+	rest := src[end:]
+	if i := bytes.IndexByte(rest, '\n'); i != -1 {
+		rest = rest[i+1:]
+	} else {
+		return ""
+	}
+
+	for len(rest) > 0 {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i != -1 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			return declarationName(line)
+		}
+	}
+
+	return ""
+}
+
+func declarationName(line []byte) string {
+	line = bytes.TrimSpace(line)
+	for _, decl := range declarations {
+		match := decl.FindSubmatch(line)
+		if match != nil && !controlKeywords[string(match[1])] {
+			return string(match[1])
+		}
+	}
+	return ""
+}
+
+// indentation counts leading whitespace, with tabs counted as a single column since a
+// file is expected to indent consistently
+func indentation(line []byte) int {
+	count := 0
+	for _, b := range line {
+		if b != ' ' && b != '\t' {
+			break
+		}
+		count++
+	}
+	return count
+}
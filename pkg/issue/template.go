@@ -6,7 +6,11 @@ THE SOURCE CODE MANAGMENET PLATFORM FLAG THAT IS PASSED INTO THE REPORT COMMAND.
 */
 package issue
 
-import "text/template"
+import (
+	"slices"
+	"text/template"
+	"text/template/parse"
+)
 
 var (
 	issue_template_markdown = `### Description
@@ -17,6 +21,17 @@ var (
 - ***File name:*** ` + "`" + `{{ .FileName }}` + "`" + `
 - ***Path:*** ` + "`" + `{{ .FilePath }}` + "`" + `
 - ***Line number:*** ` + "`" + `{{ .LineNumber }}` + "`" + `
+{{- if .Owners }}
+- ***Owners:*** {{ range $i, $owner := .Owners }}{{ if $i }}, {{ end }}{{ $owner }}{{ end }}
+{{- end }}
+{{- if .Committed }}
+
+### History
+
+- ***Author:*** {{ .Author }}
+- ***Added:*** ` + "`" + `{{ .CreatedAt.Format "2006-01-02" }}` + "`" + `
+- ***Commit:*** ` + "`" + `{{ .CommitSHA }}` + "`" + `
+{{- end }}
 
 ### Environment
 
@@ -31,3 +46,62 @@ var (
 func generateIssueTemplate() (*template.Template, error) {
 	return template.New("").Parse(issue_template_markdown)
 }
+
+// authorshipFields are the fields of [Issue] that are set by [IssueManager.Attribute]
+var authorshipFields = []string{"Author", "AuthorEmail", "CommitSHA", "CreatedAt", "Committed"}
+
+// UsesAuthorship reports whether the template refers to the author of an annotation, so that the
+// history of the repository is only traversed when the author is rendered
+func (mngr *IssueManager) UsesAuthorship() bool {
+	if mngr.template == nil {
+		return false
+	}
+
+	for _, tmpl := range mngr.template.Templates() {
+		if tmpl.Tree != nil && usesFields(tmpl.Tree.Root, authorshipFields) {
+			return true
+		}
+	}
+	return false
+}
+
+// usesFields reports whether a node of a parsed template refers to any of the fields, either as
+// .Field or $.Field
+func usesFields(node parse.Node, fields []string) bool {
+	uses := func(nodes ...parse.Node) bool {
+		return slices.ContainsFunc(nodes, func(n parse.Node) bool { return usesFields(n, fields) })
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		return n != nil && uses(n.Nodes...)
+	case *parse.ActionNode:
+		return uses(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if uses(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		return uses(n.Args...)
+	case *parse.FieldNode:
+		return slices.Contains(fields, n.Ident[0])
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && slices.Contains(fields, n.Ident[1])
+	case *parse.ChainNode:
+		return uses(n.Node)
+	case *parse.IfNode:
+		return uses(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		return uses(n.Pipe, n.List, n.ElseList)
+	case *parse.WithNode:
+		return uses(n.Pipe, n.List, n.ElseList)
+	case *parse.TemplateNode:
+		return uses(n.Pipe)
+	}
+	return false
+}
@@ -232,6 +232,11 @@ func (base *Lexer) processIssueNumberTokens(lexeme []byte, tokens *[]Token, inde
 			index = base.processHashToken(lexeme, tokens, index)
 		case CLOSE_PARAN:
 			base.appendPosToken(start, end, lexeme[index], TOKEN_CLOSE_PARAN, tokens)
+		default:
+			// issue numbers can be written back with a custom prefix, i.e. @TODO(ENG-432)
+			if unicode.IsDigit(rune(lexeme[index])) {
+				index = base.processIssueNumber(lexeme, tokens, index)
+			}
 		}
 	}
 }
@@ -240,16 +245,23 @@ func (base *Lexer) processHashToken(lexeme []byte, tokens *[]Token, index int) i
 	start := base.Start + index
 	end := start
 	base.appendPosToken(start, end, lexeme[index], TOKEN_HASH, tokens)
-	index++
+	return base.processIssueNumber(lexeme, tokens, index+1)
+}
 
-	start = base.Start + index
+func (base *Lexer) processIssueNumber(lexeme []byte, tokens *[]Token, index int) int {
+	start := base.Start + index
 	issueNumLexeme := make([]byte, 0, 5)
-	for index < len(lexeme) && lexeme[index] != CLOSE_PARAN {
+	for index < len(lexeme) && unicode.IsDigit(rune(lexeme[index])) {
 		issueNumLexeme = append(issueNumLexeme, lexeme[index])
 		index++
 	}
 
-	end = (base.Start + index) - 1
+	// references without a number, i.e. @TODO(#), are left for <issue-summoner scan --staged> to report
+	if len(issueNumLexeme) == 0 {
+		return index - 1
+	}
+
+	end := (base.Start + index) - 1
 	issueNum := newPosToken(start, end, base.Line, issueNumLexeme, TOKEN_ISSUE_NUMBER)
 	*tokens = append(*tokens, issueNum)
 	return index - 1
@@ -21,6 +21,7 @@ type Step struct {
 type Item struct {
 	ID          int
 	Title, Desc string
+	Preview     string // shown below the description when set, i.e. the assignees of an issue
 }
 
 func (s *Selection) OnSelect(option int, value bool) {
@@ -108,6 +109,9 @@ func (m model) View() string {
 
 		title := DimTextStyle.Render(option.Title)
 		description := DimTextStyle.Render(option.Desc)
+		if option.Preview != "" {
+			description += "\n" + SecondaryTextStyle.Render(option.Preview)
+		}
 
 		s.WriteString(fmt.Sprintf("%s [%s] %s\n%s\n\n", cursor, checked, title, description))
 	}
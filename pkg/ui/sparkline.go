@@ -0,0 +1,28 @@
+package ui
+
+// sparkTicks are the bars of a sparkline, from the lowest to the highest value
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders a series of values as a line of bars, which are scaled between the lowest
+// and highest value of the series. A series of equal values is rendered with the lowest bar.
+func Sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		tick := 0
+		if hi > lo {
+			tick = (v - lo) * (len(sparkTicks) - 1) / (hi - lo)
+		}
+		line[i] = sparkTicks[tick]
+	}
+
+	return string(line)
+}